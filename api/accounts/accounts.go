@@ -15,7 +15,9 @@ import (
 	"github.com/HiNounou029/nounouchain/core/block"
 	"github.com/HiNounou029/nounouchain/core/chain"
 	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/nounou/logdb"
 	"github.com/HiNounou029/nounouchain/state"
+	"github.com/HiNounou029/nounouchain/vm"
 	"github.com/HiNounou029/nounouchain/vm/runtime"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
@@ -28,16 +30,25 @@ var (
 	balanceSig = "0x1d7976f3" //balanceOf(address)
 )
 
+const (
+	defaultTxsLimit = 10
+	maxTxsLimit     = 1000
+	// max points of balance time series
+	maxBalancePoints = 1000
+)
+
 type Accounts struct {
 	chain        *chain.Chain
 	stateCreator *state.Creator
+	logDB        *logdb.LogDB
 	callGasLimit uint64
 }
 
-func New(chain *chain.Chain, stateCreator *state.Creator, callGasLimit uint64) *Accounts {
+func New(chain *chain.Chain, stateCreator *state.Creator, logDB *logdb.LogDB, callGasLimit uint64) *Accounts {
 	return &Accounts{
 		chain,
 		stateCreator,
+		logDB,
 		callGasLimit,
 	}
 }
@@ -60,7 +71,7 @@ func (a *Accounts) handleGetCode(w http.ResponseWriter, req *http.Request) error
 	if err != nil {
 		return utils.BadRequest(errors.WithMessage(err, "address"))
 	}
-	h, err := a.handleRevision(req.URL.Query().Get("revision"))
+	h, err := utils.ParseRevision(a.chain, req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
@@ -105,7 +116,7 @@ func (a *Accounts) handleGetAccount(w http.ResponseWriter, req *http.Request) er
 	if err != nil {
 		return utils.BadRequest(errors.WithMessage(err, "address"))
 	}
-	h, err := a.handleRevision(req.URL.Query().Get("revision"))
+	h, err := utils.ParseRevision(a.chain, req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
@@ -186,7 +197,7 @@ func (a *Accounts) handleGetStorage(w http.ResponseWriter, req *http.Request) er
 	if err != nil {
 		return utils.BadRequest(errors.WithMessage(err, "key"))
 	}
-	h, err := a.handleRevision(req.URL.Query().Get("revision"))
+	h, err := utils.ParseRevision(a.chain, req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
@@ -197,12 +208,125 @@ func (a *Accounts) handleGetStorage(w http.ResponseWriter, req *http.Request) er
 	return utils.WriteTo(w, req, map[string]string{"value": storage.String()})
 }
 
+func parseUintQuery(req *http.Request, name string, def uint64) (uint64, error) {
+	s := req.URL.Query().Get(name)
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, utils.BadRequest(errors.WithMessage(err, name))
+	}
+	return n, nil
+}
+
+// handleGetTransactions lists txs related to the account.
+// Txs of blocks committed before the accountTx index are listed once backfilled by the node,
+// as recorded by logdb.AccountTxsIndexedFrom.
+func (a *Accounts) handleGetTransactions(w http.ResponseWriter, req *http.Request) error {
+	addr, err := polo.ParseAddress(mux.Vars(req)["address"])
+	if err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "address"))
+	}
+	offset, err := parseUintQuery(req, "offset", 0)
+	if err != nil {
+		return err
+	}
+	limit, err := parseUintQuery(req, "limit", defaultTxsLimit)
+	if err != nil {
+		return err
+	}
+	if limit > maxTxsLimit {
+		return utils.BadRequest(fmt.Errorf("limit: exceeds %v", maxTxsLimit))
+	}
+	filter := &logdb.AccountTxFilter{
+		Address: addr,
+		Options: &logdb.Options{Offset: offset, Limit: limit},
+		Order:   logdb.ASC,
+	}
+	switch order := req.URL.Query().Get("order"); order {
+	case "", string(logdb.ASC):
+	case string(logdb.DESC):
+		filter.Order = logdb.DESC
+	default:
+		return utils.BadRequest(errors.New("order: should be asc or desc"))
+	}
+	txs, err := a.logDB.FilterAccountTxs(req.Context(), filter)
+	if err != nil {
+		return err
+	}
+	result := make([]*AccountTx, len(txs))
+	for i, atx := range txs {
+		result[i] = convertAccountTx(atx)
+	}
+	return utils.WriteJSON(w, result)
+}
+
+func (a *Accounts) handleGetBalances(w http.ResponseWriter, req *http.Request) error {
+	addr, err := polo.ParseAddress(mux.Vars(req)["address"])
+	if err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "address"))
+	}
+	query := req.URL.Query()
+	from, err := utils.ParseTime(query.Get("from"))
+	if err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "from"))
+	}
+	to, err := utils.ParseTime(query.Get("to"))
+	if err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "to"))
+	}
+	if to < from {
+		return utils.BadRequest(errors.New("to: less than from"))
+	}
+	interval, err := strconv.ParseUint(query.Get("interval"), 10, 64)
+	if err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "interval"))
+	}
+	if interval == 0 {
+		return utils.BadRequest(errors.New("interval: should be positive"))
+	}
+	if (to-from)/interval >= maxBalancePoints {
+		return utils.BadRequest(fmt.Errorf("interval: too many points, max %v", maxBalancePoints))
+	}
+
+	var balances []*Balance
+	for t := from; t <= to; t += interval {
+		h, err := a.chain.GetTrunkBlockHeaderByTime(t)
+		if err != nil {
+			if a.chain.IsNotFound(err) {
+				return utils.BadRequest(errors.WithMessage(errors.New("time before genesis block"), "from"))
+			}
+			return err
+		}
+		state, err := a.stateCreator.NewState(h.StateRoot())
+		if err != nil {
+			return err
+		}
+		b := state.GetBalance(addr)
+		if err := state.Err(); err != nil {
+			return err
+		}
+		balances = append(balances, &Balance{
+			Timestamp:      t,
+			Balance:        math.HexOrDecimal256(*b),
+			BlockID:        h.ID(),
+			BlockNumber:    h.Number(),
+			BlockTimestamp: h.Timestamp(),
+		})
+		if to-t < interval {
+			break
+		}
+	}
+	return utils.WriteJSON(w, balances)
+}
+
 func (a *Accounts) handleCallContract(w http.ResponseWriter, req *http.Request) error {
 	callData := &CallData{}
 	if err := utils.ParseJSON(req.Body, &callData); err != nil {
 		return utils.BadRequest(errors.WithMessage(err, "body"))
 	}
-	h, err := a.handleRevision(req.URL.Query().Get("revision"))
+	h, err := utils.ParseRevision(a.chain, req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
@@ -222,9 +346,11 @@ func (a *Accounts) handleCallContract(w http.ResponseWriter, req *http.Request)
 				Data:  callData.Data,
 			},
 		},
-		Gas:      callData.Gas,
-		GasPrice: callData.GasPrice,
-		Caller:   callData.Caller,
+		Gas:            callData.Gas,
+		GasPrice:       callData.GasPrice,
+		Caller:         callData.Caller,
+		StateOverrides: callData.StateOverrides,
+		BlockOverride:  callData.BlockOverride,
 	}
 	results, err := a.batchCall(req.Context(), batchCallData, h)
 	if err != nil {
@@ -238,7 +364,7 @@ func (a *Accounts) handleCallBatchCode(w http.ResponseWriter, req *http.Request)
 	if err := utils.ParseJSON(req.Body, &batchCallData); err != nil {
 		return utils.BadRequest(errors.WithMessage(err, "body"))
 	}
-	h, err := a.handleRevision(req.URL.Query().Get("revision"))
+	h, err := utils.ParseRevision(a.chain, req.URL.Query().Get("revision"))
 	if err != nil {
 		return err
 	}
@@ -254,19 +380,11 @@ func (a *Accounts) batchCall(ctx context.Context, batchCallData *BatchCallData,
 	if err != nil {
 		return nil, err
 	}
-	state, err := a.stateCreator.NewState(header.StateRoot())
+	rt, err := a.newRuntime(header, batchCallData)
 	if err != nil {
 		return nil, err
 	}
-	signer, _ := header.Signer()
-	rt := runtime.New(a.chain.NewSeeker(header.ParentID()), state,
-		&xenv.BlockContext{
-			Beneficiary: header.Beneficiary(),
-			Signer:      signer,
-			Number:      header.Number(),
-			Time:        header.Timestamp(),
-			GasLimit:    header.GasLimit(),
-			TotalScore:  header.TotalScore()})
+	state := rt.State()
 	results = make(BatchCallResults, 0)
 	vmout := make(chan *runtime.Output, 1)
 	for i, clause := range clauses {
@@ -298,6 +416,196 @@ func (a *Accounts) batchCall(ctx context.Context, batchCallData *BatchCallData,
 	return results, nil
 }
 
+func (a *Accounts) handleEstimateGas(w http.ResponseWriter, req *http.Request) error {
+	batchCallData := &BatchCallData{}
+	if err := utils.ParseJSON(req.Body, &batchCallData); err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "body"))
+	}
+	h, err := utils.ParseRevision(a.chain, req.URL.Query().Get("revision"))
+	if err != nil {
+		return err
+	}
+	result, err := a.estimateGas(req.Context(), batchCallData, h)
+	if err != nil {
+		return err
+	}
+	return utils.WriteJSON(w, result)
+}
+
+// estimateGas binary searches the minimal gas for clauses to be executed as a tx.
+// The gas of call data is the upper bound, which includes intrinsic gas.
+func (a *Accounts) estimateGas(ctx context.Context, batchCallData *BatchCallData, header *block.Header) (*EstimateResult, error) {
+	gas, gasPrice, caller, clauses, err := a.handleBatchCallData(batchCallData)
+	if err != nil {
+		return nil, err
+	}
+	intrinsicGas, err := tx.IntrinsicGas(clauses...)
+	if err != nil {
+		return nil, utils.BadRequest(errors.WithMessage(err, "clauses"))
+	}
+	if gas < intrinsicGas {
+		return nil, utils.BadRequest(errors.New("gas: less than intrinsic gas"))
+	}
+	txCtx := &xenv.TransactionContext{
+		Origin:   *caller,
+		GasPrice: gasPrice}
+
+	// execute with the upper bound first
+	hi := gas - intrinsicGas
+	leftOverGas, failed, err := a.executeClauses(ctx, header, batchCallData, clauses, hi, txCtx)
+	if err != nil {
+		return nil, err
+	}
+	result := &EstimateResult{IntrinsicGas: intrinsicGas}
+	if failed != nil {
+		result.Gas = gas - leftOverGas
+		result.Reverted = true
+		result.VMError = failed.VMErr.Error()
+		result.RevertReason, _ = vm.DecodeRevertReason(failed.RevertData())
+		return result, nil
+	}
+
+	// less than gas used never succeeds
+	lo := hi - leftOverGas
+	if lo > 0 {
+		lo--
+	}
+	for lo+1 < hi {
+		mid := lo + (hi-lo)/2
+		_, failed, err := a.executeClauses(ctx, header, batchCallData, clauses, mid, txCtx)
+		if err != nil {
+			return nil, err
+		}
+		if failed != nil {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	result.Gas = intrinsicGas + hi
+	return result, nil
+}
+
+// executeClauses executes clauses on state of the header like a tx, gas refund applied after each clause.
+// Output of the failed clause is returned if any.
+func (a *Accounts) executeClauses(ctx context.Context, header *block.Header, batchCallData *BatchCallData, clauses []*tx.Clause, gas uint64, txCtx *xenv.TransactionContext) (leftOverGas uint64, failed *runtime.Output, err error) {
+	rt, err := a.newRuntime(header, batchCallData)
+	if err != nil {
+		return 0, nil, err
+	}
+	state := rt.State()
+	leftOverGas = gas
+	vmout := make(chan *runtime.Output, 1)
+	for i, clause := range clauses {
+		exec, interrupt := rt.PrepareClause(clause, uint32(i), leftOverGas, txCtx)
+		go func() {
+			out, _ := exec()
+			vmout <- out
+		}()
+		select {
+		case <-ctx.Done():
+			interrupt()
+			return 0, nil, ctx.Err()
+		case out := <-vmout:
+			if err := rt.Seeker().Err(); err != nil {
+				return 0, nil, err
+			}
+			if err := state.Err(); err != nil {
+				return 0, nil, err
+			}
+			gasUsed := leftOverGas - out.LeftOverGas
+			leftOverGas = out.LeftOverGas
+			if out.VMErr != nil {
+				return leftOverGas, out, nil
+			}
+			// same refund rule as executing tx
+			refund := gasUsed / 2
+			if refund > out.RefundGas {
+				refund = out.RefundGas
+			}
+			leftOverGas += refund
+		}
+	}
+	return leftOverGas, nil, nil
+}
+
+// newRuntime creates runtime on a throwaway state of the header, with overrides of call data applied.
+func (a *Accounts) newRuntime(header *block.Header, batchCallData *BatchCallData) (*runtime.Runtime, error) {
+	state, err := a.stateCreator.NewState(header.StateRoot())
+	if err != nil {
+		return nil, err
+	}
+	if err := applyStateOverrides(state, batchCallData.StateOverrides); err != nil {
+		return nil, err
+	}
+	signer, _ := header.Signer()
+	blockCtx := &xenv.BlockContext{
+		Beneficiary: header.Beneficiary(),
+		Signer:      signer,
+		Number:      header.Number(),
+		Time:        header.Timestamp(),
+		GasLimit:    header.GasLimit(),
+		TotalScore:  header.TotalScore()}
+	if o := batchCallData.BlockOverride; o != nil {
+		if o.Number != nil {
+			// blocks beyond the revision can't be sought
+			if *o.Number > header.Number() {
+				return nil, utils.BadRequest(errors.New("blockOverride.number: exceeds revision"))
+			}
+			blockCtx.Number = *o.Number
+		}
+		if o.Timestamp != nil {
+			blockCtx.Time = *o.Timestamp
+		}
+		if o.Beneficiary != nil {
+			blockCtx.Beneficiary = *o.Beneficiary
+		}
+	}
+	return runtime.New(a.chain.NewSeeker(header.ParentID()), state, blockCtx), nil
+}
+
+func applyStateOverrides(state *state.State, overrides map[string]*AccountOverride) error {
+	for key, o := range overrides {
+		addr, err := polo.ParseAddress(key)
+		if err != nil {
+			return utils.BadRequest(errors.WithMessage(err, "stateOverrides"))
+		}
+		if o == nil {
+			continue
+		}
+		if o.State != nil && o.StateDiff != nil {
+			return utils.BadRequest(fmt.Errorf("stateOverrides[%v]: both state and stateDiff specified", key))
+		}
+		if o.Balance != nil {
+			state.SetBalance(addr, (*big.Int)(o.Balance))
+		}
+		if o.Code != nil {
+			code, err := hexutil.Decode(*o.Code)
+			if err != nil {
+				return utils.BadRequest(errors.WithMessage(err, fmt.Sprintf("stateOverrides[%v].code", key)))
+			}
+			state.SetCode(addr, code)
+		}
+		slots := o.StateDiff
+		if o.State != nil {
+			state.ClearStorage(addr)
+			slots = o.State
+		}
+		for k, v := range slots {
+			slot, err := polo.ParseBytes32(k)
+			if err != nil {
+				return utils.BadRequest(errors.WithMessage(err, fmt.Sprintf("stateOverrides[%v].state", key)))
+			}
+			value, err := polo.ParseBytes32(v)
+			if err != nil {
+				return utils.BadRequest(errors.WithMessage(err, fmt.Sprintf("stateOverrides[%v].state", key)))
+			}
+			state.SetStorage(addr, slot, value)
+		}
+	}
+	return state.Err()
+}
+
 func (a *Accounts) handleBatchCallData(batchCallData *BatchCallData) (gas uint64, gasPrice *big.Int, caller *polo.Address, clauses []*tx.Clause, err error) {
 	if batchCallData.Gas > a.callGasLimit {
 		return 0, nil, nil, nil, utils.Forbidden(errors.New("gas: exceeds limit"))
@@ -337,48 +645,16 @@ func (a *Accounts) handleBatchCallData(batchCallData *BatchCallData) (gas uint64
 	return
 }
 
-func (a *Accounts) handleRevision(revision string) (*block.Header, error) {
-	if revision == "" || revision == "best" {
-		return a.chain.BestBlock().Header(), nil
-	}
-	if len(revision) == 66 || len(revision) == 64 {
-		blockID, err := polo.ParseBytes32(revision)
-		if err != nil {
-			return nil, utils.BadRequest(errors.WithMessage(err, "revision"))
-		}
-		h, err := a.chain.GetBlockHeader(blockID)
-		if err != nil {
-			if a.chain.IsNotFound(err) {
-				return nil, utils.BadRequest(errors.WithMessage(err, "revision"))
-			}
-			return nil, err
-		}
-		return h, nil
-	}
-	n, err := strconv.ParseUint(revision, 0, 0)
-	if err != nil {
-		return nil, utils.BadRequest(errors.WithMessage(err, "revision"))
-	}
-	if n > math.MaxUint32 {
-		return nil, utils.BadRequest(errors.WithMessage(errors.New("block number out of max uint32"), "revision"))
-	}
-	h, err := a.chain.GetTrunkBlockHeader(uint32(n))
-	if err != nil {
-		if a.chain.IsNotFound(err) {
-			return nil, utils.BadRequest(errors.WithMessage(err, "revision"))
-		}
-		return nil, err
-	}
-	return h, nil
-}
-
 func (a *Accounts) Mount(root *mux.Router, pathPrefix string) {
 	sub := root.PathPrefix(pathPrefix).Subrouter()
 
 	sub.Path("/*").Methods("POST").HandlerFunc(utils.WrapHandlerFunc(a.handleCallBatchCode))
+	sub.Path("/*/estimate").Methods("POST").HandlerFunc(utils.WrapHandlerFunc(a.handleEstimateGas))
 	sub.Path("/{address}").Methods(http.MethodGet).HandlerFunc(utils.WrapHandlerFunc(a.handleGetAccount))
 	sub.Path("/{address}/code").Methods(http.MethodGet).HandlerFunc(utils.WrapHandlerFunc(a.handleGetCode))
 	sub.Path("/{address}/storage/{key}").Methods("GET").HandlerFunc(utils.WrapHandlerFunc(a.handleGetStorage))
+	sub.Path("/{address}/balances").Methods(http.MethodGet).HandlerFunc(utils.WrapHandlerFunc(a.handleGetBalances))
+	sub.Path("/{address}/transactions").Methods(http.MethodGet).HandlerFunc(utils.WrapHandlerFunc(a.handleGetTransactions))
 	sub.Path("").Methods("POST").HandlerFunc(utils.WrapHandlerFunc(a.handleCallContract))
 	sub.Path("/{address}").Methods("POST").HandlerFunc(utils.WrapHandlerFunc(a.handleCallContract))
 
@@ -9,6 +9,7 @@ import (
 	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/HiNounou029/nounouchain/polo"
 	ABI "github.com/HiNounou029/nounouchain/nounou/abi"
 	"github.com/HiNounou029/nounouchain/nounou/genesis"
+	"github.com/HiNounou029/nounouchain/nounou/logdb"
 	"github.com/HiNounou029/nounouchain/core/chain"
 	"github.com/HiNounou029/nounouchain/core/tx"
 	"github.com/HiNounou029/nounouchain/crypto"
@@ -85,6 +87,9 @@ var invalidBytes32 = "0x00000000000000000000000000000000000000000000000000000000
 var invalidNumberRevision = "4294967296"                                                  //invalid block number
 
 var ts *httptest.Server
+var logDB *logdb.LogDB
+var deployTxID polo.Bytes32
+var genesisTime uint64
 
 func TestAccount(t *testing.T) {
 	initAccountServer(t)
@@ -95,6 +100,10 @@ func TestAccount(t *testing.T) {
 	deployContractWithCall(t)
 	callContract(t)
 	batchCall(t)
+	estimateGas(t)
+	callWithOverrides(t)
+	getTransactions(t)
+	getBalances(t)
 }
 
 func getAccount(t *testing.T) {
@@ -170,10 +179,13 @@ func initAccountServer(t *testing.T) {
 		t.Fatal(err)
 	}
 	chain, _ := chain.New(db, b)
+	logDB, _ = logdb.NewMem()
+	genesisTime = b.Header().Timestamp()
 	claTransfer := tx.NewClause(&addr).WithValue(value)
 	claDeploy := tx.NewClause(nil).WithData(bytecode)
 	transaction := buildTxWithClauses(t, chain.Tag(), claTransfer, claDeploy)
 	contractAddr = polo.CreateContractAddress(transaction.ID(), 1, 0)
+	deployTxID = transaction.ID()
 	packTx(chain, stateC, transaction, t)
 
 	method := "set"
@@ -188,7 +200,7 @@ func initAccountServer(t *testing.T) {
 	packTx(chain, stateC, transactionCall, t)
 
 	router := mux.NewRouter()
-	accounts.New(chain, stateC, math.MaxUint64).Mount(router, "/accounts")
+	accounts.New(chain, stateC, logDB, math.MaxUint64).Mount(router, "/accounts")
 	ts = httptest.NewServer(router)
 }
 
@@ -227,6 +239,88 @@ func packTx(chain *chain.Chain, stateC *state.Creator, transaction *tx.Transacti
 	if _, err := chain.AddBlock(b, receipts); err != nil {
 		t.Fatal(err)
 	}
+	batch := logDB.Prepare(b.Header())
+	for i, trx := range b.Transactions() {
+		origin, _ := trx.Signer()
+		batch.InsertTx(uint32(i), trx, origin, receipts[i].Reverted)
+	}
+	if err := batch.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func getBalances(t *testing.T) {
+	from := strconv.FormatUint(genesisTime, 10)
+	interval := uint64(time.Now().Unix()) + 3600 - genesisTime
+	to := strconv.FormatUint(genesisTime+interval, 10)
+
+	_, statusCode := httpGet(t, ts.URL+"/accounts/"+addr.String()+"/balances?from="+from+"&to="+to)
+	assert.Equal(t, http.StatusBadRequest, statusCode, "missing interval")
+	_, statusCode = httpGet(t, ts.URL+"/accounts/"+addr.String()+"/balances?from="+to+"&to="+from+"&interval=1")
+	assert.Equal(t, http.StatusBadRequest, statusCode, "to less than from")
+	_, statusCode = httpGet(t, ts.URL+"/accounts/"+addr.String()+"/balances?from="+from+"&to="+to+"&interval=1")
+	assert.Equal(t, http.StatusBadRequest, statusCode, "too many points")
+	_, statusCode = httpGet(t, ts.URL+"/accounts/"+addr.String()+"/balances?from=0&to="+from+"&interval=86400")
+	assert.Equal(t, http.StatusBadRequest, statusCode, "before genesis")
+
+	res, statusCode := httpGet(t, ts.URL+"/accounts/"+addr.String()+"/balances?from="+from+"&to="+to+"&interval="+strconv.FormatUint(interval, 10))
+	assert.Equal(t, http.StatusOK, statusCode, "OK")
+	var balances []*accounts.Balance
+	if err := json.Unmarshal(res, &balances); err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, balances, 2) {
+		assert.Equal(t, genesisTime, balances[0].Timestamp)
+		assert.Equal(t, uint32(0), balances[0].BlockNumber)
+		assert.Equal(t, 0, (*big.Int)(&balances[0].Balance).Sign())
+		assert.Equal(t, math.HexOrDecimal256(*value), balances[1].Balance)
+	}
+
+	// revision by time
+	res, statusCode = httpGet(t, ts.URL+"/accounts/"+addr.String()+"?revision=t:"+time.Unix(int64(genesisTime), 0).UTC().Format(time.RFC3339))
+	assert.Equal(t, http.StatusOK, statusCode, "OK")
+	var acc accounts.Account
+	if err := json.Unmarshal(res, &acc); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, (*big.Int)(&acc.Balance).Sign(), "balance at genesis")
+	_, statusCode = httpGet(t, ts.URL+"/accounts/"+addr.String()+"?revision=t:0")
+	assert.Equal(t, http.StatusBadRequest, statusCode, "before genesis")
+}
+
+func getTransactions(t *testing.T) {
+	_, statusCode := httpGet(t, ts.URL+"/accounts/"+invalidAddr+"/transactions")
+	assert.Equal(t, http.StatusBadRequest, statusCode, "bad address")
+	_, statusCode = httpGet(t, ts.URL+"/accounts/"+addr.String()+"/transactions?order=up")
+	assert.Equal(t, http.StatusBadRequest, statusCode, "bad order")
+	_, statusCode = httpGet(t, ts.URL+"/accounts/"+addr.String()+"/transactions?limit=1001")
+	assert.Equal(t, http.StatusBadRequest, statusCode, "limit exceeded")
+
+	getTxs := func(address polo.Address, query string) []*accounts.AccountTx {
+		res, statusCode := httpGet(t, ts.URL+"/accounts/"+address.String()+"/transactions"+query)
+		assert.Equal(t, http.StatusOK, statusCode, "OK")
+		var txs []*accounts.AccountTx
+		if err := json.Unmarshal(res, &txs); err != nil {
+			t.Fatal(err)
+		}
+		return txs
+	}
+	// clause recipient
+	txs := getTxs(addr, "")
+	if assert.Len(t, txs, 1) {
+		assert.Equal(t, deployTxID, txs[0].Meta.TxID)
+		assert.Equal(t, genesis.DevAccounts()[0].Address, txs[0].Meta.TxOrigin)
+	}
+	// contract created and called
+	txs = getTxs(contractAddr, "?order=desc")
+	if assert.Len(t, txs, 2) {
+		assert.Equal(t, deployTxID, txs[1].Meta.TxID)
+		assert.True(t, txs[0].Meta.BlockNumber > txs[1].Meta.BlockNumber)
+	}
+	// origin
+	assert.Len(t, getTxs(genesis.DevAccounts()[0].Address, ""), 2)
+	assert.Len(t, getTxs(genesis.DevAccounts()[0].Address, "?offset=1&limit=10"), 1)
+	assert.Len(t, getTxs(polo.BytesToAddress([]byte("nobody")), ""), 0)
 }
 
 func deployContractWithCall(t *testing.T) {
@@ -358,6 +452,127 @@ func batchCall(t *testing.T) {
 	assert.Equal(t, http.StatusOK, statusCode)
 }
 
+func estimateGas(t *testing.T) {
+	abi, err := ABI.New([]byte(abiJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, _ := abi.MethodByName("set")
+	input, err := m.EncodeInput(uint8(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqBody := &accounts.BatchCallData{
+		Clauses: accounts.Clauses{
+			accounts.Clause{
+				To:   &contractAddr,
+				Data: hexutil.Encode(input),
+			}},
+	}
+	res, statusCode := httpPost(t, ts.URL+"/accounts/*/estimate", reqBody)
+	assert.Equal(t, http.StatusOK, statusCode)
+	var result accounts.EstimateResult
+	if err := json.Unmarshal(res, &result); err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, result.Reverted)
+	intrinsicGas, _ := tx.IntrinsicGas(tx.NewClause(&contractAddr).WithData(input))
+	assert.Equal(t, intrinsicGas, result.IntrinsicGas)
+
+	// estimated gas is the minimal one
+	call := func(gas uint64) bool {
+		reqBody.Gas = gas
+		res, _ := httpPost(t, ts.URL+"/accounts/*", reqBody)
+		var results accounts.BatchCallResults
+		if err := json.Unmarshal(res, &results); err != nil {
+			t.Fatal(err)
+		}
+		return results[0].Reverted
+	}
+	assert.False(t, call(result.Gas-result.IntrinsicGas))
+	assert.True(t, call(result.Gas-result.IntrinsicGas-1))
+
+	reqBody.Gas = intrinsicGas - 1
+	_, statusCode = httpPost(t, ts.URL+"/accounts/*/estimate", reqBody)
+	assert.Equal(t, http.StatusBadRequest, statusCode, "gas less than intrinsic gas")
+
+	// unknown method falls into reverting fallback
+	reqBody.Gas = 0
+	reqBody.Clauses[0].Data = "0x12345678"
+	res, statusCode = httpPost(t, ts.URL+"/accounts/*/estimate", reqBody)
+	assert.Equal(t, http.StatusOK, statusCode)
+	if err := json.Unmarshal(res, &result); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, result.Reverted)
+}
+
+func callWithOverrides(t *testing.T) {
+	target := polo.BytesToAddress([]byte("override"))
+	call := func(body *accounts.CallData) (*accounts.CallResult, int) {
+		res, statusCode := httpPost(t, ts.URL+"/accounts/"+target.String(), body)
+		if statusCode != http.StatusOK {
+			return nil, statusCode
+		}
+		var output *accounts.CallResult
+		if err := json.Unmarshal(res, &output); err != nil {
+			t.Fatal(err)
+		}
+		return output, statusCode
+	}
+	word := func(data string) uint64 {
+		return new(big.Int).SetBytes(hexutil.MustDecode(data)).Uint64()
+	}
+
+	// return SLOAD(0)
+	sloadCode := "0x60005460005260206000f3"
+	output, _ := call(&accounts.CallData{
+		StateOverrides: map[string]*accounts.AccountOverride{
+			target.String(): {
+				Code:  &sloadCode,
+				State: map[string]string{polo.Bytes32{}.String(): polo.BytesToBytes32([]byte{42}).String()},
+			},
+		},
+	})
+	assert.False(t, output.Reverted)
+	assert.Equal(t, uint64(42), word(output.Data))
+
+	// return TIMESTAMP
+	timeCode := "0x4260005260206000f3"
+	timestamp := uint64(12345)
+	output, _ = call(&accounts.CallData{
+		StateOverrides: map[string]*accounts.AccountOverride{target.String(): {Code: &timeCode}},
+		BlockOverride:  &accounts.BlockOverride{Timestamp: &timestamp},
+	})
+	assert.Equal(t, timestamp, word(output.Data))
+
+	// value transfer with overridden balance
+	caller := polo.BytesToAddress([]byte("caller"))
+	output, _ = call(&accounts.CallData{
+		Value:  (*math.HexOrDecimal256)(big.NewInt(100)),
+		Caller: &caller,
+		StateOverrides: map[string]*accounts.AccountOverride{
+			caller.String(): {Balance: (*math.HexOrDecimal256)(big.NewInt(100))},
+		},
+	})
+	assert.False(t, output.Reverted)
+
+	_, statusCode := call(&accounts.CallData{
+		StateOverrides: map[string]*accounts.AccountOverride{
+			target.String(): {
+				State:     map[string]string{},
+				StateDiff: map[string]string{},
+			},
+		},
+	})
+	assert.Equal(t, http.StatusBadRequest, statusCode, "both state and stateDiff")
+
+	_, statusCode = call(&accounts.CallData{
+		StateOverrides: map[string]*accounts.AccountOverride{invalidAddr: {}},
+	})
+	assert.Equal(t, http.StatusBadRequest, statusCode, "invalid address")
+}
+
 func httpPost(t *testing.T, url string, body interface{}) ([]byte, int) {
 	data, err := json.Marshal(body)
 	if err != nil {
@@ -4,7 +4,9 @@ package accounts
 
 import (
 	"github.com/HiNounou029/nounouchain/api/transactions"
+	"github.com/HiNounou029/nounouchain/nounou/logdb"
 	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/vm"
 	"github.com/HiNounou029/nounouchain/vm/runtime"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
@@ -18,32 +20,83 @@ type Account struct {
 
 //CallData represents contract-call body
 type CallData struct {
-	Value    *math.HexOrDecimal256 `json:"value"`
-	Data     string                `json:"data"`
-	Gas      uint64                `json:"gas"`
-	GasPrice *math.HexOrDecimal256 `json:"gasPrice"`
-	Caller   *polo.Address         `json:"caller"`
+	Value          *math.HexOrDecimal256       `json:"value"`
+	Data           string                      `json:"data"`
+	Gas            uint64                      `json:"gas"`
+	GasPrice       *math.HexOrDecimal256       `json:"gasPrice"`
+	Caller         *polo.Address               `json:"caller"`
+	StateOverrides map[string]*AccountOverride `json:"stateOverrides"`
+	BlockOverride  *BlockOverride              `json:"blockOverride"`
+}
+
+// AccountOverride overrides an account before simulating calls, keyed by address.
+// State replaces the whole storage, while StateDiff replaces individual slots.
+type AccountOverride struct {
+	Balance   *math.HexOrDecimal256 `json:"balance"`
+	Code      *string               `json:"code"`
+	State     map[string]string     `json:"state"`
+	StateDiff map[string]string     `json:"stateDiff"`
+}
+
+// BlockOverride overrides block context before simulating calls.
+type BlockOverride struct {
+	Number      *uint32       `json:"number"`
+	Timestamp   *uint64       `json:"timestamp"`
+	Beneficiary *polo.Address `json:"beneficiary"`
+}
+
+// Balance balance of the account at the time, read from the latest block not after the time.
+type Balance struct {
+	Timestamp      uint64               `json:"timestamp"`
+	Balance        math.HexOrDecimal256 `json:"balance"`
+	BlockID        polo.Bytes32         `json:"blockID"`
+	BlockNumber    uint32               `json:"blockNumber"`
+	BlockTimestamp uint64               `json:"blockTimestamp"`
+}
+
+// AccountTx a tx sent by the account, or has a clause to or creating the account.
+type AccountTx struct {
+	TxIndex  uint32               `json:"txIndex"`
+	Reverted bool                 `json:"reverted"`
+	Meta     transactions.LogMeta `json:"meta"`
+}
+
+func convertAccountTx(atx *logdb.AccountTx) *AccountTx {
+	return &AccountTx{
+		TxIndex:  atx.TxIndex,
+		Reverted: atx.Reverted,
+		Meta: transactions.LogMeta{
+			BlockID:        atx.BlockID,
+			BlockNumber:    atx.BlockNumber,
+			BlockTimestamp: atx.BlockTime,
+			TxID:           atx.TxID,
+			TxOrigin:       atx.TxOrigin,
+		},
+	}
 }
 
 type CallResult struct {
-	Data      string                   `json:"data"`
-	Events    []*transactions.Event    `json:"events"`
-	Transfers []*transactions.Transfer `json:"transfers"`
-	GasUsed   uint64                   `json:"gasUsed"`
-	Reverted  bool                     `json:"reverted"`
-	VMError   string                   `json:"vmError"`
+	Data         string                   `json:"data"`
+	Events       []*transactions.Event    `json:"events"`
+	Transfers    []*transactions.Transfer `json:"transfers"`
+	GasUsed      uint64                   `json:"gasUsed"`
+	Reverted     bool                     `json:"reverted"`
+	VMError      string                   `json:"vmError"`
+	RevertReason string                   `json:"revertReason,omitempty"`
 }
 
 func convertCallResultWithInputGas(vo *runtime.Output, inputGas uint64) *CallResult {
 	gasUsed := inputGas - vo.LeftOverGas
 	var (
-		vmError  string
-		reverted bool
+		vmError      string
+		reverted     bool
+		revertReason string
 	)
 
 	if vo.VMErr != nil {
 		reverted = true
 		vmError = vo.VMErr.Error()
+		revertReason, _ = vm.DecodeRevertReason(vo.RevertData())
 	}
 
 	events := make([]*transactions.Event, len(vo.Events))
@@ -70,12 +123,13 @@ func convertCallResultWithInputGas(vo *runtime.Output, inputGas uint64) *CallRes
 	}
 
 	return &CallResult{
-		Data:      hexutil.Encode(vo.Data),
-		Events:    events,
-		Transfers: transfers,
-		GasUsed:   gasUsed,
-		Reverted:  reverted,
-		VMError:   vmError,
+		Data:         hexutil.Encode(vo.Data),
+		Events:       events,
+		Transfers:    transfers,
+		GasUsed:      gasUsed,
+		Reverted:     reverted,
+		VMError:      vmError,
+		RevertReason: revertReason,
 	}
 }
 
@@ -90,10 +144,22 @@ type Clauses []Clause
 
 //BatchCallData executes a batch of codes
 type BatchCallData struct {
-	Clauses  Clauses               `json:"clauses"`
-	Gas      uint64                `json:"gas"`
-	GasPrice *math.HexOrDecimal256 `json:"gasPrice"`
-	Caller   *polo.Address         `json:"caller"`
+	Clauses        Clauses                     `json:"clauses"`
+	Gas            uint64                      `json:"gas"`
+	GasPrice       *math.HexOrDecimal256       `json:"gasPrice"`
+	Caller         *polo.Address               `json:"caller"`
+	StateOverrides map[string]*AccountOverride `json:"stateOverrides"`
+	BlockOverride  *BlockOverride              `json:"blockOverride"`
 }
 
 type BatchCallResults []*CallResult
+
+// EstimateResult is the result of gas estimation.
+// Gas includes intrinsic gas, which is enough for the tx to succeed, or used gas if reverted.
+type EstimateResult struct {
+	Gas          uint64 `json:"gas"`
+	IntrinsicGas uint64 `json:"intrinsicGas"`
+	Reverted     bool   `json:"reverted"`
+	VMError      string `json:"vmError"`
+	RevertReason string `json:"revertReason,omitempty"`
+}
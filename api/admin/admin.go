@@ -0,0 +1,188 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+// Package admin implements the admin API, to manage peers, tx pool and logging of the running node.
+package admin
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/HiNounou029/nounouchain/api/node"
+	"github.com/HiNounou029/nounouchain/api/utils"
+	"github.com/HiNounou029/nounouchain/core/txpool"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/ethereum/go-ethereum/p2p/discover"
+	"github.com/gorilla/mux"
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+)
+
+var log = log15.New("pkg", "admin")
+
+// max of log verbosity, same as the verbosity flag
+const maxVerbosity = 9
+
+type Admin struct {
+	nw       Network
+	txPool   *txpool.TxPool
+	logLevel LogLevel
+}
+
+func New(nw Network, txPool *txpool.TxPool, logLevel LogLevel) *Admin {
+	return &Admin{
+		nw,
+		txPool,
+		logLevel,
+	}
+}
+
+func parseNodeID(req *http.Request) (discover.NodeID, error) {
+	id, err := discover.HexID(mux.Vars(req)["id"])
+	if err != nil {
+		return discover.NodeID{}, utils.BadRequest(errors.WithMessage(err, "id"))
+	}
+	return id, nil
+}
+
+func (a *Admin) handleGetPeers(w http.ResponseWriter, req *http.Request) error {
+	return utils.WriteJSON(w, node.ConvertPeersStats(a.nw.PeersStats()))
+}
+
+func (a *Admin) handleAddStaticPeer(w http.ResponseWriter, req *http.Request) error {
+	var body StaticPeer
+	if err := utils.ParseJSON(req.Body, &body); err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "body"))
+	}
+	n, err := discover.ParseNode(body.Enode)
+	if err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "enode"))
+	}
+	a.nw.AddStatic(n)
+	log.Info("static peer added", "enode", n)
+	return utils.WriteJSON(w, utils.M{})
+}
+
+func (a *Admin) handleRemoveStaticPeer(w http.ResponseWriter, req *http.Request) error {
+	id, err := parseNodeID(req)
+	if err != nil {
+		return err
+	}
+	// only id is used to remove
+	a.nw.RemoveStatic(&discover.Node{ID: id})
+	log.Info("static peer removed", "id", id)
+	return utils.WriteJSON(w, utils.M{})
+}
+
+func (a *Admin) handleDisconnectPeer(w http.ResponseWriter, req *http.Request) error {
+	id, err := parseNodeID(req)
+	if err != nil {
+		return err
+	}
+	if !a.nw.DisconnectPeer(id) {
+		return utils.HTTPError(errors.New("peer not connected"), http.StatusNotFound)
+	}
+	log.Info("peer disconnected", "id", id)
+	return utils.WriteJSON(w, utils.M{})
+}
+
+func (a *Admin) handleBanPeer(w http.ResponseWriter, req *http.Request) error {
+	id, err := parseNodeID(req)
+	if err != nil {
+		return err
+	}
+	var body Ban
+	if err := utils.ParseJSON(req.Body, &body); err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "body"))
+	}
+	duration := time.Duration(body.Duration) * time.Second
+	// a banned peer would be re-dialed if static
+	a.nw.RemoveStatic(&discover.Node{ID: id})
+	a.nw.BanPeer(id, duration)
+	log.Info("peer banned", "id", id, "duration", duration)
+	return utils.WriteJSON(w, utils.M{})
+}
+
+func (a *Admin) handleUnbanPeer(w http.ResponseWriter, req *http.Request) error {
+	id, err := parseNodeID(req)
+	if err != nil {
+		return err
+	}
+	if !a.nw.UnbanPeer(id) {
+		return utils.HTTPError(errors.New("peer not banned"), http.StatusNotFound)
+	}
+	log.Info("peer unbanned", "id", id)
+	return utils.WriteJSON(w, utils.M{})
+}
+
+func (a *Admin) handleGetBannedPeers(w http.ResponseWriter, req *http.Request) error {
+	banned := make([]*BannedPeer, 0)
+	for id, expiry := range a.nw.BannedPeers() {
+		bp := &BannedPeer{PeerID: id.String()}
+		if !expiry.IsZero() {
+			bp.Expiry = uint64(expiry.Unix())
+		}
+		banned = append(banned, bp)
+	}
+	sort.Slice(banned, func(i, j int) bool {
+		return banned[i].PeerID < banned[j].PeerID
+	})
+	return utils.WriteJSON(w, banned)
+}
+
+func (a *Admin) handleRemoveTx(w http.ResponseWriter, req *http.Request) error {
+	id, err := polo.ParseBytes32(mux.Vars(req)["id"])
+	if err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "id"))
+	}
+	if !a.txPool.Remove(id) {
+		return utils.HTTPError(errors.New("tx not found in pool"), http.StatusNotFound)
+	}
+	log.Info("tx removed from pool", "id", id)
+	return utils.WriteJSON(w, utils.M{})
+}
+
+func (a *Admin) handleFlushTxPool(w http.ResponseWriter, req *http.Request) error {
+	removed := a.txPool.Flush()
+	log.Info("tx pool flushed", "removed", removed)
+	return utils.WriteJSON(w, utils.M{"removed": removed})
+}
+
+func (a *Admin) handleWashTxPool(w http.ResponseWriter, req *http.Request) error {
+	a.txPool.Wash()
+	return utils.WriteJSON(w, utils.M{})
+}
+
+func (a *Admin) handleGetVerbosity(w http.ResponseWriter, req *http.Request) error {
+	return utils.WriteJSON(w, &Verbosity{int(a.logLevel.Level())})
+}
+
+func (a *Admin) handleSetVerbosity(w http.ResponseWriter, req *http.Request) error {
+	var body Verbosity
+	if err := utils.ParseJSON(req.Body, &body); err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "body"))
+	}
+	if body.Verbosity < 0 || body.Verbosity > maxVerbosity {
+		return utils.BadRequest(errors.New("verbosity: out of range"))
+	}
+	a.logLevel.SetLevel(log15.Lvl(body.Verbosity))
+	log.Info("log verbosity changed", "verbosity", body.Verbosity)
+	return utils.WriteJSON(w, &body)
+}
+
+func (a *Admin) Mount(root *mux.Router, pathPrefix string) {
+	sub := root.PathPrefix(pathPrefix).Subrouter()
+
+	sub.Path("/peers").Methods("GET").HandlerFunc(utils.WrapHandlerFunc(a.handleGetPeers))
+	sub.Path("/peers/static").Methods("POST").HandlerFunc(utils.WrapHandlerFunc(a.handleAddStaticPeer))
+	sub.Path("/peers/static/{id}").Methods("DELETE").HandlerFunc(utils.WrapHandlerFunc(a.handleRemoveStaticPeer))
+	sub.Path("/peers/banned").Methods("GET").HandlerFunc(utils.WrapHandlerFunc(a.handleGetBannedPeers))
+	sub.Path("/peers/{id}/disconnect").Methods("POST").HandlerFunc(utils.WrapHandlerFunc(a.handleDisconnectPeer))
+	sub.Path("/peers/{id}/ban").Methods("POST").HandlerFunc(utils.WrapHandlerFunc(a.handleBanPeer))
+	sub.Path("/peers/{id}/ban").Methods("DELETE").HandlerFunc(utils.WrapHandlerFunc(a.handleUnbanPeer))
+	sub.Path("/txpool/txs").Methods("DELETE").HandlerFunc(utils.WrapHandlerFunc(a.handleFlushTxPool))
+	sub.Path("/txpool/txs/{id}").Methods("DELETE").HandlerFunc(utils.WrapHandlerFunc(a.handleRemoveTx))
+	sub.Path("/txpool/wash").Methods("POST").HandlerFunc(utils.WrapHandlerFunc(a.handleWashTxPool))
+	sub.Path("/log/verbosity").Methods("GET").HandlerFunc(utils.WrapHandlerFunc(a.handleGetVerbosity))
+	sub.Path("/log/verbosity").Methods("PUT").HandlerFunc(utils.WrapHandlerFunc(a.handleSetVerbosity))
+}
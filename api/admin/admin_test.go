@@ -0,0 +1,160 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package admin_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HiNounou029/nounouchain/api/admin"
+	"github.com/HiNounou029/nounouchain/core/chain"
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/core/txpool"
+	"github.com/HiNounou029/nounouchain/crypto"
+	"github.com/HiNounou029/nounouchain/network/comm"
+	"github.com/HiNounou029/nounouchain/nounou/genesis"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/HiNounou029/nounouchain/storage"
+	"github.com/ethereum/go-ethereum/p2p/discover"
+	"github.com/gorilla/mux"
+	"github.com/inconshreveable/log15"
+	"github.com/stretchr/testify/assert"
+)
+
+const nodeID = "a979fb575495b8d6db44f750317d0f4622bf4c2aa3365d6af7c284339968eef29b69ad0dce72a4d8db5ebb4968de0e3bec910127f134779fbcb0cb6d3331163c"
+
+// network with a real communicator, and static peers recorded.
+type network struct {
+	*comm.Communicator
+	statics map[discover.NodeID]bool
+}
+
+func (n *network) AddStatic(node *discover.Node)    { n.statics[node.ID] = true }
+func (n *network) RemoveStatic(node *discover.Node) { delete(n.statics, node.ID) }
+
+type logLevel struct{ lvl log15.Lvl }
+
+func (l *logLevel) Level() log15.Lvl       { return l.lvl }
+func (l *logLevel) SetLevel(lvl log15.Lvl) { l.lvl = lvl }
+
+func newTx(t *testing.T, c *chain.Chain, nonce uint64) *tx.Transaction {
+	to := polo.BytesToAddress([]byte("to"))
+	trx := new(tx.Builder).
+		ChainTag(c.Tag()).
+		Expiration(10).
+		Gas(21000).
+		Nonce(nonce).
+		Clause(tx.NewClause(&to).WithValue(big.NewInt(10000))).
+		BlockRef(tx.NewBlockRef(0)).
+		Build()
+	sig, err := crypto.Sign(trx.SigningHash().Bytes(), genesis.DevAccounts()[0].PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return trx.WithSignature(sig)
+}
+
+func TestAdmin(t *testing.T) {
+	db, _ := storage.NewMem()
+	stateC := state.NewCreator(db)
+	b, _, err := genesis.NewDevnet().Build(stateC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := chain.New(db, b)
+	pool, err := txpool.New(c, stateC, txpool.Options{
+		Limit:           10000,
+		LimitPerAccount: 16,
+		MaxLifetime:     10 * time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	nw := &network{comm.New(c, pool, "", false, nil), make(map[discover.NodeID]bool)}
+	level := &logLevel{log15.LvlInfo}
+
+	router := mux.NewRouter()
+	admin.New(nw, pool, level).Mount(router, "/admin")
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	call := func(method, path, body string) (int, string) {
+		req, _ := http.NewRequest(method, ts.URL+path, bytes.NewReader([]byte(body)))
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		data, _ := ioutil.ReadAll(res.Body)
+		return res.StatusCode, strings.TrimSpace(string(data))
+	}
+	id := discover.MustHexID(nodeID)
+
+	// static peers
+	status, _ := call("POST", "/admin/peers/static", `{"enode":"enode://`+nodeID+`@127.0.0.1:11235"}`)
+	assert.Equal(t, 200, status)
+	assert.True(t, nw.statics[id])
+	status, _ = call("POST", "/admin/peers/static", `{"enode":"bad"}`)
+	assert.Equal(t, 400, status)
+	status, _ = call("DELETE", "/admin/peers/static/"+nodeID, "")
+	assert.Equal(t, 200, status)
+	assert.False(t, nw.statics[id])
+
+	// peers
+	status, _ = call("POST", "/admin/peers/"+nodeID+"/disconnect", "")
+	assert.Equal(t, 404, status, "not connected")
+	status, _ = call("POST", "/admin/peers/0x01/disconnect", "")
+	assert.Equal(t, 400, status)
+
+	status, _ = call("POST", "/admin/peers/"+nodeID+"/ban", `{"duration":3600}`)
+	assert.Equal(t, 200, status)
+	status, body := call("GET", "/admin/peers/banned", "")
+	assert.Equal(t, 200, status)
+	var banned []*admin.BannedPeer
+	assert.Nil(t, json.Unmarshal([]byte(body), &banned))
+	if assert.Len(t, banned, 1) {
+		assert.Equal(t, nodeID, banned[0].PeerID)
+		assert.InDelta(t, time.Now().Add(time.Hour).Unix(), banned[0].Expiry, 5)
+	}
+	status, _ = call("DELETE", "/admin/peers/"+nodeID+"/ban", "")
+	assert.Equal(t, 200, status)
+	status, _ = call("DELETE", "/admin/peers/"+nodeID+"/ban", "")
+	assert.Equal(t, 404, status, "not banned")
+	assert.Len(t, nw.BannedPeers(), 0)
+
+	// tx pool
+	trx1, trx2 := newTx(t, c, 1), newTx(t, c, 2)
+	pool.Fill(tx.Transactions{trx1})
+	status, _ = call("DELETE", "/admin/txpool/txs/"+trx1.ID().String(), "")
+	assert.Equal(t, 200, status)
+	status, _ = call("DELETE", "/admin/txpool/txs/"+trx1.ID().String(), "")
+	assert.Equal(t, 404, status)
+	pool.Fill(tx.Transactions{trx1, trx2})
+	status, body = call("DELETE", "/admin/txpool/txs", "")
+	assert.Equal(t, 200, status)
+	var flushed map[string]int
+	assert.Nil(t, json.Unmarshal([]byte(body), &flushed))
+	assert.Equal(t, map[string]int{"removed": 2}, flushed)
+	assert.Len(t, pool.Dump(), 0)
+	status, _ = call("POST", "/admin/txpool/wash", "")
+	assert.Equal(t, 200, status)
+
+	// log verbosity
+	status, body = call("GET", "/admin/log/verbosity", "")
+	assert.Equal(t, 200, status)
+	assert.JSONEq(t, `{"verbosity":3}`, body)
+	status, _ = call("PUT", "/admin/log/verbosity", `{"verbosity":5}`)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, log15.Lvl(5), level.lvl)
+	status, _ = call("PUT", "/admin/log/verbosity", `{"verbosity":10}`)
+	assert.Equal(t, 400, status)
+}
@@ -0,0 +1,51 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package admin
+
+import (
+	"time"
+
+	"github.com/HiNounou029/nounouchain/network/comm"
+	"github.com/ethereum/go-ethereum/p2p/discover"
+	"github.com/inconshreveable/log15"
+)
+
+// Network the p2p network controlled by admin API.
+type Network interface {
+	AddStatic(node *discover.Node)
+	RemoveStatic(node *discover.Node)
+	PeersStats() []*comm.PeerStats
+	DisconnectPeer(id discover.NodeID) bool
+	BanPeer(id discover.NodeID, duration time.Duration)
+	UnbanPeer(id discover.NodeID) bool
+	BannedPeers() map[discover.NodeID]time.Time
+}
+
+// LogLevel the log verbosity controlled by admin API.
+type LogLevel interface {
+	Level() log15.Lvl
+	SetLevel(lvl log15.Lvl)
+}
+
+// StaticPeer body to add a static peer.
+type StaticPeer struct {
+	Enode string `json:"enode"`
+}
+
+// Ban body to ban a peer.
+type Ban struct {
+	// in seconds, 0 for forever
+	Duration uint64 `json:"duration"`
+}
+
+// BannedPeer a banned peer.
+type BannedPeer struct {
+	PeerID string `json:"peerID"`
+	// unix timestamp the ban expires, 0 for forever
+	Expiry uint64 `json:"expiry"`
+}
+
+// Verbosity body of log verbosity (0-9), same as the verbosity flag.
+type Verbosity struct {
+	Verbosity int `json:"verbosity"`
+}
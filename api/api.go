@@ -9,9 +9,13 @@ import (
 	"strings"
 
 	"github.com/HiNounou029/nounouchain/api/accounts"
+	"github.com/HiNounou029/nounouchain/api/auth"
 	"github.com/HiNounou029/nounouchain/api/blocks"
+	"github.com/HiNounou029/nounouchain/api/debug"
+	"github.com/HiNounou029/nounouchain/api/eth"
 	"github.com/HiNounou029/nounouchain/api/events"
 	"github.com/HiNounou029/nounouchain/api/eventslegacy"
+	"github.com/HiNounou029/nounouchain/api/graphql"
 	"github.com/HiNounou029/nounouchain/api/node"
 	"github.com/HiNounou029/nounouchain/api/status"
 	"github.com/HiNounou029/nounouchain/api/subscriptions"
@@ -19,6 +23,7 @@ import (
 	"github.com/HiNounou029/nounouchain/api/transfers"
 	"github.com/HiNounou029/nounouchain/api/transferslegacy"
 	"github.com/HiNounou029/nounouchain/nounou/logdb"
+	"github.com/HiNounou029/nounouchain/common/ratelimit"
 	"github.com/HiNounou029/nounouchain/core/chain"
 	"github.com/HiNounou029/nounouchain/core/txpool"
 	"github.com/HiNounou029/nounouchain/state"
@@ -33,7 +38,7 @@ var (
 //New return api router
 func New(chain *chain.Chain, stateCreator *state.Creator, txPool *txpool.TxPool,
 	logDB *logdb.LogDB, nw node.Network, allowedOrigins string,
-	backtraceLimit uint32, callGasLimit uint64, path string) (http.HandlerFunc, func()) {
+	backtraceLimit uint32, callGasLimit uint64, graphQLCostLimit uint64, path string, txLimiter *ratelimit.Limiter, enableDebug bool, authenticator *auth.Auth) (http.HandlerFunc, func()) {
 	origins := strings.Split(strings.TrimSpace(allowedOrigins), ",")
 	for i, o := range origins {
 		origins[i] = strings.ToLower(strings.TrimSpace(o))
@@ -41,7 +46,7 @@ func New(chain *chain.Chain, stateCreator *state.Creator, txPool *txpool.TxPool,
 
 	router := mux.NewRouter()
 
-	accounts.New(chain, stateCreator, callGasLimit).
+	accounts.New(chain, stateCreator, logDB, callGasLimit).
 		Mount(router, "/accounts")
 	eventslegacy.New(logDB).
 		Mount(router, "/events")
@@ -59,22 +64,41 @@ func New(chain *chain.Chain, stateCreator *state.Creator, txPool *txpool.TxPool,
 		Mount(router, "/blocks")
 	status.New(chain).
 		Mount(router, "/status")
-	transactions.New(chain, txPool).
+	transactions.New(chain, stateCreator, txPool, txLimiter).
 		Mount(router, "/transactions")
 	node.New(nw).
 		Mount(router, "/node")
 	authority.New(chain, stateCreator).
 		Mount(router, "/authority")
+	graphql.New(chain, stateCreator, logDB, graphQLCostLimit).
+		Mount(router, "/graphql")
 
 	cert.New(path).Mount(router, "/verify")
 
-	subs := subscriptions.New(chain, origins, backtraceLimit)
+	if enableDebug {
+		debug.New(chain, stateCreator, callGasLimit).
+			Mount(router, "/debug")
+	}
+
+	subs := subscriptions.New(chain, txPool, origins, backtraceLimit)
 	subs.Mount(router, "/subscriptions")
 
+	ethRPC := eth.New(chain, stateCreator, txPool, logDB, txLimiter, callGasLimit, "nounou/v"+ApiVer, origins)
+	ethRPC.Mount(router, "/eth")
+
+	var handler http.Handler = router
+	if authenticator != nil {
+		handler = authenticator.Handler(router)
+	}
+	handler = handleMetrics(router, handler)
 	return handlers.CORS(
 			handlers.AllowedOrigins(origins),
 			handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-			handlers.AllowedHeaders([]string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "x-genesis-id"}),
-            handlers.AllowCredentials())(router).ServeHTTP,
-		subs.Close // subscriptions handles hijacked conns, which need to be closed
+			handlers.AllowedHeaders([]string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "x-genesis-id", auth.KeyHeader}),
+            handlers.AllowCredentials())(handler).ServeHTTP,
+		func() {
+			// subscriptions and eth websocket handle hijacked conns, which need to be closed
+			subs.Close()
+			ethRPC.Close()
+		}
 }
@@ -0,0 +1,162 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+// Package auth authenticates API requests, checks the role required by the route, and enforces
+// per-identity request quotas.
+//
+// Requests are authenticated by, in order, client certificate, API key (X-API-Key header),
+// or JWT (Authorization: Bearer header). Requests without any credential get the anonymous role.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/HiNounou029/nounouchain/common/ratelimit"
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+)
+
+var log = log15.New("pkg", "auth")
+
+// Role permission level, of which each includes permissions of lower ones.
+type Role int
+
+// roles
+const (
+	RoleNone Role = iota
+	RoleRead
+	RoleSubmit
+	RoleAdmin
+)
+
+var roleNames = []string{"none", "read", "submit", "admin"}
+
+func (r Role) String() string {
+	if r >= 0 && int(r) < len(roleNames) {
+		return roleNames[r]
+	}
+	return fmt.Sprintf("role(%d)", int(r))
+}
+
+// ParseRole parses role from name, where empty name means none.
+func ParseRole(name string) (Role, error) {
+	if name == "" {
+		return RoleNone, nil
+	}
+	for i, n := range roleNames {
+		if n == name {
+			return Role(i), nil
+		}
+	}
+	return RoleNone, fmt.Errorf("unknown role %v", name)
+}
+
+// Identity the authenticated identity of a request.
+type Identity struct {
+	Name   string // key name, JWT subject or certificate common name, empty for anonymous
+	Method string // "cert", "key" or "jwt", empty for anonymous
+	Role   Role
+
+	limiter *ratelimit.Limiter
+}
+
+// Authenticator authenticates requests with one kind of credential.
+type Authenticator interface {
+	// Authenticate returns nil identity if no credential of its kind presented,
+	// or error if the credential is invalid.
+	Authenticate(req *http.Request) (*Identity, error)
+}
+
+type identityKey struct{}
+
+// FromContext returns the identity of the request, or nil if auth is not enabled.
+func FromContext(ctx context.Context) *Identity {
+	id, _ := ctx.Value(identityKey{}).(*Identity)
+	return id
+}
+
+// Permitted returns whether the request of ctx has the role, which is always true if auth is not enabled.
+func Permitted(ctx context.Context, role Role) bool {
+	if id := FromContext(ctx); id != nil {
+		return id.Role >= role
+	}
+	return true
+}
+
+// route requires the role for requests to the path prefix, with the method if not empty.
+type route struct {
+	method string
+	path   string
+	role   Role
+}
+
+func (r *route) match(req *http.Request) bool {
+	if r.method != "" && !strings.EqualFold(r.method, req.Method) {
+		return false
+	}
+	return req.URL.Path == r.path || strings.HasPrefix(req.URL.Path, strings.TrimSuffix(r.path, "/")+"/")
+}
+
+// defaultRoutes routes not requiring the read role.
+// Note that eth_sendRawTransaction of /eth checks the submit role itself.
+var defaultRoutes = []*route{
+	{"", "/admin", RoleAdmin},
+	{"", "/debug", RoleAdmin},
+	{"POST", "/transactions", RoleSubmit},
+}
+
+// Auth authenticates and authorizes API requests.
+type Auth struct {
+	authenticators []Authenticator
+	anonymous      *Identity
+	routes         []*route
+}
+
+func (a *Auth) requiredRole(req *http.Request) Role {
+	for _, r := range a.routes {
+		if r.match(req) {
+			return r.role
+		}
+	}
+	return RoleRead
+}
+
+func (a *Auth) authenticate(req *http.Request) (*Identity, error) {
+	for _, authenticator := range a.authenticators {
+		id, err := authenticator.Authenticate(req)
+		if err != nil {
+			return nil, err
+		}
+		if id != nil {
+			return id, nil
+		}
+	}
+	return a.anonymous, nil
+}
+
+// Handler wraps the handler to authenticate and authorize requests.
+func (a *Auth) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id, err := a.authenticate(req)
+		if err != nil {
+			log.Debug("authentication failed", "remote", req.RemoteAddr, "err", err)
+			http.Error(w, errors.WithMessage(err, "unauthorized").Error(), http.StatusUnauthorized)
+			return
+		}
+		if required := a.requiredRole(req); id.Role < required {
+			if id.Method == "" {
+				http.Error(w, "unauthorized: credential required", http.StatusUnauthorized)
+			} else {
+				http.Error(w, fmt.Sprintf("forbidden: role %v required", required), http.StatusForbidden)
+			}
+			return
+		}
+		if !id.limiter.Allow(id.Method + ":" + id.Name) {
+			http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), identityKey{}, id)))
+	})
+}
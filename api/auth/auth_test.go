@@ -0,0 +1,242 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(a *Auth, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	a.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := FromContext(req.Context())
+		w.Write([]byte(id.Method + ":" + id.Name + ":" + id.Role.String()))
+	})).ServeHTTP(w, req)
+	return w
+}
+
+func newRequest(method, path string, header ...string) *http.Request {
+	req := httptest.NewRequest(method, path, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	return req
+}
+
+func TestRole(t *testing.T) {
+	for _, name := range []string{"none", "read", "submit", "admin"} {
+		role, err := ParseRole(name)
+		assert.Nil(t, err)
+		assert.Equal(t, name, role.String())
+	}
+	role, err := ParseRole("")
+	assert.Nil(t, err)
+	assert.Equal(t, RoleNone, role)
+	_, err = ParseRole("root")
+	assert.NotNil(t, err)
+	assert.True(t, Permitted(httptest.NewRequest("GET", "/", nil).Context(), RoleAdmin), "auth not enabled")
+}
+
+func TestKeyAuth(t *testing.T) {
+	a, err := New(&Config{
+		Anonymous: "read",
+		Keys: []*KeyConfig{
+			{Name: "reader", Key: "k1", Role: "read"},
+			{Name: "wallet", Key: "k2", Role: "submit", Quota: Quota{Rate: 1, Burst: 2}},
+		},
+		Routes: []*RouteConfig{{Method: "POST", Path: "/accounts", Role: "submit"}},
+	}, "")
+	assert.Nil(t, err)
+
+	tests := []struct {
+		req    *http.Request
+		status int
+		body   string
+	}{
+		{newRequest("GET", "/blocks/best"), 200, "::read"},
+		{newRequest("POST", "/transactions"), 401, ""},
+		{newRequest("POST", "/accounts/0x01"), 401, ""},
+		{newRequest("GET", "/accounts/0x01"), 200, ""},
+		{newRequest("GET", "/debug/tracers", KeyHeader, "k1"), 403, ""},
+		{newRequest("POST", "/transactions", KeyHeader, "k1"), 403, ""},
+		{newRequest("POST", "/transactionsfoo", KeyHeader, "k1"), 200, "key:reader:read"},
+		{newRequest("POST", "/transactions", KeyHeader, "bad"), 401, ""},
+		{newRequest("POST", "/transactions", KeyHeader, "k2"), 200, "key:wallet:submit"},
+		{newRequest("POST", "/accounts/0x01", KeyHeader, "k2"), 200, "key:wallet:submit"},
+		// quota of wallet exhausted
+		{newRequest("GET", "/blocks/best", KeyHeader, "k2"), 429, ""},
+		{newRequest("GET", "/blocks/best", KeyHeader, "k1"), 200, "key:reader:read"},
+	}
+	for i, test := range tests {
+		w := serve(a, test.req)
+		assert.Equal(t, test.status, w.Code, "#%v", i)
+		if test.body != "" {
+			assert.Equal(t, test.body, w.Body.String(), "#%v", i)
+		}
+	}
+
+	a, err = New(&Config{}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 401, serve(a, newRequest("GET", "/blocks/best")).Code, "credential required by default")
+
+	_, err = New(&Config{Keys: []*KeyConfig{{Key: "k"}, {Key: "k"}}}, "")
+	assert.NotNil(t, err, "duplicated key")
+	_, err = New(&Config{Routes: []*RouteConfig{{Path: "/", Role: "root"}}}, "")
+	assert.NotNil(t, err, "unknown role")
+}
+
+func encodeSegment(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signJWT(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	input := encodeSegment(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + encodeSegment(claims)
+	hash := sha256.Sum256([]byte(input))
+	var sig []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		s, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = s
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWTAuth(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	b64 := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "n": b64(rsaKey.N), "e": b64(big.NewInt(int64(rsaKey.E)))},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X), "y": b64(ecKey.Y)},
+		},
+	})
+	file, err := ioutil.TempFile("", "jwks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.Write(jwks)
+	file.Close()
+
+	a, err := New(&Config{
+		JWT: &JWTConfig{JWKS: file.Name(), Issuer: "iss", Audience: "api", Role: "read"},
+	}, "")
+	assert.Nil(t, err)
+
+	exp := time.Now().Add(time.Hour).Unix()
+	claims := func(kv ...interface{}) map[string]interface{} {
+		c := map[string]interface{}{"sub": "alice", "iss": "iss", "aud": []string{"api", "other"}, "exp": exp}
+		for i := 0; i+1 < len(kv); i += 2 {
+			c[kv[i].(string)] = kv[i+1]
+		}
+		return c
+	}
+	bearer := func(token string) *http.Request {
+		return newRequest("GET", "/debug/x", "Authorization", "Bearer "+token)
+	}
+
+	w := serve(a, newRequest("GET", "/blocks/best", "Authorization", "Bearer "+signJWT(t, "RS256", "rsa", rsaKey, claims())))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "jwt:alice:read", w.Body.String())
+
+	tests := []struct {
+		token  string
+		status int
+	}{
+		{signJWT(t, "ES256", "ec", ecKey, claims("role", "admin")), 200},
+		{signJWT(t, "RS256", "rsa", rsaKey, claims()), 403},
+		{signJWT(t, "RS256", "rsa", rsaKey, claims("role", "root")), 401},
+		{signJWT(t, "ES256", "rsa", ecKey, claims("role", "admin")), 401},
+		{signJWT(t, "RS256", "unknown", rsaKey, claims("role", "admin")), 401},
+		{signJWT(t, "ES256", "ec", ecKey, claims("role", "admin", "exp", time.Now().Add(-time.Hour).Unix())), 401},
+		{signJWT(t, "ES256", "ec", ecKey, claims("role", "admin", "nbf", time.Now().Add(time.Hour).Unix())), 401},
+		{signJWT(t, "ES256", "ec", ecKey, claims("role", "admin", "iss", "other")), 401},
+		{signJWT(t, "ES256", "ec", ecKey, claims("role", "admin", "aud", "other")), 401},
+		{signJWT(t, "ES256", "ec", ecKey, claims("role", "admin", "sub", "")), 401},
+		{signJWT(t, "ES256", "ec", ecKey, claims("role", "admin"))[:20], 401},
+	}
+	for i, test := range tests {
+		assert.Equal(t, test.status, serve(a, bearer(test.token)).Code, "#%v", i)
+	}
+	// tampered
+	token := signJWT(t, "ES256", "ec", ecKey, claims())
+	tampered := token[:len(token)-2] + "AA"
+	if tampered == token {
+		tampered = token[:len(token)-2] + "BB"
+	}
+	assert.Equal(t, 401, serve(a, bearer(tampered)).Code)
+	assert.Equal(t, 401, serve(a, newRequest("GET", "/", "Authorization", "Basic xxx")).Code)
+}
+
+func TestCertAuth(t *testing.T) {
+	a, err := New(&Config{
+		Certs: []*CertConfig{{CommonName: "ops", Role: "admin"}, {CommonName: "*", Role: "read"}},
+		Keys:  []*KeyConfig{{Name: "k", Key: "k", Role: "submit"}},
+	}, "")
+	assert.Nil(t, err)
+	// by-pass the CA
+	a.authenticators[0].(*certAuthenticator).verify = func(cert *x509.Certificate) error {
+		if cert.Subject.Organization[0] != "ca" {
+			return errors.New("unknown authority")
+		}
+		return nil
+	}
+
+	withCert := func(cn, org string) *http.Request {
+		req := newRequest("GET", "/debug/x", KeyHeader, "k")
+		req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{
+			{Subject: pkix.Name{CommonName: cn, Organization: []string{org}}},
+		}}
+		return req
+	}
+	w := serve(a, withCert("ops", "ca"))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "cert:ops:admin", w.Body.String())
+
+	w = serve(a, withCert("bob", "ca"))
+	assert.Equal(t, 403, w.Code, "cert takes precedence over key")
+
+	assert.Equal(t, 401, serve(a, withCert("ops", "evil")).Code)
+	assert.Equal(t, 403, serve(a, newRequest("GET", "/debug/x", KeyHeader, "k")).Code)
+
+	// subject of the certificate verified by the authenticator, rather than TLS
+	var subject string
+	a.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		subject = ClientSubject(req)
+	})).ServeHTTP(httptest.NewRecorder(), withCert("ops", "ca"))
+	assert.Equal(t, "CN=ops,O=ca", subject)
+	assert.Equal(t, "", ClientSubject(withCert("ops", "ca")), "not verified")
+}
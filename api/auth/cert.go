@@ -0,0 +1,86 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package auth
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+
+	"github.com/HiNounou029/nounouchain/caclient"
+	"github.com/pkg/errors"
+)
+
+// anyCommonName the common name in config matching any certificate.
+const anyCommonName = "*"
+
+// certAuthenticator authenticates requests by TLS client certificates issued by the CA.
+type certAuthenticator struct {
+	certs  map[string]*Identity // by common name
+	verify func(cert *x509.Certificate) error
+}
+
+func newCertAuthenticator(configs []*CertConfig, rootCaPath string) (*certAuthenticator, error) {
+	ca := &certAuthenticator{
+		certs: make(map[string]*Identity),
+		verify: func(cert *x509.Certificate) error {
+			return caclient.ValCert(rootCaPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+		},
+	}
+	for i, cc := range configs {
+		if cc.CommonName == "" {
+			return nil, fmt.Errorf("certs[%v]: commonName required", i)
+		}
+		role, err := ParseRole(cc.Role)
+		if err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("certs[%v]", i))
+		}
+		if _, ok := ca.certs[cc.CommonName]; ok {
+			return nil, fmt.Errorf("certs[%v]: duplicated commonName", i)
+		}
+		ca.certs[cc.CommonName] = &Identity{
+			Name:    cc.CommonName,
+			Method:  "cert",
+			Role:    role,
+			limiter: cc.limiter(),
+		}
+	}
+	return ca, nil
+}
+
+// ClientSubject returns subject of the client certificate, verified either by TLS or by the
+// certificate authenticator, or empty string if no verified certificate.
+func ClientSubject(req *http.Request) string {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return ""
+	}
+	if len(req.TLS.VerifiedChains) == 0 {
+		// the TLS server requested but not verified the certificate
+		if id := FromContext(req.Context()); id == nil || id.Method != "cert" {
+			return ""
+		}
+	}
+	return req.TLS.PeerCertificates[0].Subject.String()
+}
+
+func (ca *certAuthenticator) Authenticate(req *http.Request) (*Identity, error) {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return nil, nil
+	}
+	cert := req.TLS.PeerCertificates[0]
+	// already verified against the same CA if the server verifies client certs
+	if len(req.TLS.VerifiedChains) == 0 {
+		if err := ca.verify(cert); err != nil {
+			return nil, errors.WithMessage(err, "client certificate")
+		}
+	}
+	cn := cert.Subject.CommonName
+	if id, ok := ca.certs[cn]; ok {
+		return id, nil
+	}
+	if id, ok := ca.certs[anyCommonName]; ok {
+		return &Identity{Name: cn, Method: id.Method, Role: id.Role, limiter: id.limiter}, nil
+	}
+	return nil, fmt.Errorf("client certificate %v not authorized", cn)
+}
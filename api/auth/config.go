@@ -0,0 +1,146 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/HiNounou029/nounouchain/common/ratelimit"
+	"github.com/pkg/errors"
+)
+
+// Config the auth config, e.g.
+//
+//	{
+//		"anonymous": "read",
+//		"keys": [{"name": "wallet", "key": "secret", "role": "submit", "rate": 10, "burst": 100}],
+//		"jwt": {"jwks": "jwks.json", "issuer": "https://id.example.com", "role": "read", "rate": 5},
+//		"certs": [{"commonName": "ops", "role": "admin"}],
+//		"routes": [{"method": "POST", "path": "/accounts", "role": "submit"}]
+//	}
+type Config struct {
+	// role of requests without credential, none by default, which means credential required
+	Anonymous string        `json:"anonymous"`
+	Keys      []*KeyConfig  `json:"keys"`
+	JWT       *JWTConfig    `json:"jwt"`
+	Certs     []*CertConfig `json:"certs"`
+	// routes requiring roles other than read, matched in order before the default ones
+	Routes []*RouteConfig `json:"routes"`
+}
+
+// Quota limits requests per second of an identity, 0 rate for unlimited.
+// Burst defaults to rate.
+type Quota struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+func (q *Quota) limiter() *ratelimit.Limiter {
+	burst := q.Burst
+	if burst <= 0 {
+		burst = int(q.Rate)
+	}
+	return ratelimit.New(q.Rate, burst)
+}
+
+// KeyConfig a static API key.
+type KeyConfig struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+	Role string `json:"role"`
+	Quota
+}
+
+// JWTConfig verification of JWTs, signed with RS256 or ES256 by keys in the local JWKS file.
+type JWTConfig struct {
+	JWKS     string `json:"jwks"`
+	Issuer   string `json:"issuer"`   // required iss claim if not empty
+	Audience string `json:"audience"` // required aud claim if not empty
+	// claim of the role, "role" by default, falls back to Role if the claim absent
+	RoleClaim string `json:"roleClaim"`
+	Role      string `json:"role"`
+	// quota of each subject
+	Quota
+}
+
+// CertConfig role of client certificates issued by the CA, with the common name,
+// or any common name if it's "*".
+type CertConfig struct {
+	CommonName string `json:"commonName"`
+	Role       string `json:"role"`
+	// quota of each common name
+	Quota
+}
+
+// RouteConfig role required by requests to the path prefix, with the method if not empty.
+type RouteConfig struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Role   string `json:"role"`
+}
+
+// Load loads config from the JSON file and creates Auth.
+// Client certificates are verified against the root CA at rootCaPath.
+func Load(path string, rootCaPath string) (*Auth, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	var config Config
+	if err := decoder.Decode(&config); err != nil {
+		return nil, errors.WithMessage(err, "decode auth config")
+	}
+	return New(&config, rootCaPath)
+}
+
+// New creates Auth by config. Extra authenticators are tried after the built-in ones.
+func New(config *Config, rootCaPath string, extra ...Authenticator) (*Auth, error) {
+	anonymous, err := ParseRole(config.Anonymous)
+	if err != nil {
+		return nil, errors.WithMessage(err, "anonymous")
+	}
+	a := &Auth{
+		anonymous: &Identity{Role: anonymous},
+	}
+	for i, rc := range config.Routes {
+		role, err := ParseRole(rc.Role)
+		if err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("routes[%v]", i))
+		}
+		if rc.Path == "" {
+			return nil, fmt.Errorf("routes[%v]: path required", i)
+		}
+		a.routes = append(a.routes, &route{rc.Method, rc.Path, role})
+	}
+	a.routes = append(a.routes, defaultRoutes...)
+
+	if len(config.Certs) > 0 {
+		ca, err := newCertAuthenticator(config.Certs, rootCaPath)
+		if err != nil {
+			return nil, err
+		}
+		a.authenticators = append(a.authenticators, ca)
+	}
+	if len(config.Keys) > 0 {
+		ka, err := newKeyAuthenticator(config.Keys)
+		if err != nil {
+			return nil, err
+		}
+		a.authenticators = append(a.authenticators, ka)
+	}
+	if config.JWT != nil {
+		ja, err := newJWTAuthenticator(config.JWT)
+		if err != nil {
+			return nil, err
+		}
+		a.authenticators = append(a.authenticators, ja)
+	}
+	a.authenticators = append(a.authenticators, extra...)
+	return a, nil
+}
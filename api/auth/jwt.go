@@ -0,0 +1,249 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/HiNounou029/nounouchain/common/ratelimit"
+	"github.com/pkg/errors"
+)
+
+// clock skew tolerated when checking exp and nbf
+const jwtLeeway = 30 * time.Second
+
+// jwk a key of JWKS, only RSA and P-256 EC keys are supported.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, errors.WithMessage(err, "n")
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, errors.WithMessage(err, "e")
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("e: too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %v", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, errors.WithMessage(err, "x")
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, errors.WithMessage(err, "y")
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.New("point not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %v", k.Kty)
+	}
+}
+
+// jwtAuthenticator authenticates requests by JWTs in the Authorization header.
+type jwtAuthenticator struct {
+	keys      map[string]crypto.PublicKey // by kid
+	issuer    string
+	audience  string
+	roleClaim string
+	role      Role
+	limiter   *ratelimit.Limiter
+	now       func() time.Time
+}
+
+func newJWTAuthenticator(config *JWTConfig) (*jwtAuthenticator, error) {
+	role, err := ParseRole(config.Role)
+	if err != nil {
+		return nil, errors.WithMessage(err, "jwt")
+	}
+	data, err := ioutil.ReadFile(config.JWKS)
+	if err != nil {
+		return nil, errors.WithMessage(err, "jwt: read jwks")
+	}
+	var jwks struct {
+		Keys []*jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, errors.WithMessage(err, "jwt: decode jwks")
+	}
+	if len(jwks.Keys) == 0 {
+		return nil, errors.New("jwt: no keys in jwks")
+	}
+	ja := &jwtAuthenticator{
+		keys:      make(map[string]crypto.PublicKey),
+		issuer:    config.Issuer,
+		audience:  config.Audience,
+		roleClaim: config.RoleClaim,
+		role:      role,
+		limiter:   config.limiter(),
+		now:       time.Now,
+	}
+	if ja.roleClaim == "" {
+		ja.roleClaim = "role"
+	}
+	for i, k := range jwks.Keys {
+		pub, err := k.publicKey()
+		if err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("jwt: jwks keys[%v]", i))
+		}
+		ja.keys[k.Kid] = pub
+	}
+	return ja, nil
+}
+
+func (ja *jwtAuthenticator) Authenticate(req *http.Request) (*Identity, error) {
+	authorization := req.Header.Get("Authorization")
+	if authorization == "" {
+		return nil, nil
+	}
+	const prefix = "Bearer "
+	if len(authorization) < len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return nil, errors.New("unsupported authorization scheme")
+	}
+	claims, err := ja.verify(strings.TrimSpace(authorization[len(prefix):]))
+	if err != nil {
+		return nil, errors.WithMessage(err, "jwt")
+	}
+
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return nil, errors.New("jwt: sub claim required")
+	}
+	role := ja.role
+	if v, ok := claims[ja.roleClaim]; ok {
+		name, _ := v.(string)
+		if role, err = ParseRole(name); err != nil {
+			return nil, errors.WithMessage(err, "jwt: "+ja.roleClaim+" claim")
+		}
+	}
+	return &Identity{Name: sub, Method: "jwt", Role: role, limiter: ja.limiter}, nil
+}
+
+// verify verifies signature and registered claims of the token, and returns claims.
+func (ja *jwtAuthenticator) verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, errors.WithMessage(err, "header")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.WithMessage(err, "signature")
+	}
+	key, ok := ja.keys[header.Kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %v", header.Kid)
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	switch header.Alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("alg mismatches key type")
+		}
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], sig); err != nil {
+			return nil, errors.New("invalid signature")
+		}
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, errors.New("alg mismatches key type")
+		}
+		if len(sig) != 64 {
+			return nil, errors.New("invalid signature")
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(pub, hash[:], r, s) {
+			return nil, errors.New("invalid signature")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported alg %v", header.Alg)
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, errors.WithMessage(err, "claims")
+	}
+	now := ja.now()
+	if exp, ok := claims["exp"].(float64); !ok || now.After(time.Unix(int64(exp), 0).Add(jwtLeeway)) {
+		return nil, errors.New("token expired or exp claim absent")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(jwtLeeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, errors.New("token not valid yet")
+	}
+	if ja.issuer != "" && claims["iss"] != ja.issuer {
+		return nil, errors.New("issuer mismatch")
+	}
+	if ja.audience != "" && !hasAudience(claims["aud"], ja.audience) {
+		return nil, errors.New("audience mismatch")
+	}
+	return claims, nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// hasAudience checks the aud claim, which is a string or an array of strings.
+func hasAudience(aud interface{}, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package auth
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// KeyHeader the header carrying the API key.
+const KeyHeader = "X-API-Key"
+
+// keyAuthenticator authenticates requests by static API keys.
+type keyAuthenticator struct {
+	// keyed by hash of the key, so that lookup time leaks nothing of keys
+	keys map[[32]byte]*Identity
+}
+
+func newKeyAuthenticator(configs []*KeyConfig) (*keyAuthenticator, error) {
+	ka := &keyAuthenticator{keys: make(map[[32]byte]*Identity)}
+	for i, kc := range configs {
+		if kc.Key == "" {
+			return nil, fmt.Errorf("keys[%v]: key required", i)
+		}
+		role, err := ParseRole(kc.Role)
+		if err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("keys[%v]", i))
+		}
+		hash := sha256.Sum256([]byte(kc.Key))
+		if _, ok := ka.keys[hash]; ok {
+			return nil, fmt.Errorf("keys[%v]: duplicated key", i)
+		}
+		name := kc.Name
+		if name == "" {
+			name = fmt.Sprintf("#%v", i)
+		}
+		ka.keys[hash] = &Identity{
+			Name:    name,
+			Method:  "key",
+			Role:    role,
+			limiter: kc.limiter(),
+		}
+	}
+	return ka, nil
+}
+
+func (ka *keyAuthenticator) Authenticate(req *http.Request) (*Identity, error) {
+	key := req.Header.Get(KeyHeader)
+	if key == "" {
+		return nil, nil
+	}
+	if id, ok := ka.keys[sha256.Sum256([]byte(key))]; ok {
+		return id, nil
+	}
+	return nil, errors.New("invalid API key")
+}
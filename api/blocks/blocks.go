@@ -15,6 +15,9 @@ import (
 	"github.com/pkg/errors"
 )
 
+// timeRevision revision given by unix timestamp
+type timeRevision uint64
+
 type Blocks struct {
 	chain *chain.Chain
 }
@@ -60,6 +63,9 @@ func (b *Blocks) parseRevision(revision string) (interface{}, error) {
 		}
 		return blockID, nil
 	}
+	if timestamp, ok, err := utils.ParseTimeRevision(revision); ok {
+		return timeRevision(timestamp), err
+	}
 	n, err := strconv.ParseUint(revision, 0, 0)
 	if err != nil {
 		return nil, err
@@ -76,6 +82,12 @@ func (b *Blocks) getBlock(revision interface{}) (*block.Block, error) {
 		return b.chain.GetBlock(revision.(polo.Bytes32))
 	case uint32:
 		return b.chain.GetTrunkBlock(revision.(uint32))
+	case timeRevision:
+		header, err := b.chain.GetTrunkBlockHeaderByTime(uint64(revision.(timeRevision)))
+		if err != nil {
+			return nil, err
+		}
+		return b.chain.GetBlock(header.ID())
 	default:
 		return b.chain.BestBlock(), nil
 	}
@@ -8,6 +8,8 @@ import (
 	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -68,6 +70,24 @@ func TestBlock(t *testing.T) {
 	checkBlock(t, blk, rb)
 	assert.Equal(t, http.StatusOK, statusCode)
 
+	res, statusCode = httpGet(t, ts.URL+"/blocks/t:"+strconv.FormatUint(blk.Header().Timestamp(), 10))
+	if err := json.Unmarshal(res, &rb); err != nil {
+		t.Fatal(err)
+	}
+	checkBlock(t, blk, rb)
+	assert.Equal(t, http.StatusOK, statusCode)
+
+	res, statusCode = httpGet(t, ts.URL+"/blocks/t:"+time.Unix(int64(blk.Header().Timestamp())-1, 0).UTC().Format(time.RFC3339))
+	if err := json.Unmarshal(res, &rb); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint32(0), rb.Number, "genesis block")
+
+	res, statusCode = httpGet(t, ts.URL+"/blocks/t:0")
+	assert.Equal(t, "null", strings.TrimSpace(string(res)), "before genesis")
+	res, statusCode = httpGet(t, ts.URL+"/blocks/t:yesterday")
+	assert.Equal(t, http.StatusBadRequest, statusCode)
+
 }
 
 func initBlockServer(t *testing.T) {
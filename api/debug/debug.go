@@ -0,0 +1,322 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HiNounou029/nounouchain/api/utils"
+	"github.com/HiNounou029/nounouchain/common/xenv"
+	"github.com/HiNounou029/nounouchain/consensus"
+	"github.com/HiNounou029/nounouchain/core/block"
+	"github.com/HiNounou029/nounouchain/core/chain"
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/HiNounou029/nounouchain/vm"
+	"github.com/HiNounou029/nounouchain/vm/runtime"
+	"github.com/HiNounou029/nounouchain/vm/tracers"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// names of tracers
+const (
+	structLoggerName = "structLogger"
+	callTracerName   = "call"
+	prestateName     = "prestate"
+)
+
+const (
+	// timeout of a trace, including replaying preceding txs in the block
+	traceTimeout = 10 * time.Second
+	// default and max count of struct logs, to bound memory used by a trace
+	defaultStructLogLimit = 10000
+	maxStructLogLimit     = 100000
+)
+
+type Debug struct {
+	chain        *chain.Chain
+	stateCreator *state.Creator
+	consensus    *consensus.Consensus
+	callGasLimit uint64
+}
+
+func New(chain *chain.Chain, stateCreator *state.Creator, callGasLimit uint64) *Debug {
+	return &Debug{
+		chain,
+		stateCreator,
+		consensus.New(chain, stateCreator),
+		callGasLimit,
+	}
+}
+
+func newTracer(name string, config json.RawMessage) (vm.Tracer, error) {
+	switch name {
+	case "", structLoggerName:
+		var cfg vm.LogConfig
+		if len(config) > 0 {
+			if err := json.Unmarshal(config, &cfg); err != nil {
+				return nil, errors.WithMessage(err, "config")
+			}
+		}
+		if cfg.Limit <= 0 {
+			cfg.Limit = defaultStructLogLimit
+		} else if cfg.Limit > maxStructLogLimit {
+			return nil, fmt.Errorf("config.limit: exceeds %v", maxStructLogLimit)
+		}
+		return vm.NewStructLogger(&cfg), nil
+	case callTracerName:
+		return tracers.NewCallTracer(), nil
+	case prestateName:
+		return tracers.NewPrestateTracer(), nil
+	}
+	return nil, fmt.Errorf("name: unsupported tracer %v", name)
+}
+
+// traceResult collects result from the tracer.
+// The state should be reverted to the point before execution, for prestate tracer.
+func traceResult(tracer vm.Tracer, state *state.State, gasUsed uint64, output *runtime.Output) (interface{}, error) {
+	switch t := tracer.(type) {
+	case *vm.StructLogger:
+		result := &StructLogResult{
+			Gas:        gasUsed,
+			StructLogs: t.StructLogs(),
+		}
+		if output != nil {
+			result.Failed = output.VMErr != nil
+			result.ReturnValue = hexutil.Encode(output.Data)
+		}
+		return result, nil
+	case *tracers.CallTracer:
+		return t.Result(), nil
+	case *tracers.PrestateTracer:
+		return t.Result(state)
+	}
+	return nil, errors.New("unexpected tracer")
+}
+
+func (d *Debug) handleTraceTransaction(w http.ResponseWriter, req *http.Request) error {
+	var data TraceTxData
+	if err := utils.ParseJSON(req.Body, &data); err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "body"))
+	}
+	tracer, err := newTracer(data.Name, data.Config)
+	if err != nil {
+		return utils.BadRequest(err)
+	}
+	blk, txIndex, err := d.parseTarget(data.Target)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), traceTimeout)
+	defer cancel()
+	result, err := d.traceTransaction(ctx, tracer, blk, txIndex)
+	if err != nil {
+		return traceError(ctx, err)
+	}
+	return utils.WriteJSON(w, result)
+}
+
+// traceError reports the trace interrupted by timeout or cancellation as service unavailable.
+func traceError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return utils.HTTPError(errors.WithMessage(ctx.Err(), "trace"), http.StatusServiceUnavailable)
+	}
+	return err
+}
+
+// traceTransaction replays txs before the target in the block, then traces the target.
+func (d *Debug) traceTransaction(ctx context.Context, tracer vm.Tracer, blk *block.Block, txIndex uint64) (interface{}, error) {
+	rt, err := d.consensus.NewRuntimeForReplay(blk.Header())
+	if err != nil {
+		return nil, err
+	}
+	txs := blk.Transactions()
+	for _, tx := range txs[:txIndex] {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if _, err := rt.ExecuteTransaction(tx); err != nil {
+			return nil, err
+		}
+	}
+
+	target := txs[txIndex]
+	checkpoint := rt.State().NewCheckpoint()
+	rt.SetVMConfig(vm.Config{Debug: true, Tracer: tracer})
+	executor, err := rt.PrepareTransaction(target)
+	if err != nil {
+		return nil, err
+	}
+	var lastOutput *runtime.Output
+	for executor.HasNextClause() {
+		_, output, err := executor.NextClause()
+		if err != nil {
+			return nil, err
+		}
+		lastOutput = output
+	}
+	receipt, err := executor.Finalize()
+	if err != nil {
+		return nil, err
+	}
+
+	if prestate, ok := tracer.(*tracers.PrestateTracer); ok {
+		origin, _ := target.Signer()
+		prestate.Touch(origin)
+		prestate.Touch(receipt.GasPayer)
+		prestate.Touch(blk.Header().Beneficiary())
+	}
+	rt.State().RevertTo(checkpoint)
+	return traceResult(tracer, rt.State(), receipt.GasUsed, lastOutput)
+}
+
+func (d *Debug) handleTraceCall(w http.ResponseWriter, req *http.Request) error {
+	var data TraceCallData
+	if err := utils.ParseJSON(req.Body, &data); err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "body"))
+	}
+	tracer, err := newTracer(data.Name, data.Config)
+	if err != nil {
+		return utils.BadRequest(err)
+	}
+	header, err := utils.ParseRevision(d.chain, req.URL.Query().Get("revision"))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), traceTimeout)
+	defer cancel()
+	result, err := d.traceCall(ctx, tracer, header, &data)
+	if err != nil {
+		return traceError(ctx, err)
+	}
+	return utils.WriteJSON(w, result)
+}
+
+// traceCall traces a call on the state of the given block, like contract calls of /accounts.
+func (d *Debug) traceCall(ctx context.Context, tracer vm.Tracer, header *block.Header, data *TraceCallData) (interface{}, error) {
+	gas := data.Gas
+	if gas > d.callGasLimit {
+		return nil, utils.Forbidden(errors.New("gas: exceeds limit"))
+	} else if gas == 0 {
+		gas = d.callGasLimit
+	}
+	gasPrice := new(big.Int)
+	if data.GasPrice != nil {
+		gasPrice = (*big.Int)(data.GasPrice)
+	}
+	caller := polo.Address{}
+	if data.Caller != nil {
+		caller = *data.Caller
+	}
+	value := new(big.Int)
+	if data.Value != nil {
+		value = (*big.Int)(data.Value)
+	}
+	var input []byte
+	if data.Data != "" {
+		var err error
+		if input, err = hexutil.Decode(data.Data); err != nil {
+			return nil, utils.BadRequest(errors.WithMessage(err, "data"))
+		}
+	}
+
+	state, err := d.stateCreator.NewState(header.StateRoot())
+	if err != nil {
+		return nil, err
+	}
+	signer, _ := header.Signer()
+	rt := runtime.New(d.chain.NewSeeker(header.ParentID()), state,
+		&xenv.BlockContext{
+			Beneficiary: header.Beneficiary(),
+			Signer:      signer,
+			Number:      header.Number(),
+			Time:        header.Timestamp(),
+			GasLimit:    header.GasLimit(),
+			TotalScore:  header.TotalScore()})
+	rt.SetVMConfig(vm.Config{Debug: true, Tracer: tracer})
+
+	checkpoint := state.NewCheckpoint()
+	clause := tx.NewClause(data.To).WithData(input).WithValue(value)
+	exec, interrupt := rt.PrepareClause(clause, 0, gas, &xenv.TransactionContext{
+		Origin:   caller,
+		GasPrice: gasPrice})
+
+	vmout := make(chan *runtime.Output, 1)
+	go func() {
+		out, _ := exec()
+		vmout <- out
+	}()
+	select {
+	case <-ctx.Done():
+		interrupt()
+		return nil, ctx.Err()
+	case out := <-vmout:
+		if err := rt.Seeker().Err(); err != nil {
+			return nil, err
+		}
+		if err := state.Err(); err != nil {
+			return nil, err
+		}
+		state.RevertTo(checkpoint)
+		return traceResult(tracer, state, gas-out.LeftOverGas, out)
+	}
+}
+
+// parseTarget parses target in form of tx id or blockID/txIndex.
+func (d *Debug) parseTarget(target string) (*block.Block, uint64, error) {
+	var (
+		blockID polo.Bytes32
+		txIndex uint64
+		err     error
+	)
+	if parts := strings.Split(target, "/"); len(parts) == 2 {
+		if blockID, err = polo.ParseBytes32(parts[0]); err != nil {
+			return nil, 0, utils.BadRequest(errors.WithMessage(err, "target"))
+		}
+		if txIndex, err = strconv.ParseUint(parts[1], 0, 0); err != nil {
+			return nil, 0, utils.BadRequest(errors.WithMessage(err, "target"))
+		}
+	} else {
+		txID, err := polo.ParseBytes32(target)
+		if err != nil {
+			return nil, 0, utils.BadRequest(errors.WithMessage(err, "target"))
+		}
+		meta, err := d.chain.GetTransactionMeta(txID, d.chain.BestBlock().Header().ID())
+		if err != nil {
+			if d.chain.IsNotFound(err) {
+				return nil, 0, utils.BadRequest(errors.WithMessage(err, "target"))
+			}
+			return nil, 0, err
+		}
+		blockID, txIndex = meta.BlockID, meta.Index
+	}
+
+	blk, err := d.chain.GetBlock(blockID)
+	if err != nil {
+		if d.chain.IsNotFound(err) {
+			return nil, 0, utils.BadRequest(errors.WithMessage(err, "target"))
+		}
+		return nil, 0, err
+	}
+	if txIndex >= uint64(len(blk.Transactions())) {
+		return nil, 0, utils.BadRequest(errors.New("target: tx index out of range"))
+	}
+	return blk, txIndex, nil
+}
+
+func (d *Debug) Mount(root *mux.Router, pathPrefix string) {
+	sub := root.PathPrefix(pathPrefix).Subrouter()
+
+	sub.Path("/tracers").Methods("POST").HandlerFunc(utils.WrapHandlerFunc(d.handleTraceTransaction))
+	sub.Path("/tracers/call").Methods("POST").HandlerFunc(utils.WrapHandlerFunc(d.handleTraceCall))
+}
@@ -0,0 +1,219 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package debug_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/HiNounou029/nounouchain/api/debug"
+	"github.com/HiNounou029/nounouchain/core/chain"
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/crypto"
+	"github.com/HiNounou029/nounouchain/miner"
+	"github.com/HiNounou029/nounouchain/nounou/genesis"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/HiNounou029/nounouchain/storage"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// init code which stores 1 at slot 0, and deploys nothing
+var initCode = []byte{0x60, 0x01, 0x60, 0x00, 0x55, 0x00}
+
+var (
+	c          *chain.Chain
+	router     *mux.Router
+	ts         *httptest.Server
+	transfer   *tx.Transaction
+	deployment *tx.Transaction
+)
+
+type structLogResult struct {
+	Gas        uint64                   `json:"gas"`
+	Failed     bool                     `json:"failed"`
+	StructLogs []map[string]interface{} `json:"structLogs"`
+}
+
+func TestDebug(t *testing.T) {
+	initDebugServer(t)
+	defer ts.Close()
+
+	traceTransaction(t)
+	traceCall(t)
+	traceTimeout(t)
+}
+
+func traceTransaction(t *testing.T) {
+	var result structLogResult
+	res, status := httpPost(t, ts.URL+"/debug/tracers", map[string]interface{}{
+		"target": deployment.ID().String(),
+	})
+	assert.Equal(t, http.StatusOK, status, string(res))
+	assert.Nil(t, json.Unmarshal(res, &result))
+	assert.False(t, result.Failed)
+	assert.Equal(t, 4, len(result.StructLogs), "PUSH1, PUSH1, SSTORE, STOP")
+
+	blockID := c.BestBlock().Header().ID().String()
+	res, status = httpPost(t, ts.URL+"/debug/tracers", map[string]interface{}{
+		"target": blockID + "/1",
+		"config": map[string]interface{}{"limit": 2},
+	})
+	assert.Equal(t, http.StatusOK, status, string(res))
+	assert.Nil(t, json.Unmarshal(res, &result))
+	assert.Equal(t, 2, len(result.StructLogs), "limited")
+
+	var frames []*struct {
+		Type string `json:"type"`
+	}
+	res, status = httpPost(t, ts.URL+"/debug/tracers", map[string]interface{}{
+		"name":   "call",
+		"target": deployment.ID().String(),
+	})
+	assert.Equal(t, http.StatusOK, status, string(res))
+	assert.Nil(t, json.Unmarshal(res, &frames))
+	if assert.Equal(t, 1, len(frames)) {
+		assert.Equal(t, "CREATE", frames[0].Type)
+	}
+
+	var prestate map[string]interface{}
+	res, status = httpPost(t, ts.URL+"/debug/tracers", map[string]interface{}{
+		"name":   "prestate",
+		"target": transfer.ID().String(),
+	})
+	assert.Equal(t, http.StatusOK, status, string(res))
+	assert.Nil(t, json.Unmarshal(res, &prestate))
+	assert.Contains(t, prestate, genesis.DevAccounts()[0].Address.String())
+
+	bad := []map[string]interface{}{
+		{"name": "unknown", "target": transfer.ID().String()},
+		{"target": transfer.ID().String(), "config": map[string]interface{}{"limit": 1000000}},
+		{"target": "0x01"},
+		{"target": polo.Bytes32{}.String()},
+		{"target": blockID + "/2"},
+		{"target": blockID + "/x"},
+	}
+	for _, body := range bad {
+		res, status = httpPost(t, ts.URL+"/debug/tracers", body)
+		assert.Equal(t, http.StatusBadRequest, status, string(res))
+	}
+}
+
+func traceCall(t *testing.T) {
+	var result structLogResult
+	res, status := httpPost(t, ts.URL+"/debug/tracers/call?revision=best", map[string]interface{}{
+		"data": "0x600160005500",
+	})
+	assert.Equal(t, http.StatusOK, status, string(res))
+	assert.Nil(t, json.Unmarshal(res, &result))
+	assert.Equal(t, 4, len(result.StructLogs))
+
+	res, status = httpPost(t, ts.URL+"/debug/tracers/call", map[string]interface{}{
+		"gas": 1 << 40,
+	})
+	assert.Equal(t, http.StatusForbidden, status, string(res))
+
+	res, status = httpPost(t, ts.URL+"/debug/tracers/call?revision=100", map[string]interface{}{})
+	assert.Equal(t, http.StatusBadRequest, status, string(res))
+
+	res, status = httpPost(t, ts.URL+"/debug/tracers/call?revision=t:"+strconv.FormatUint(c.BestBlock().Header().Timestamp(), 10), map[string]interface{}{
+		"data": "0x600160005500",
+	})
+	assert.Equal(t, http.StatusOK, status, string(res))
+
+	res, status = httpPost(t, ts.URL+"/debug/tracers/call?revision=t:0", map[string]interface{}{})
+	assert.Equal(t, http.StatusBadRequest, status, string(res))
+}
+
+func traceTimeout(t *testing.T) {
+	data, _ := json.Marshal(map[string]interface{}{
+		"target": deployment.ID().String(),
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest("POST", "/debug/tracers", bytes.NewReader(data)).WithContext(ctx)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, w.Body.String())
+}
+
+func initDebugServer(t *testing.T) {
+	db, _ := storage.NewMem()
+	stateC := state.NewCreator(db)
+	b, _, err := genesis.NewDevnet().Build(stateC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ = chain.New(db, b)
+
+	to := polo.BytesToAddress([]byte("to"))
+	transfer = newTx(t, 1, tx.NewClause(&to).WithValue(big.NewInt(10000)))
+	deployment = newTx(t, 2, tx.NewClause(nil).WithData(initCode))
+
+	miner := miner.New(c, stateC, genesis.DevAccounts()[0].Address, &genesis.DevAccounts()[0].Address)
+	flow, err := miner.Schedule(b.Header(), uint64(time.Now().Unix()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, trx := range []*tx.Transaction{transfer, deployment} {
+		if err := flow.Adopt(trx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blk, stage, receipts, err := flow.Pack(genesis.DevAccounts()[0].PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stage.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.AddBlock(blk, receipts); err != nil {
+		t.Fatal(err)
+	}
+
+	router = mux.NewRouter()
+	debug.New(c, stateC, 10000000).Mount(router, "/debug")
+	ts = httptest.NewServer(router)
+}
+
+func newTx(t *testing.T, nonce uint64, clause *tx.Clause) *tx.Transaction {
+	trx := new(tx.Builder).
+		ChainTag(c.Tag()).
+		Expiration(10).
+		Gas(1000000).
+		Nonce(nonce).
+		Clause(clause).
+		BlockRef(tx.NewBlockRef(0)).
+		Build()
+	sig, err := crypto.Sign(trx.SigningHash().Bytes(), genesis.DevAccounts()[0].PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return trx.WithSignature(sig)
+}
+
+func httpPost(t *testing.T, url string, body interface{}) ([]byte, int) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, res.StatusCode
+}
@@ -0,0 +1,39 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package debug
+
+import (
+	"encoding/json"
+
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/vm"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// TraceTxData is the body of tx tracing request.
+type TraceTxData struct {
+	Name   string          `json:"name"`
+	Config json.RawMessage `json:"config"`
+	// Target is the tx id, or blockID/txIndex.
+	Target string `json:"target"`
+}
+
+// TraceCallData is the body of call tracing request.
+type TraceCallData struct {
+	Name     string                `json:"name"`
+	Config   json.RawMessage       `json:"config"`
+	To       *polo.Address         `json:"to"`
+	Value    *math.HexOrDecimal256 `json:"value"`
+	Data     string                `json:"data"`
+	Gas      uint64                `json:"gas"`
+	GasPrice *math.HexOrDecimal256 `json:"gasPrice"`
+	Caller   *polo.Address         `json:"caller"`
+}
+
+// StructLogResult is the result of struct logger.
+type StructLogResult struct {
+	Gas         uint64         `json:"gas"`
+	Failed      bool           `json:"failed"`
+	ReturnValue string         `json:"returnValue"`
+	StructLogs  []vm.StructLog `json:"structLogs"`
+}
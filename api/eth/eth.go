@@ -0,0 +1,302 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+// Package eth serves the ethereum compatible JSON-RPC, over HTTP and websocket,
+// for tools that speak eth_* only.
+//
+// Supported methods:
+//
+//	web3_clientVersion, web3_sha3, net_version, net_listening,
+//	eth_chainId, eth_blockNumber, eth_gasPrice,
+//	eth_getBalance, eth_getCode, eth_getStorageAt, eth_call,
+//	eth_getBlockByNumber, eth_getBlockByHash,
+//	eth_getTransactionByHash, eth_getTransactionReceipt,
+//	eth_getLogs, eth_sendRawTransaction
+//
+// Differences from ethereum:
+//   - hashes are IDs of blocks and txs, and web3_sha3 is the hash function of the chain
+//   - a tx with multiple clauses is presented with its first clause only
+//   - eth_sendRawTransaction accepts txs in the plain format, as POST /transactions does with `plain`
+//   - "pending" is the same as "latest"
+//
+// Unsupported methods are listed in unsupportedMethods, and answered with the reason.
+package eth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/HiNounou029/nounouchain/api/utils"
+	"github.com/HiNounou029/nounouchain/common/metric"
+	"github.com/HiNounou029/nounouchain/common/ratelimit"
+	"github.com/HiNounou029/nounouchain/core/chain"
+	"github.com/HiNounou029/nounouchain/core/txpool"
+	"github.com/HiNounou029/nounouchain/nounou/logdb"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/inconshreveable/log15"
+)
+
+var (
+	log = log15.New("pkg", "eth")
+
+	rateLimitedTxsCounter = metric.NewCounter("api_eth_rate_limited_txs", "eth txs rejected due to client IP rate limit")
+)
+
+// unsupportedMethods maps eth methods not supported, to the reason.
+var unsupportedMethods = map[string]string{
+	"eth_accounts":        "the node manages no user accounts",
+	"eth_sign":            "the node manages no user accounts",
+	"eth_signTransaction": "the node manages no user accounts",
+	"eth_sendTransaction": "the node manages no user accounts, use eth_sendRawTransaction",
+
+	"eth_getTransactionCount": "tx nonce is not sequential, any unused nonce is valid",
+	"eth_estimateGas":         "use POST /accounts/*/estimate",
+	"eth_syncing":             "use GET /node/network/peers",
+	"eth_protocolVersion":     "not applicable",
+	"eth_feeHistory":          "not applicable",
+	"eth_getProof":            "not applicable",
+
+	"eth_coinbase":                      "proof of authority, use GET /authority",
+	"eth_mining":                        "proof of authority, no mining",
+	"eth_hashrate":                      "proof of authority, no mining",
+	"eth_getWork":                       "proof of authority, no mining",
+	"eth_submitWork":                    "proof of authority, no mining",
+	"eth_submitHashrate":                "proof of authority, no mining",
+	"net_peerCount":                     "use GET /node/network/peers",
+	"eth_getUncleByBlockHashAndIndex":   "no uncles",
+	"eth_getUncleByBlockNumberAndIndex": "no uncles",
+	"eth_getUncleCountByBlockHash":      "no uncles",
+	"eth_getUncleCountByBlockNumber":    "no uncles",
+
+	"eth_newFilter":                   "use the websocket API at /subscriptions",
+	"eth_newBlockFilter":              "use the websocket API at /subscriptions",
+	"eth_newPendingTransactionFilter": "use the websocket API at /subscriptions",
+	"eth_getFilterChanges":            "use the websocket API at /subscriptions",
+	"eth_getFilterLogs":               "use the websocket API at /subscriptions",
+	"eth_uninstallFilter":             "use the websocket API at /subscriptions",
+	"eth_subscribe":                   "use the websocket API at /subscriptions",
+	"eth_unsubscribe":                 "use the websocket API at /subscriptions",
+}
+
+type method func(ctx context.Context, params []json.RawMessage) (interface{}, error)
+
+// clientIPKey is the context key of the client IP, which txs sent are limited by.
+type clientIPKey struct{}
+
+// Eth the ethereum compatible JSON-RPC API.
+type Eth struct {
+	chain         *chain.Chain
+	stateCreator  *state.Creator
+	pool          *txpool.TxPool
+	logDB         *logdb.LogDB
+	limiter       *ratelimit.Limiter
+	callGasLimit  uint64
+	clientVersion string
+	upgrader      *websocket.Upgrader
+	methods       map[string]method
+	done          chan struct{}
+	wg            sync.WaitGroup
+}
+
+// New creates the eth JSON-RPC API. The limiter limits txs sent per client IP, as the transactions API does,
+// nil means no limitation.
+func New(chain *chain.Chain, stateCreator *state.Creator, pool *txpool.TxPool, logDB *logdb.LogDB, limiter *ratelimit.Limiter,
+	callGasLimit uint64, clientVersion string, allowedOrigins []string) *Eth {
+	e := &Eth{
+		chain:         chain,
+		stateCreator:  stateCreator,
+		pool:          pool,
+		logDB:         logDB,
+		limiter:       limiter,
+		callGasLimit:  callGasLimit,
+		clientVersion: clientVersion,
+		upgrader: &websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" {
+					return true
+				}
+				for _, allowedOrigin := range allowedOrigins {
+					if allowedOrigin == origin || allowedOrigin == "*" {
+						return true
+					}
+				}
+				return false
+			},
+		},
+		done: make(chan struct{}),
+	}
+	e.methods = map[string]method{
+		"web3_clientVersion":        e.clientVersionMethod,
+		"web3_sha3":                 e.sha3,
+		"net_version":               e.netVersion,
+		"net_listening":             e.netListening,
+		"eth_chainId":               e.chainID,
+		"eth_blockNumber":           e.blockNumber,
+		"eth_gasPrice":              e.gasPrice,
+		"eth_getBalance":            e.getBalance,
+		"eth_getCode":               e.getCode,
+		"eth_getStorageAt":          e.getStorageAt,
+		"eth_call":                  e.call,
+		"eth_getBlockByNumber":      e.getBlockByNumber,
+		"eth_getBlockByHash":        e.getBlockByHash,
+		"eth_getTransactionByHash":  e.getTransactionByHash,
+		"eth_getTransactionReceipt": e.getTransactionReceipt,
+		"eth_getLogs":               e.getLogs,
+		"eth_sendRawTransaction":    e.sendRawTransaction,
+	}
+	return e
+}
+
+// dispatch handles a request or a batch of requests, and returns the response to be encoded.
+// Nil is returned if nothing to respond, e.g. for notifications.
+func (e *Eth) dispatch(ctx context.Context, data []byte) interface{} {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return errorResponse(nil, &rpcError{Code: codeParseError, Message: err.Error()})
+		}
+		if len(batch) == 0 {
+			return errorResponse(nil, &rpcError{Code: codeInvalidRequest, Message: "empty batch"})
+		}
+		responses := make([]*response, 0, len(batch))
+		for _, raw := range batch {
+			if resp := e.handleRequest(ctx, raw); resp != nil {
+				responses = append(responses, resp)
+			}
+		}
+		if len(responses) == 0 {
+			return nil
+		}
+		return responses
+	}
+	if resp := e.handleRequest(ctx, data); resp != nil {
+		return resp
+	}
+	return nil
+}
+
+func (e *Eth) handleRequest(ctx context.Context, data []byte) *response {
+	var req request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return errorResponse(nil, &rpcError{Code: codeParseError, Message: err.Error()})
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, &rpcError{Code: codeInvalidRequest, Message: "invalid request"})
+	}
+
+	result, err := e.invoke(ctx, req.Method, req.Params)
+	if len(req.ID) == 0 {
+		// notification
+		return nil
+	}
+	if err != nil {
+		rpcErr, ok := err.(*rpcError)
+		if !ok {
+			rpcErr = &rpcError{Code: codeServerError, Message: err.Error()}
+		}
+		return errorResponse(req.ID, rpcErr)
+	}
+	if result == nil {
+		result = json.RawMessage("null")
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func (e *Eth) invoke(ctx context.Context, name string, rawParams json.RawMessage) (interface{}, error) {
+	m, ok := e.methods[name]
+	if !ok {
+		if reason, ok := unsupportedMethods[name]; ok {
+			return nil, &rpcError{Code: codeMethodNotFound, Message: "the method " + name + " is not supported: " + reason}
+		}
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "the method " + name + " does not exist"}
+	}
+	var params []json.RawMessage
+	if len(rawParams) > 0 && string(rawParams) != "null" {
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "params: should be array"}
+		}
+	}
+	return m(ctx, params)
+}
+
+func errorResponse(id json.RawMessage, err *rpcError) *response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &response{JSONRPC: "2.0", ID: id, Error: err}
+}
+
+func (e *Eth) handleHTTP(w http.ResponseWriter, req *http.Request) error {
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	resp := e.dispatch(context.WithValue(req.Context(), clientIPKey{}, utils.ClientIP(req)), data)
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	return utils.WriteJSON(w, resp)
+}
+
+func (e *Eth) handleWebsocket(w http.ResponseWriter, req *http.Request) error {
+	e.wg.Add(1)
+	defer e.wg.Done()
+
+	conn, err := e.upgrader.Upgrade(w, req, nil)
+	// since the conn is hijacked here, no error should be returned in lines below
+	if err != nil {
+		log.Error("upgrade to websocket", "err", err)
+		return nil
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Debug("close websocket", "err", err)
+		}
+	}()
+
+	// derived from the request, to keep the identity authenticated
+	ctx, cancel := context.WithCancel(context.WithValue(req.Context(), clientIPKey{}, utils.ClientIP(req)))
+	defer cancel()
+	go func() {
+		select {
+		case <-e.done:
+			conn.Close()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			log.Debug("websocket read err", "err", err)
+			return nil
+		}
+		if resp := e.dispatch(ctx, data); resp != nil {
+			if err := conn.WriteJSON(resp); err != nil {
+				log.Debug("websocket write err", "err", err)
+				return nil
+			}
+		}
+	}
+}
+
+// Close closes websocket connections.
+func (e *Eth) Close() {
+	close(e.done)
+	e.wg.Wait()
+}
+
+func (e *Eth) Mount(root *mux.Router, pathPrefix string) {
+	sub := root.PathPrefix(pathPrefix).Subrouter()
+
+	sub.Path("").Methods("POST").HandlerFunc(utils.WrapHandlerFunc(e.handleHTTP))
+	sub.Path("").Methods("GET").HandlerFunc(utils.WrapHandlerFunc(e.handleWebsocket))
+}
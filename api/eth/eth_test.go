@@ -0,0 +1,272 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package eth_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HiNounou029/nounouchain/api/eth"
+	"github.com/HiNounou029/nounouchain/common/ratelimit"
+	"github.com/HiNounou029/nounouchain/core/chain"
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/core/txpool"
+	"github.com/HiNounou029/nounouchain/crypto"
+	"github.com/HiNounou029/nounouchain/miner"
+	"github.com/HiNounou029/nounouchain/nounou/genesis"
+	"github.com/HiNounou029/nounouchain/nounou/logdb"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/HiNounou029/nounouchain/storage"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	ts          *httptest.Server
+	transaction *tx.Transaction
+	recipient   = polo.BytesToAddress([]byte("to"))
+	event       = &tx.Event{
+		Address: polo.BytesToAddress([]byte("addr")),
+		Topics:  []polo.Bytes32{polo.BytesToBytes32([]byte("topic0")), polo.BytesToBytes32([]byte("topic1"))},
+		Data:    []byte{1, 2, 3},
+	}
+)
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func TestEth(t *testing.T) {
+	initEthServer(t)
+	defer ts.Close()
+
+	var str string
+	mustCall(t, &str, "eth_blockNumber")
+	assert.Equal(t, "0x1", str)
+
+	mustCall(t, &str, "eth_getBalance", &recipient, "latest")
+	assert.Equal(t, "0x2710", str)
+	mustCall(t, &str, "eth_getBalance", &recipient, "earliest")
+	assert.Equal(t, "0x0", str)
+
+	mustCall(t, &str, "eth_call", map[string]interface{}{"to": &recipient}, "latest")
+	assert.Equal(t, "0x", str)
+
+	var blk struct {
+		Hash         polo.Bytes32
+		Transactions []polo.Bytes32
+	}
+	mustCall(t, &blk, "eth_getBlockByNumber", "0x1", false)
+	assert.Equal(t, []polo.Bytes32{transaction.ID()}, blk.Transactions)
+
+	var fullBlk struct {
+		Transactions []struct {
+			Hash  polo.Bytes32
+			From  polo.Address
+			To    *polo.Address
+			Value string
+		}
+	}
+	mustCall(t, &fullBlk, "eth_getBlockByHash", &blk.Hash, true)
+	if assert.Len(t, fullBlk.Transactions, 1) {
+		assert.Equal(t, transaction.ID(), fullBlk.Transactions[0].Hash)
+		assert.Equal(t, genesis.DevAccounts()[0].Address, fullBlk.Transactions[0].From)
+		assert.Equal(t, &recipient, fullBlk.Transactions[0].To)
+		assert.Equal(t, "0x2710", fullBlk.Transactions[0].Value)
+	}
+
+	var receipt struct {
+		Status          string
+		GasUsed         string
+		ContractAddress *polo.Address
+		Logs            []struct{ Address polo.Address }
+	}
+	mustCall(t, &receipt, "eth_getTransactionReceipt", transaction.ID().String())
+	assert.Equal(t, "0x1", receipt.Status)
+	assert.Equal(t, "0x5208", receipt.GasUsed)
+	assert.Nil(t, receipt.ContractAddress)
+
+	var logs []struct {
+		Address          polo.Address
+		Topics           []polo.Bytes32
+		Data             string
+		TransactionHash  polo.Bytes32
+		TransactionIndex string
+	}
+	mustCall(t, &logs, "eth_getLogs", map[string]interface{}{
+		"fromBlock": "0x0",
+		"address":   &event.Address,
+		"topics":    []interface{}{nil, []string{polo.BytesToBytes32([]byte("x")).String(), event.Topics[1].String()}},
+	})
+	if assert.Len(t, logs, 1) {
+		assert.Equal(t, event.Address, logs[0].Address)
+		assert.Equal(t, event.Topics, logs[0].Topics)
+		assert.Equal(t, "0x010203", logs[0].Data)
+		assert.Equal(t, transaction.ID(), logs[0].TransactionHash)
+		assert.Equal(t, "0x0", logs[0].TransactionIndex)
+	}
+	mustCall(t, &logs, "eth_getLogs", map[string]interface{}{"fromBlock": "0x0", "topics": []interface{}{event.Topics[1].String()}})
+	assert.Len(t, logs, 0)
+
+	resp := call(t, "eth_accounts")
+	if assert.NotNil(t, resp.Error) {
+		assert.Equal(t, -32601, resp.Error.Code)
+		assert.Contains(t, resp.Error.Message, "not supported")
+	}
+	resp = call(t, "eth_getBalance")
+	if assert.NotNil(t, resp.Error) {
+		assert.Equal(t, -32602, resp.Error.Code)
+	}
+	resp = call(t, "eth_sendRawTransaction", "0x1234")
+	if assert.NotNil(t, resp.Error) {
+		assert.Equal(t, -32602, resp.Error.Code)
+	}
+	// the burst is used up by the previous one
+	resp = call(t, "eth_sendRawTransaction", "0x1234")
+	if assert.NotNil(t, resp.Error) {
+		assert.Equal(t, -32005, resp.Error.Code)
+	}
+}
+
+func TestEthBatch(t *testing.T) {
+	initEthServer(t)
+	defer ts.Close()
+
+	res := httpPost(t, `[{"jsonrpc":"2.0","id":1,"method":"eth_chainId"},{"jsonrpc":"2.0","method":"net_listening"},{"jsonrpc":"2.0","id":2,"method":"foo"}]`)
+	var responses []struct {
+		ID     int
+		Result string
+		Error  *struct{ Code int }
+	}
+	if err := json.Unmarshal(res, &responses); err != nil {
+		t.Fatal(err)
+	}
+	// notification not responded
+	if assert.Len(t, responses, 2) {
+		assert.Equal(t, 1, responses[0].ID)
+		assert.NotEmpty(t, responses[0].Result)
+		assert.Equal(t, 2, responses[1].ID)
+		assert.Equal(t, -32601, responses[1].Error.Code)
+	}
+
+	var resp rpcResponse
+	if err := json.Unmarshal(httpPost(t, `{`), &resp); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, -32700, resp.Error.Code)
+}
+
+func call(t *testing.T, method string, params ...interface{}) *rpcResponse {
+	if params == nil {
+		params = []interface{}{}
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp rpcResponse
+	if err := json.Unmarshal(httpPost(t, string(data)), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return &resp
+}
+
+func mustCall(t *testing.T, result interface{}, method string, params ...interface{}) {
+	resp := call(t, method, params...)
+	if resp.Error != nil {
+		t.Fatalf("%v: %v", method, resp.Error.Message)
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func httpPost(t *testing.T, body string) []byte {
+	res, err := http.Post(ts.URL+"/eth", "application/json", bytes.NewReader([]byte(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func initEthServer(t *testing.T) {
+	db, _ := storage.NewMem()
+	stateC := state.NewCreator(db)
+	gene := genesis.NewDevnet()
+
+	b, _, err := gene.Build(stateC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := chain.New(db, b)
+	transaction = new(tx.Builder).
+		ChainTag(c.Tag()).
+		Expiration(10).
+		Gas(21000).
+		Nonce(1).
+		Clause(tx.NewClause(&recipient).WithValue(big.NewInt(10000))).
+		BlockRef(tx.NewBlockRef(0)).
+		Build()
+	sig, err := crypto.Sign(transaction.SigningHash().Bytes(), genesis.DevAccounts()[0].PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transaction = transaction.WithSignature(sig)
+
+	miner := miner.New(c, stateC, genesis.DevAccounts()[0].Address, &genesis.DevAccounts()[0].Address)
+	flow, err := miner.Schedule(b.Header(), uint64(time.Now().Unix()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := flow.Adopt(transaction); err != nil {
+		t.Fatal(err)
+	}
+	b, stage, receipts, err := flow.Pack(genesis.DevAccounts()[0].PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stage.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.AddBlock(b, receipts); err != nil {
+		t.Fatal(err)
+	}
+
+	logDB, err := logdb.NewMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := logDB.Prepare(b.Header()).ForTransaction(transaction.ID(), genesis.DevAccounts()[0].Address).
+		Insert(tx.Events{event}, nil, false).Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	pool, err := txpool.New(c, stateC, txpool.Options{Limit: 10000, LimitPerAccount: 16, MaxLifetime: 10 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := mux.NewRouter()
+	eth.New(c, stateC, pool, logDB, ratelimit.New(0.001, 1), 10000000, "test", nil).Mount(router, "/eth")
+	ts = httptest.NewServer(router)
+}
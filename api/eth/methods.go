@@ -0,0 +1,432 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/HiNounou029/nounouchain/api/auth"
+	"github.com/HiNounou029/nounouchain/api/transactions"
+	"github.com/HiNounou029/nounouchain/api/utils"
+	"github.com/HiNounou029/nounouchain/common/xenv"
+	"github.com/HiNounou029/nounouchain/core/block"
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/crypto"
+	"github.com/HiNounou029/nounouchain/nounou/builtin"
+	"github.com/HiNounou029/nounouchain/nounou/logdb"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/HiNounou029/nounouchain/vm"
+	"github.com/HiNounou029/nounouchain/vm/runtime"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// maxFilterCriteria limits combinations of addresses and topics of eth_getLogs.
+const maxFilterCriteria = 256
+
+var errHeaderNotFound = &rpcError{Code: codeServerError, Message: "header not found"}
+
+// parseParams decodes positional params into args, of which the first `required` ones are required.
+func parseParams(params []json.RawMessage, required int, args ...interface{}) error {
+	if len(params) < required {
+		return invalidParams(fmt.Errorf("missing value for required argument %v", len(params)))
+	}
+	if len(params) > len(args) {
+		return invalidParams(fmt.Errorf("too many arguments, want at most %v", len(args)))
+	}
+	for i, param := range params {
+		if err := json.Unmarshal(param, args[i]); err != nil {
+			return invalidParams(errors.WithMessage(err, fmt.Sprintf("invalid argument %v", i)))
+		}
+	}
+	return nil
+}
+
+// header returns header of the trunk block, or nil if not found.
+func (e *Eth) header(num blockNumber) (*block.Header, error) {
+	if num == latestBlock || num == pendingBlock {
+		return e.chain.BestBlock().Header(), nil
+	}
+	h, err := e.chain.GetTrunkBlockHeader(uint32(num))
+	if err != nil {
+		if e.chain.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return h, nil
+}
+
+// state returns state after the block, the latest by default.
+func (e *Eth) state(params []json.RawMessage, addr *polo.Address, extra ...interface{}) (*state.State, error) {
+	num := latestBlock
+	args := append([]interface{}{addr}, extra...)
+	if err := parseParams(params, len(args), append(args, &num)...); err != nil {
+		return nil, err
+	}
+	h, err := e.header(num)
+	if err != nil {
+		return nil, err
+	}
+	if h == nil {
+		return nil, errHeaderNotFound
+	}
+	return e.stateCreator.NewState(h.StateRoot())
+}
+
+func (e *Eth) clientVersionMethod(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	return e.clientVersion, nil
+}
+
+func (e *Eth) sha3(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	var data hexutil.Bytes
+	if err := parseParams(params, 1, &data); err != nil {
+		return nil, err
+	}
+	return hexutil.Bytes(crypto.Keccak256(data)), nil
+}
+
+func (e *Eth) netVersion(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	return strconv.Itoa(int(e.chain.Tag())), nil
+}
+
+func (e *Eth) netListening(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	return true, nil
+}
+
+func (e *Eth) chainID(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	return hexutil.Uint64(e.chain.Tag()), nil
+}
+
+func (e *Eth) blockNumber(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	return hexutil.Uint64(e.chain.BestBlock().Header().Number()), nil
+}
+
+func (e *Eth) gasPrice(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	st, err := e.stateCreator.NewState(e.chain.BestBlock().Header().StateRoot())
+	if err != nil {
+		return nil, err
+	}
+	price := builtin.Params.Native(st).Get(polo.KeyBaseGasPrice)
+	if err := st.Err(); err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(price), nil
+}
+
+func (e *Eth) getBalance(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	var addr polo.Address
+	st, err := e.state(params, &addr)
+	if err != nil {
+		return nil, err
+	}
+	balance := st.GetBalance(addr)
+	if err := st.Err(); err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(balance), nil
+}
+
+func (e *Eth) getCode(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	var addr polo.Address
+	st, err := e.state(params, &addr)
+	if err != nil {
+		return nil, err
+	}
+	code := st.GetCode(addr)
+	if err := st.Err(); err != nil {
+		return nil, err
+	}
+	return hexutil.Bytes(code), nil
+}
+
+func (e *Eth) getStorageAt(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	var (
+		addr polo.Address
+		pos  hexutil.Big
+	)
+	st, err := e.state(params, &addr, &pos)
+	if err != nil {
+		return nil, err
+	}
+	if pos.ToInt().Sign() < 0 || pos.ToInt().BitLen() > 256 {
+		return nil, invalidParams(errors.New("invalid argument 1: out of range"))
+	}
+	value := st.GetStorage(addr, polo.BytesToBytes32(pos.ToInt().Bytes()))
+	if err := st.Err(); err != nil {
+		return nil, err
+	}
+	return hexutil.Bytes(value[:]), nil
+}
+
+func (e *Eth) call(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	var (
+		args callArgs
+		num  = latestBlock
+	)
+	if err := parseParams(params, 1, &args, &num); err != nil {
+		return nil, err
+	}
+	h, err := e.header(num)
+	if err != nil {
+		return nil, err
+	}
+	if h == nil {
+		return nil, errHeaderNotFound
+	}
+	st, err := e.stateCreator.NewState(h.StateRoot())
+	if err != nil {
+		return nil, err
+	}
+	signer, _ := h.Signer()
+	rt := runtime.New(e.chain.NewSeeker(h.ParentID()), st, &xenv.BlockContext{
+		Beneficiary: h.Beneficiary(),
+		Signer:      signer,
+		Number:      h.Number(),
+		Time:        h.Timestamp(),
+		GasLimit:    h.GasLimit(),
+		TotalScore:  h.TotalScore()})
+
+	gas := e.callGasLimit
+	if args.Gas != nil && uint64(*args.Gas) < gas {
+		gas = uint64(*args.Gas)
+	}
+	txCtx := &xenv.TransactionContext{GasPrice: new(big.Int)}
+	if args.From != nil {
+		txCtx.Origin = *args.From
+	}
+	if args.GasPrice != nil {
+		txCtx.GasPrice = args.GasPrice.ToInt()
+	}
+
+	exec, interrupt := rt.PrepareClause(args.clause(), 0, gas, txCtx)
+	vmout := make(chan *runtime.Output, 1)
+	go func() {
+		out, _ := exec()
+		vmout <- out
+	}()
+	var out *runtime.Output
+	select {
+	case <-ctx.Done():
+		interrupt()
+		return nil, ctx.Err()
+	case out = <-vmout:
+	}
+	if err := rt.Seeker().Err(); err != nil {
+		return nil, err
+	}
+	if err := st.Err(); err != nil {
+		return nil, err
+	}
+
+	if out.VMErr == vm.ErrExecutionReverted {
+		msg := "execution reverted"
+		if reason, ok := vm.DecodeRevertReason(out.Data); ok {
+			msg += ": " + reason
+		}
+		return nil, &rpcError{Code: codeExecutionReverted, Message: msg, Data: hexutil.Bytes(out.Data)}
+	}
+	if out.VMErr != nil {
+		return nil, &rpcError{Code: codeServerError, Message: out.VMErr.Error()}
+	}
+	return hexutil.Bytes(out.Data), nil
+}
+
+// block returns the block with converted txs, or nil if not found.
+func (e *Eth) block(id polo.Bytes32, fullTx bool) (interface{}, error) {
+	blk, err := e.chain.GetBlock(id)
+	if err != nil {
+		if e.chain.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var receipts tx.Receipts
+	if fullTx {
+		if receipts, err = e.chain.GetBlockReceipts(id); err != nil {
+			return nil, err
+		}
+	}
+	return convertBlock(blk, receipts), nil
+}
+
+func (e *Eth) getBlockByNumber(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	var (
+		num    blockNumber
+		fullTx bool
+	)
+	if err := parseParams(params, 1, &num, &fullTx); err != nil {
+		return nil, err
+	}
+	h, err := e.header(num)
+	if err != nil || h == nil {
+		return nil, err
+	}
+	return e.block(h.ID(), fullTx)
+}
+
+func (e *Eth) getBlockByHash(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	var (
+		id     polo.Bytes32
+		fullTx bool
+	)
+	if err := parseParams(params, 1, &id, &fullTx); err != nil {
+		return nil, err
+	}
+	return e.block(id, fullTx)
+}
+
+// trunkTx returns the block containing the tx on trunk, and index of the tx, or nil if not found.
+func (e *Eth) trunkTx(params []json.RawMessage) (*block.Block, uint64, error) {
+	var txID polo.Bytes32
+	if err := parseParams(params, 1, &txID); err != nil {
+		return nil, 0, err
+	}
+	meta, err := e.chain.GetTrunkTransactionMeta(txID)
+	if err != nil {
+		if e.chain.IsNotFound(err) {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+	blk, err := e.chain.GetBlock(meta.BlockID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return blk, meta.Index, nil
+}
+
+func (e *Eth) getTransactionByHash(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	blk, index, err := e.trunkTx(params)
+	if err != nil || blk == nil {
+		return nil, err
+	}
+	receipt, err := e.chain.GetTransactionReceipt(blk.Header().ID(), index)
+	if err != nil {
+		return nil, err
+	}
+	return convertTransaction(blk.Transactions()[index], blk.Header(), index, receipt), nil
+}
+
+func (e *Eth) getTransactionReceipt(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	blk, index, err := e.trunkTx(params)
+	if err != nil || blk == nil {
+		return nil, err
+	}
+	receipts, err := e.chain.GetBlockReceipts(blk.Header().ID())
+	if err != nil {
+		return nil, err
+	}
+	return convertReceipt(blk, index, receipts), nil
+}
+
+func (e *Eth) getLogs(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	var query filterQuery
+	if err := parseParams(params, 1, &query); err != nil {
+		return nil, err
+	}
+	criteriaSet, err := query.criteriaSet(maxFilterCriteria)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	var from, to *block.Header
+	if query.BlockHash != nil {
+		if query.FromBlock != nil || query.ToBlock != nil {
+			return nil, invalidParams(errors.New("blockHash is exclusive with fromBlock and toBlock"))
+		}
+		if from, err = e.chain.GetBlockHeader(*query.BlockHash); err != nil {
+			if e.chain.IsNotFound(err) {
+				return nil, errHeaderNotFound
+			}
+			return nil, err
+		}
+		to = from
+	} else {
+		fromNum, toNum := latestBlock, latestBlock
+		if query.FromBlock != nil {
+			fromNum = *query.FromBlock
+		}
+		if query.ToBlock != nil {
+			toNum = *query.ToBlock
+		}
+		if from, err = e.header(fromNum); err != nil {
+			return nil, err
+		}
+		if to, err = e.header(toNum); err != nil {
+			return nil, err
+		}
+		if to == nil {
+			to = e.chain.BestBlock().Header()
+		}
+		if from == nil || from.Number() > to.Number() {
+			return []*rpcLog{}, nil
+		}
+	}
+
+	events, err := e.logDB.FilterEvents(ctx, &logdb.EventFilter{
+		CriteriaSet: criteriaSet,
+		Range: &logdb.Range{
+			Unit: logdb.Block,
+			From: uint64(from.Number()),
+			To:   uint64(to.Number()),
+		},
+		Options: &logdb.Options{Limit: utils.MaxPageSize + 1},
+		Order:   logdb.ASC,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(events) > utils.MaxPageSize {
+		return nil, &rpcError{Code: codeLimitExceeded, Message: fmt.Sprintf("query returned more than %v results", utils.MaxPageSize)}
+	}
+
+	logs := make([]*rpcLog, 0, len(events))
+	txIndexes := make(map[polo.Bytes32]uint64)
+	for _, ev := range events {
+		if query.BlockHash != nil && ev.BlockID != *query.BlockHash {
+			continue
+		}
+		txIndex, ok := txIndexes[ev.TxID]
+		if !ok {
+			meta, err := e.chain.GetTransactionMeta(ev.TxID, ev.BlockID)
+			if err != nil {
+				return nil, err
+			}
+			txIndex = meta.Index
+			txIndexes[ev.TxID] = txIndex
+		}
+		logs = append(logs, convertEvent(ev, txIndex))
+	}
+	return logs, nil
+}
+
+func (e *Eth) sendRawTransaction(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	if !auth.Permitted(ctx, auth.RoleSubmit) {
+		return nil, &rpcError{Code: codeServerError, Message: "forbidden: role submit required"}
+	}
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	if !e.limiter.Allow(ip) {
+		rateLimitedTxsCounter.Inc()
+		return nil, &rpcError{Code: codeLimitExceeded, Message: "rate limit exceeded"}
+	}
+	var data hexutil.Bytes
+	if err := parseParams(params, 1, &data); err != nil {
+		return nil, err
+	}
+	plain := transactions.PlainTx{Plain: hexutil.Encode(data)}
+	trx, err := plain.Decode()
+	if err != nil {
+		return nil, invalidParams(errors.WithMessage(err, "invalid argument 0"))
+	}
+	if err := e.pool.Add(trx); err != nil {
+		return nil, err
+	}
+	id := trx.ID()
+	return &id, nil
+}
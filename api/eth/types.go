@@ -0,0 +1,406 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package eth
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/HiNounou029/nounouchain/core/block"
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/nounou/logdb"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// JSON-RPC 2.0 error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeServerError    = -32000
+	codeLimitExceeded  = -32005
+	// as geth does for reverted eth_call
+	codeExecutionReverted = 3
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+func invalidParams(err error) error {
+	return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+}
+
+// blockNumber is the block parameter, a number or one of tags.
+type blockNumber int64
+
+const (
+	latestBlock  blockNumber = -1
+	pendingBlock blockNumber = -2
+)
+
+func (b *blockNumber) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	switch str {
+	case "latest":
+		*b = latestBlock
+	case "pending":
+		*b = pendingBlock
+	case "earliest":
+		*b = 0
+	default:
+		n, err := hexutil.DecodeUint64(str)
+		if err != nil {
+			return err
+		}
+		if n > math.MaxUint32 {
+			return errors.New("block number out of max uint32")
+		}
+		*b = blockNumber(n)
+	}
+	return nil
+}
+
+// addresses is a single address, or an array of addresses.
+type addresses []polo.Address
+
+func (a *addresses) UnmarshalJSON(data []byte) error {
+	var list []polo.Address
+	if err := json.Unmarshal(data, &list); err == nil {
+		*a = list
+		return nil
+	}
+	var addr polo.Address
+	if err := json.Unmarshal(data, &addr); err != nil {
+		return err
+	}
+	*a = addresses{addr}
+	return nil
+}
+
+// topics is a topic position of filter, null, a single topic or an array of alternatives.
+type topics []polo.Bytes32
+
+func (t *topics) UnmarshalJSON(data []byte) error {
+	var list []polo.Bytes32
+	if err := json.Unmarshal(data, &list); err == nil {
+		*t = list
+		return nil
+	}
+	var topic *polo.Bytes32
+	if err := json.Unmarshal(data, &topic); err != nil {
+		return err
+	}
+	if topic == nil {
+		*t = nil
+	} else {
+		*t = topics{*topic}
+	}
+	return nil
+}
+
+type callArgs struct {
+	From     *polo.Address   `json:"from"`
+	To       *polo.Address   `json:"to"`
+	Gas      *hexutil.Uint64 `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Data     *hexutil.Bytes  `json:"data"`
+	Input    *hexutil.Bytes  `json:"input"`
+}
+
+func (c *callArgs) clause() *tx.Clause {
+	clause := tx.NewClause(c.To)
+	if c.Value != nil {
+		clause = clause.WithValue(c.Value.ToInt())
+	}
+	if c.Input != nil {
+		clause = clause.WithData(*c.Input)
+	} else if c.Data != nil {
+		clause = clause.WithData(*c.Data)
+	}
+	return clause
+}
+
+type filterQuery struct {
+	BlockHash *polo.Bytes32 `json:"blockHash"`
+	FromBlock *blockNumber  `json:"fromBlock"`
+	ToBlock   *blockNumber  `json:"toBlock"`
+	Address   addresses     `json:"address"`
+	Topics    []topics      `json:"topics"`
+}
+
+// criteriaSet expands the query into criteria of logdb, which are alternatives.
+func (q *filterQuery) criteriaSet(limit int) ([]*logdb.EventCriteria, error) {
+	if len(q.Topics) > 5 {
+		return nil, errors.New("topics: too many positions")
+	}
+	set := []*logdb.EventCriteria{{}}
+	if len(q.Address) > 0 {
+		set = set[:0]
+		for i := range q.Address {
+			set = append(set, &logdb.EventCriteria{Address: &q.Address[i]})
+		}
+	}
+	for pos, alternatives := range q.Topics {
+		if len(alternatives) == 0 {
+			continue
+		}
+		if len(set)*len(alternatives) > limit {
+			return nil, fmt.Errorf("topics: more than %v combinations", limit)
+		}
+		expanded := make([]*logdb.EventCriteria, 0, len(set)*len(alternatives))
+		for _, c := range set {
+			for i := range alternatives {
+				criteria := *c
+				criteria.Topics[pos] = &alternatives[i]
+				expanded = append(expanded, &criteria)
+			}
+		}
+		set = expanded
+	}
+	return set, nil
+}
+
+type rpcBlock struct {
+	Number           hexutil.Uint64 `json:"number"`
+	Hash             polo.Bytes32   `json:"hash"`
+	ParentHash       polo.Bytes32   `json:"parentHash"`
+	Nonce            hexutil.Bytes  `json:"nonce"`
+	MixHash          polo.Bytes32   `json:"mixHash"`
+	Sha3Uncles       polo.Bytes32   `json:"sha3Uncles"`
+	LogsBloom        hexutil.Bytes  `json:"logsBloom"`
+	TransactionsRoot polo.Bytes32   `json:"transactionsRoot"`
+	StateRoot        polo.Bytes32   `json:"stateRoot"`
+	ReceiptsRoot     polo.Bytes32   `json:"receiptsRoot"`
+	Miner            polo.Address   `json:"miner"`
+	Difficulty       hexutil.Uint64 `json:"difficulty"`
+	TotalDifficulty  hexutil.Uint64 `json:"totalDifficulty"`
+	ExtraData        hexutil.Bytes  `json:"extraData"`
+	Size             hexutil.Uint64 `json:"size"`
+	GasLimit         hexutil.Uint64 `json:"gasLimit"`
+	GasUsed          hexutil.Uint64 `json:"gasUsed"`
+	Timestamp        hexutil.Uint64 `json:"timestamp"`
+	Transactions     []interface{}  `json:"transactions"`
+	Uncles           []polo.Bytes32 `json:"uncles"`
+}
+
+// convertBlock converts the block. Txs are converted as objects if receipts given, or as hashes.
+// Fields not applicable are zero, as they are in geth for PoA networks.
+func convertBlock(blk *block.Block, receipts tx.Receipts) *rpcBlock {
+	header := blk.Header()
+	txs := blk.Transactions()
+	result := &rpcBlock{
+		Number:           hexutil.Uint64(header.Number()),
+		Hash:             header.ID(),
+		ParentHash:       header.ParentID(),
+		Nonce:            make(hexutil.Bytes, 8),
+		LogsBloom:        make(hexutil.Bytes, 256),
+		TransactionsRoot: header.TxsRoot(),
+		StateRoot:        header.StateRoot(),
+		ReceiptsRoot:     header.ReceiptsRoot(),
+		Miner:            header.Beneficiary(),
+		TotalDifficulty:  hexutil.Uint64(header.TotalScore()),
+		ExtraData:        hexutil.Bytes{},
+		Size:             hexutil.Uint64(blk.Size()),
+		GasLimit:         hexutil.Uint64(header.GasLimit()),
+		GasUsed:          hexutil.Uint64(header.GasUsed()),
+		Timestamp:        hexutil.Uint64(header.Timestamp()),
+		Transactions:     make([]interface{}, 0, len(txs)),
+		Uncles:           []polo.Bytes32{},
+	}
+	for i, t := range txs {
+		if receipts == nil {
+			id := t.ID()
+			result.Transactions = append(result.Transactions, &id)
+		} else {
+			result.Transactions = append(result.Transactions, convertTransaction(t, header, uint64(i), receipts[i]))
+		}
+	}
+	return result
+}
+
+type rpcTransaction struct {
+	Hash             polo.Bytes32   `json:"hash"`
+	Nonce            hexutil.Uint64 `json:"nonce"`
+	BlockHash        polo.Bytes32   `json:"blockHash"`
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	TransactionIndex hexutil.Uint64 `json:"transactionIndex"`
+	From             polo.Address   `json:"from"`
+	To               *polo.Address  `json:"to"`
+	Value            *hexutil.Big   `json:"value"`
+	Gas              hexutil.Uint64 `json:"gas"`
+	GasPrice         *hexutil.Big   `json:"gasPrice"`
+	Input            hexutil.Bytes  `json:"input"`
+}
+
+// convertTransaction converts the tx, of which only the first clause is presented.
+// The gas price is the effective one, derived from the receipt.
+func convertTransaction(t *tx.Transaction, header *block.Header, index uint64, receipt *tx.Receipt) *rpcTransaction {
+	origin, _ := t.Signer()
+	result := &rpcTransaction{
+		Hash:             t.ID(),
+		Nonce:            hexutil.Uint64(t.Nonce()),
+		BlockHash:        header.ID(),
+		BlockNumber:      hexutil.Uint64(header.Number()),
+		TransactionIndex: hexutil.Uint64(index),
+		From:             origin,
+		Value:            (*hexutil.Big)(new(big.Int)),
+		Gas:              hexutil.Uint64(t.Gas()),
+		GasPrice:         (*hexutil.Big)(effectiveGasPrice(receipt)),
+		Input:            hexutil.Bytes{},
+	}
+	if clauses := t.Clauses(); len(clauses) > 0 {
+		result.To = clauses[0].To()
+		result.Value = (*hexutil.Big)(clauses[0].Value())
+		result.Input = clauses[0].Data()
+	}
+	return result
+}
+
+func effectiveGasPrice(receipt *tx.Receipt) *big.Int {
+	if receipt.GasUsed == 0 || receipt.Paid == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Div(receipt.Paid, new(big.Int).SetUint64(receipt.GasUsed))
+}
+
+type rpcLog struct {
+	Address          polo.Address   `json:"address"`
+	Topics           []polo.Bytes32 `json:"topics"`
+	Data             hexutil.Bytes  `json:"data"`
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	BlockHash        polo.Bytes32   `json:"blockHash"`
+	TransactionHash  polo.Bytes32   `json:"transactionHash"`
+	TransactionIndex hexutil.Uint64 `json:"transactionIndex"`
+	LogIndex         hexutil.Uint64 `json:"logIndex"`
+	Removed          bool           `json:"removed"`
+}
+
+func convertEvent(ev *logdb.Event, txIndex uint64) *rpcLog {
+	result := &rpcLog{
+		Address:          ev.Address,
+		Topics:           []polo.Bytes32{},
+		Data:             ev.Data,
+		BlockNumber:      hexutil.Uint64(ev.BlockNumber),
+		BlockHash:        ev.BlockID,
+		TransactionHash:  ev.TxID,
+		TransactionIndex: hexutil.Uint64(txIndex),
+		LogIndex:         hexutil.Uint64(ev.Index),
+	}
+	for _, topic := range ev.Topics {
+		if topic != nil {
+			result.Topics = append(result.Topics, *topic)
+		}
+	}
+	if result.Data == nil {
+		result.Data = hexutil.Bytes{}
+	}
+	return result
+}
+
+type rpcReceipt struct {
+	TransactionHash   polo.Bytes32   `json:"transactionHash"`
+	TransactionIndex  hexutil.Uint64 `json:"transactionIndex"`
+	BlockHash         polo.Bytes32   `json:"blockHash"`
+	BlockNumber       hexutil.Uint64 `json:"blockNumber"`
+	From              polo.Address   `json:"from"`
+	To                *polo.Address  `json:"to"`
+	GasUsed           hexutil.Uint64 `json:"gasUsed"`
+	CumulativeGasUsed hexutil.Uint64 `json:"cumulativeGasUsed"`
+	EffectiveGasPrice *hexutil.Big   `json:"effectiveGasPrice"`
+	ContractAddress   *polo.Address  `json:"contractAddress"`
+	Logs              []*rpcLog      `json:"logs"`
+	LogsBloom         hexutil.Bytes  `json:"logsBloom"`
+	Status            hexutil.Uint64 `json:"status"`
+}
+
+// convertReceipt converts receipt of the tx at index of the block, with receipts of the block.
+func convertReceipt(blk *block.Block, index uint64, receipts tx.Receipts) *rpcReceipt {
+	header := blk.Header()
+	t := blk.Transactions()[index]
+	receipt := receipts[index]
+	origin, _ := t.Signer()
+
+	var cumulativeGasUsed uint64
+	var logIndex uint32
+	for _, r := range receipts[:index] {
+		cumulativeGasUsed += r.GasUsed
+		for _, o := range r.Outputs {
+			logIndex += uint32(len(o.Events))
+		}
+	}
+
+	result := &rpcReceipt{
+		TransactionHash:   t.ID(),
+		TransactionIndex:  hexutil.Uint64(index),
+		BlockHash:         header.ID(),
+		BlockNumber:       hexutil.Uint64(header.Number()),
+		From:              origin,
+		GasUsed:           hexutil.Uint64(receipt.GasUsed),
+		CumulativeGasUsed: hexutil.Uint64(cumulativeGasUsed + receipt.GasUsed),
+		EffectiveGasPrice: (*hexutil.Big)(effectiveGasPrice(receipt)),
+		Logs:              []*rpcLog{},
+		LogsBloom:         make(hexutil.Bytes, 256),
+	}
+	if !receipt.Reverted {
+		result.Status = 1
+	}
+	if clauses := t.Clauses(); len(clauses) > 0 {
+		result.To = clauses[0].To()
+		if result.To == nil && !receipt.Reverted {
+			addr := polo.CreateContractAddress(t.ID(), 0, 0)
+			result.ContractAddress = &addr
+		}
+	}
+	for _, o := range receipt.Outputs {
+		for _, ev := range o.Events {
+			result.Logs = append(result.Logs, convertEvent(&logdb.Event{
+				BlockID:     header.ID(),
+				Index:       logIndex,
+				BlockNumber: header.Number(),
+				TxID:        t.ID(),
+				Address:     ev.Address,
+				Topics:      eventTopics(ev.Topics),
+				Data:        ev.Data,
+			}, index))
+			logIndex++
+		}
+	}
+	return result
+}
+
+func eventTopics(list []polo.Bytes32) (topics [5]*polo.Bytes32) {
+	for i := 0; i < len(list) && i < len(topics); i++ {
+		topics[i] = &list[i]
+	}
+	return
+}
@@ -4,6 +4,7 @@ package events
 
 import (
 	"context"
+	"math"
 	"net/http"
 
 	"github.com/HiNounou029/nounouchain/api/utils"
@@ -40,6 +41,14 @@ func (e *Events) handleFilter(w http.ResponseWriter, req *http.Request) error {
 	if err := utils.ParseJSON(req.Body, &filter); err != nil {
 		return utils.BadRequest(errors.WithMessage(err, "body"))
 	}
+	if utils.AcceptsNDJSON(req) {
+		return e.stream(w, req, convertEventFilter(&filter))
+	}
+	options, err := utils.PageOptions(filter.Options)
+	if err != nil {
+		return err
+	}
+	filter.Options = options
 	fes, err := e.filter(req.Context(), &filter)
 	if err != nil {
 		return err
@@ -47,6 +56,41 @@ func (e *Events) handleFilter(w http.ResponseWriter, req *http.Request) error {
 	return utils.WriteJSON(w, fes)
 }
 
+// stream writes all events matched in NDJSON, by pages queried with cursor.
+func (e *Events) stream(w http.ResponseWriter, req *http.Request, filter *logdb.EventFilter) error {
+	offset, remaining := uint64(0), uint64(math.MaxUint64)
+	if filter.Options != nil {
+		offset, remaining = filter.Options.Offset, filter.Options.Limit
+	}
+	nw := utils.NewNDJSONWriter(w)
+	for remaining > 0 {
+		limit := remaining
+		if limit > utils.MaxPageSize {
+			limit = utils.MaxPageSize
+		}
+		filter.Options = &logdb.Options{Offset: offset, Limit: limit}
+		events, err := e.db.FilterEvents(req.Context(), filter)
+		if err != nil {
+			return nw.Fail(err)
+		}
+		for _, event := range events {
+			if err := nw.Write(convertEvent(event)); err != nil {
+				// client gone
+				return nil
+			}
+		}
+		nw.Flush()
+		if uint64(len(events)) < limit {
+			break
+		}
+		last := events[len(events)-1]
+		filter.Cursor = &logdb.Cursor{BlockNumber: last.BlockNumber, Index: last.Index}
+		offset = 0
+		remaining -= limit
+	}
+	return nil
+}
+
 func (e *Events) Mount(root *mux.Router, pathPrefix string) {
 	sub := root.PathPrefix(pathPrefix).Subrouter()
 
@@ -64,6 +64,17 @@ func getEvents(t *testing.T) {
 		t.Fatal(err)
 	}
 	assert.Equal(t, limit, len(logs), "should be `limit` logs")
+
+	// next page
+	filter.Cursor = &logs[len(logs)-1].Cursor
+	res = httpPost(t, ts.URL+"/logs/event?", filter)
+	var next []*events.FilteredEvent
+	if err := json.Unmarshal(res, &next); err != nil {
+		t.Fatal(err)
+	}
+	if assert.Equal(t, limit, len(next)) {
+		assert.Equal(t, logs[len(logs)-1].Meta.BlockNumber+1, next[0].Meta.BlockNumber)
+	}
 }
 func initEventServer(t *testing.T) {
 	db, err := logdb.NewMem()
@@ -79,7 +90,7 @@ func initEventServer(t *testing.T) {
 	header := new(block.Builder).Build().Header()
 	for i := 0; i < 100; i++ {
 		if err := db.Prepare(header).ForTransaction(polo.BytesToBytes32([]byte("txID")), polo.BytesToAddress([]byte("txOrigin"))).
-			Insert(tx.Events{txEv}, nil).Commit(); err != nil {
+			Insert(tx.Events{txEv}, nil, false).Commit(); err != nil {
 			if err != nil {
 				t.Fatal(err)
 			}
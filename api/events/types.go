@@ -25,6 +25,7 @@ type FilteredEvent struct {
 	Topics  []*polo.Bytes32      `json:"topics"`
 	Data    string               `json:"data"`
 	Meta    transactions.LogMeta `json:"meta"`
+	Cursor  logdb.Cursor         `json:"cursor"`
 }
 
 //convert a logdb.Event into a json format Event
@@ -39,6 +40,7 @@ func convertEvent(event *logdb.Event) *FilteredEvent {
 			TxID:           event.TxID,
 			TxOrigin:       event.TxOrigin,
 		},
+		Cursor: logdb.Cursor{BlockNumber: event.BlockNumber, Index: event.Index},
 	}
 	fe.Topics = make([]*polo.Bytes32, 0)
 	for i := 0; i < 5; i++ {
@@ -80,6 +82,7 @@ type EventCriteria struct {
 type EventFilter struct {
 	CriteriaSet []*EventCriteria `json:"criteriaSet"`
 	Range       *logdb.Range     `json:"range"`
+	Cursor      *logdb.Cursor    `json:"cursor"`
 	Options     *logdb.Options   `json:"options"`
 	Order       logdb.Order      `json:"order"`
 }
@@ -87,6 +90,7 @@ type EventFilter struct {
 func convertEventFilter(filter *EventFilter) *logdb.EventFilter {
 	f := &logdb.EventFilter{
 		Range:   filter.Range,
+		Cursor:  filter.Cursor,
 		Options: filter.Options,
 		Order:   filter.Order,
 	}
@@ -56,6 +56,11 @@ func (e *EventsLegacy) handleFilter(w http.ResponseWriter, req *http.Request) er
 	} else {
 		filter.Order = logdb.DESC
 	}
+	options, err := utils.PageOptions(filter.Options)
+	if err != nil {
+		return err
+	}
+	filter.Options = options
 	fes, err := e.filter(req.Context(), &filter)
 	if err != nil {
 		return err
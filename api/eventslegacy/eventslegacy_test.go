@@ -12,6 +12,7 @@ import (
 
 	"github.com/HiNounou029/nounouchain/api/events"
 	"github.com/HiNounou029/nounouchain/api/eventslegacy"
+	"github.com/HiNounou029/nounouchain/api/utils"
 	"github.com/HiNounou029/nounouchain/polo"
 	"github.com/HiNounou029/nounouchain/nounou/logdb"
 	"github.com/HiNounou029/nounouchain/core/block"
@@ -60,6 +61,15 @@ func getEvents(t *testing.T) {
 		t.Fatal(err)
 	}
 	assert.Equal(t, limit, len(logs), "should be `limit` logs")
+
+	filter.Options.Limit = utils.MaxPageSize + 1
+	data, _ := json.Marshal(filter)
+	resp, err := http.Post(ts.URL+"/logs/events", "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "limit exceeds page size")
 }
 
 func initEventServer(t *testing.T) {
@@ -76,7 +86,7 @@ func initEventServer(t *testing.T) {
 	header := new(block.Builder).Build().Header()
 	for i := 0; i < 100; i++ {
 		if err := db.Prepare(header).ForTransaction(polo.BytesToBytes32([]byte("txID")), polo.BytesToAddress([]byte("txOrigin"))).
-			Insert(tx.Events{txEv}, nil).Commit(); err != nil {
+			Insert(tx.Events{txEv}, nil, false).Commit(); err != nil {
 			if err != nil {
 				t.Fatal(err)
 			}
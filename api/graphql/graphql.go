@@ -0,0 +1,192 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+// Package graphql serves GraphQL queries over blocks, transactions, receipts, accounts and logs,
+// so that a page can be rendered with one round-trip instead of dozens of REST calls.
+//
+// The cost of a query is limited. Each block, transaction, receipt, account or log resolved costs 1,
+// and the query fails once the cost limit is exceeded.
+package graphql
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/HiNounou029/nounouchain/api/utils"
+	"github.com/HiNounou029/nounouchain/core/block"
+	"github.com/HiNounou029/nounouchain/core/chain"
+	"github.com/HiNounou029/nounouchain/nounou/logdb"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/gorilla/mux"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+)
+
+// maxQueryDepth limits nesting of selections.
+const maxQueryDepth = 12
+
+var (
+	log = log15.New("pkg", "graphql")
+
+	errCostExceeded = errors.New("query cost exceeds limit")
+)
+
+// GraphQL the GraphQL API.
+type GraphQL struct {
+	chain        *chain.Chain
+	stateCreator *state.Creator
+	logDB        *logdb.LogDB
+	costLimit    uint64
+	schema       *graphql.Schema
+}
+
+// New creates the GraphQL API, of which queries cost at most costLimit.
+func New(chain *chain.Chain, stateCreator *state.Creator, logDB *logdb.LogDB, costLimit uint64) *GraphQL {
+	g := &GraphQL{
+		chain:        chain,
+		stateCreator: stateCreator,
+		logDB:        logDB,
+		costLimit:    costLimit,
+	}
+	g.schema = graphql.MustParseSchema(schema, &queryResolver{g},
+		graphql.MaxDepth(maxQueryDepth),
+		graphql.Logger(panicLogger{}))
+	return g
+}
+
+// panicLogger logs panics in resolvers.
+type panicLogger struct{}
+
+func (panicLogger) LogPanic(ctx context.Context, value interface{}) {
+	log.Error("graphql resolver panic", "err", value)
+}
+
+type budgetKey struct{}
+
+// charge consumes n from the budget of the query.
+func charge(ctx context.Context, n uint64) error {
+	left, ok := ctx.Value(budgetKey{}).(*int64)
+	if !ok {
+		return nil
+	}
+	if n > math.MaxInt64 || atomic.AddInt64(left, -int64(n)) < 0 {
+		return errCostExceeded
+	}
+	return nil
+}
+
+// remaining returns budget left of the query.
+func remaining(ctx context.Context) uint64 {
+	left, ok := ctx.Value(budgetKey{}).(*int64)
+	if !ok {
+		return math.MaxInt64
+	}
+	if n := atomic.LoadInt64(left); n > 0 {
+		return uint64(n)
+	}
+	return 0
+}
+
+// header returns header of the revision, or nil if not found.
+func (g *GraphQL) header(revision *string) (*block.Header, error) {
+	if revision == nil || *revision == "" || *revision == "best" {
+		return g.chain.BestBlock().Header(), nil
+	}
+	var (
+		h   *block.Header
+		err error
+	)
+	if len(*revision) == 66 || len(*revision) == 64 {
+		blockID, perr := polo.ParseBytes32(*revision)
+		if perr != nil {
+			return nil, errors.WithMessage(perr, "revision")
+		}
+		h, err = g.chain.GetBlockHeader(blockID)
+	} else {
+		n, perr := strconv.ParseUint(*revision, 0, 0)
+		if perr != nil {
+			return nil, errors.WithMessage(perr, "revision")
+		}
+		if n > math.MaxUint32 {
+			return nil, errors.WithMessage(errors.New("block number out of max uint32"), "revision")
+		}
+		h, err = g.chain.GetTrunkBlockHeader(uint32(n))
+	}
+	if err != nil {
+		if g.chain.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return h, nil
+}
+
+func (g *GraphQL) block(ctx context.Context, id polo.Bytes32) (*blockResolver, error) {
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	blk, err := g.chain.GetBlock(id)
+	if err != nil {
+		if g.chain.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &blockResolver{g, blk}, nil
+}
+
+func (g *GraphQL) transaction(blockID polo.Bytes32, index uint64) (*txResolver, error) {
+	header, err := g.chain.GetBlockHeader(blockID)
+	if err != nil {
+		return nil, err
+	}
+	t, err := g.chain.GetTransaction(blockID, index)
+	if err != nil {
+		return nil, err
+	}
+	return &txResolver{g, t, header, index}, nil
+}
+
+func (g *GraphQL) account(ctx context.Context, address string, header *block.Header) (*accountResolver, error) {
+	addr, err := polo.ParseAddress(address)
+	if err != nil {
+		return nil, errors.WithMessage(err, "address")
+	}
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	st, err := g.stateCreator.NewState(header.StateRoot())
+	if err != nil {
+		return nil, err
+	}
+	return &accountResolver{addr, st}, nil
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func (g *GraphQL) handleQuery(w http.ResponseWriter, req *http.Request) error {
+	var r request
+	if err := utils.ParseJSON(req.Body, &r); err != nil {
+		return utils.BadRequest(errors.WithMessage(err, "body"))
+	}
+	left := int64(g.costLimit)
+	if g.costLimit > math.MaxInt64 {
+		left = math.MaxInt64
+	}
+	ctx := context.WithValue(req.Context(), budgetKey{}, &left)
+	return utils.WriteJSON(w, g.schema.Exec(ctx, r.Query, r.OperationName, r.Variables))
+}
+
+func (g *GraphQL) Mount(root *mux.Router, pathPrefix string) {
+	sub := root.PathPrefix(pathPrefix).Subrouter()
+
+	sub.Path("").Methods("POST").HandlerFunc(utils.WrapHandlerFunc(g.handleQuery))
+}
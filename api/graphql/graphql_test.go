@@ -0,0 +1,252 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package graphql_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HiNounou029/nounouchain/api/graphql"
+	"github.com/HiNounou029/nounouchain/core/chain"
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/crypto"
+	"github.com/HiNounou029/nounouchain/miner"
+	"github.com/HiNounou029/nounouchain/nounou/genesis"
+	"github.com/HiNounou029/nounouchain/nounou/logdb"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/HiNounou029/nounouchain/storage"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	ts          *httptest.Server
+	transaction *tx.Transaction
+	recipient   = polo.BytesToAddress([]byte("to"))
+	event       = &tx.Event{
+		Address: polo.BytesToAddress([]byte("addr")),
+		Topics:  []polo.Bytes32{polo.BytesToBytes32([]byte("topic0"))},
+		Data:    []byte{1, 2, 3},
+	}
+)
+
+type response struct {
+	Data   json.RawMessage
+	Errors []struct{ Message string }
+}
+
+func TestGraphQL(t *testing.T) {
+	initGraphQLServer(t, 10000)
+	defer ts.Close()
+
+	var blk struct {
+		Block struct {
+			Number       uint64
+			IsTrunk      bool
+			Parent       struct{ Number uint64 }
+			Transactions []struct {
+				ID      string
+				Origin  string
+				Clauses []struct{ To, Value string }
+				Receipt struct {
+					GasUsed  uint64
+					Reverted bool
+					Outputs  []struct {
+						Transfers []struct{ Sender, Recipient, Amount string }
+					}
+				}
+			}
+			Account struct{ Balance string }
+		}
+	}
+	query(t, `{
+		block(revision: "1") {
+			number isTrunk parent { number }
+			transactions {
+				id origin clauses { to value }
+				receipt { gasUsed reverted outputs { transfers { sender recipient amount } } }
+			}
+			account(address: "`+recipient.String()+`") { balance }
+		}
+	}`, nil, &blk)
+	assert.Equal(t, uint64(1), blk.Block.Number)
+	assert.True(t, blk.Block.IsTrunk)
+	assert.Equal(t, uint64(0), blk.Block.Parent.Number)
+	if assert.Len(t, blk.Block.Transactions, 1) {
+		trx := blk.Block.Transactions[0]
+		assert.Equal(t, transaction.ID().String(), trx.ID)
+		assert.Equal(t, genesis.DevAccounts()[0].Address.String(), trx.Origin)
+		assert.Equal(t, recipient.String(), trx.Clauses[0].To)
+		assert.Equal(t, "0x2710", trx.Clauses[0].Value)
+		assert.Equal(t, uint64(21000), trx.Receipt.GasUsed)
+		assert.False(t, trx.Receipt.Reverted)
+		assert.Equal(t, recipient.String(), trx.Receipt.Outputs[0].Transfers[0].Recipient)
+		assert.Equal(t, "0x2710", trx.Receipt.Outputs[0].Transfers[0].Amount)
+	}
+	assert.Equal(t, "0x2710", blk.Block.Account.Balance)
+
+	var acc struct {
+		Account struct {
+			Balance string
+			HasCode bool
+		}
+	}
+	query(t, `query($addr: String!, $rev: String) { account(address: $addr, revision: $rev) { balance hasCode } }`,
+		map[string]interface{}{"addr": recipient.String(), "rev": "0"}, &acc)
+	assert.Equal(t, "0x0", acc.Account.Balance)
+	assert.False(t, acc.Account.HasCode)
+
+	var logs struct {
+		Events []struct {
+			Address     string
+			Topics      []string
+			Data        string
+			BlockNumber uint64
+			Transaction struct{ ID string }
+		}
+		Transfers []struct {
+			Sender, Recipient, Amount string
+			Block                     struct{ Number uint64 }
+		}
+	}
+	query(t, `query($from: Long!) {
+		events(filter: {range: {from: $from, to: 1}, criteriaSet: [{address: "`+event.Address.String()+`"}]}) {
+			address topics data blockNumber transaction { id }
+		}
+		transfers(filter: {criteriaSet: [{recipient: "`+recipient.String()+`"}], options: {limit: 10}}) {
+			sender recipient amount block { number }
+		}
+	}`, map[string]interface{}{"from": 0}, &logs)
+	if assert.Len(t, logs.Events, 1) {
+		assert.Equal(t, event.Address.String(), logs.Events[0].Address)
+		assert.Equal(t, []string{event.Topics[0].String()}, logs.Events[0].Topics)
+		assert.Equal(t, "0x010203", logs.Events[0].Data)
+		assert.Equal(t, uint64(1), logs.Events[0].BlockNumber)
+		assert.Equal(t, transaction.ID().String(), logs.Events[0].Transaction.ID)
+	}
+	if assert.Len(t, logs.Transfers, 1) {
+		assert.Equal(t, "0x2710", logs.Transfers[0].Amount)
+		assert.Equal(t, uint64(1), logs.Transfers[0].Block.Number)
+	}
+
+	var notFound struct{ Transaction *struct{ ID string } }
+	query(t, `{ transaction(id: "`+polo.Bytes32{}.String()+`") { id } }`, nil, &notFound)
+	assert.Nil(t, notFound.Transaction)
+
+	resp := post(t, `{ account(address: "bad") { balance } }`, nil)
+	assert.NotEmpty(t, resp.Errors)
+}
+
+func TestGraphQLCostLimit(t *testing.T) {
+	initGraphQLServer(t, 3)
+	defer ts.Close()
+
+	var blocks struct{ Blocks []struct{ Number uint64 } }
+	query(t, `{ blocks(from: 0, to: 1) { number } }`, nil, &blocks)
+	assert.Len(t, blocks.Blocks, 2)
+
+	resp := post(t, `{ blocks(from: 0, to: 1) { number transactions { receipt { gasUsed } } } }`, nil)
+	if assert.NotEmpty(t, resp.Errors) {
+		assert.Contains(t, resp.Errors[0].Message, "query cost exceeds limit")
+	}
+
+	resp = post(t, `{ transfers(filter: {}) { block { number } } events(filter: {}) { block { number } } }`, nil)
+	if assert.NotEmpty(t, resp.Errors) {
+		assert.Contains(t, resp.Errors[0].Message, "query cost exceeds limit")
+	}
+}
+
+func post(t *testing.T, q string, vars map[string]interface{}) *response {
+	data, err := json.Marshal(map[string]interface{}{"query": q, "variables": vars})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.Post(ts.URL+"/graphql", "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	r, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp response
+	if err := json.Unmarshal(r, &resp); err != nil {
+		t.Fatal(err)
+	}
+	return &resp
+}
+
+func query(t *testing.T, q string, vars map[string]interface{}, result interface{}) {
+	resp := post(t, q, vars)
+	if len(resp.Errors) > 0 {
+		t.Fatal(resp.Errors)
+	}
+	if err := json.Unmarshal(resp.Data, result); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func initGraphQLServer(t *testing.T, costLimit uint64) {
+	db, _ := storage.NewMem()
+	stateC := state.NewCreator(db)
+	gene := genesis.NewDevnet()
+
+	b, _, err := gene.Build(stateC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := chain.New(db, b)
+	transaction = new(tx.Builder).
+		ChainTag(c.Tag()).
+		Expiration(10).
+		Gas(21000).
+		Nonce(1).
+		Clause(tx.NewClause(&recipient).WithValue(big.NewInt(10000))).
+		BlockRef(tx.NewBlockRef(0)).
+		Build()
+	sig, err := crypto.Sign(transaction.SigningHash().Bytes(), genesis.DevAccounts()[0].PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transaction = transaction.WithSignature(sig)
+
+	miner := miner.New(c, stateC, genesis.DevAccounts()[0].Address, &genesis.DevAccounts()[0].Address)
+	flow, err := miner.Schedule(b.Header(), uint64(time.Now().Unix()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := flow.Adopt(transaction); err != nil {
+		t.Fatal(err)
+	}
+	b, stage, receipts, err := flow.Pack(genesis.DevAccounts()[0].PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stage.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.AddBlock(b, receipts); err != nil {
+		t.Fatal(err)
+	}
+
+	logDB, err := logdb.NewMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := logDB.Prepare(b.Header()).ForTransaction(transaction.ID(), genesis.DevAccounts()[0].Address).
+		Insert(tx.Events{event}, receipts[0].Outputs[0].Transfers, false).Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	router := mux.NewRouter()
+	graphql.New(c, stateC, logDB, costLimit).Mount(router, "/graphql")
+	ts = httptest.NewServer(router)
+}
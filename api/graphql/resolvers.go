@@ -0,0 +1,459 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package graphql
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/HiNounou029/nounouchain/api/transactions"
+	"github.com/HiNounou029/nounouchain/core/block"
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/nounou/logdb"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+func encodeBig(v *big.Int) string {
+	if v == nil {
+		return "0x0"
+	}
+	return hexutil.EncodeBig(v)
+}
+
+func optionalString(s interface{ String() string }, ok bool) *string {
+	if !ok {
+		return nil
+	}
+	str := s.String()
+	return &str
+}
+
+// queryResolver resolves the root Query.
+type queryResolver struct {
+	g *GraphQL
+}
+
+func (q *queryResolver) Block(ctx context.Context, args struct{ Revision *string }) (*blockResolver, error) {
+	h, err := q.g.header(args.Revision)
+	if err != nil || h == nil {
+		return nil, err
+	}
+	return q.g.block(ctx, h.ID())
+}
+
+func (q *queryResolver) Blocks(ctx context.Context, args struct{ From, To Long }) ([]*blockResolver, error) {
+	best := q.g.chain.BestBlock().Header().Number()
+	if args.From > args.To {
+		return nil, errors.New("from: greater than to")
+	}
+	if uint64(args.To) > uint64(best) {
+		return nil, errors.New("to: greater than best block number")
+	}
+	if err := charge(ctx, uint64(args.To-args.From)+1); err != nil {
+		return nil, err
+	}
+	blocks := make([]*blockResolver, 0, args.To-args.From+1)
+	for num := uint32(args.From); num <= uint32(args.To); num++ {
+		blk, err := q.g.chain.GetTrunkBlock(num)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, &blockResolver{q.g, blk})
+	}
+	return blocks, nil
+}
+
+func (q *queryResolver) Transaction(ctx context.Context, args struct{ ID string }) (*txResolver, error) {
+	txID, err := polo.ParseBytes32(args.ID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "id")
+	}
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	meta, err := q.g.chain.GetTrunkTransactionMeta(txID)
+	if err != nil {
+		if q.g.chain.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return q.g.transaction(meta.BlockID, meta.Index)
+}
+
+func (q *queryResolver) Account(ctx context.Context, args struct {
+	Address  string
+	Revision *string
+}) (*accountResolver, error) {
+	h, err := q.g.header(args.Revision)
+	if err != nil {
+		return nil, err
+	}
+	if h == nil {
+		return nil, errors.New("revision: block not found")
+	}
+	return q.g.account(ctx, args.Address, h)
+}
+
+func (q *queryResolver) Events(ctx context.Context, args struct{ Filter *eventFilterInput }) ([]*eventResolver, error) {
+	// one more than budget left, to know if it's exceeded
+	filter, err := convertEventFilter(args.Filter, remaining(ctx)+1)
+	if err != nil {
+		return nil, err
+	}
+	events, err := q.g.logDB.FilterEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := charge(ctx, uint64(len(events))); err != nil {
+		return nil, err
+	}
+	resolvers := make([]*eventResolver, len(events))
+	for i, ev := range events {
+		resolvers[i] = newEventResolver(q.g, ev)
+	}
+	return resolvers, nil
+}
+
+func (q *queryResolver) Transfers(ctx context.Context, args struct{ Filter *transferFilterInput }) ([]*transferResolver, error) {
+	filter, err := convertTransferFilter(args.Filter, remaining(ctx)+1)
+	if err != nil {
+		return nil, err
+	}
+	transfers, err := q.g.logDB.FilterTransfers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := charge(ctx, uint64(len(transfers))); err != nil {
+		return nil, err
+	}
+	resolvers := make([]*transferResolver, len(transfers))
+	for i, tr := range transfers {
+		resolvers[i] = newTransferResolver(q.g, tr)
+	}
+	return resolvers, nil
+}
+
+type blockResolver struct {
+	g   *GraphQL
+	blk *block.Block
+}
+
+func (b *blockResolver) Number() Long            { return Long(b.blk.Header().Number()) }
+func (b *blockResolver) ID() string              { return b.blk.Header().ID().String() }
+func (b *blockResolver) ParentID() string        { return b.blk.Header().ParentID().String() }
+func (b *blockResolver) Timestamp() Long         { return Long(b.blk.Header().Timestamp()) }
+func (b *blockResolver) GasLimit() Long          { return Long(b.blk.Header().GasLimit()) }
+func (b *blockResolver) GasUsed() Long           { return Long(b.blk.Header().GasUsed()) }
+func (b *blockResolver) TotalScore() Long        { return Long(b.blk.Header().TotalScore()) }
+func (b *blockResolver) Beneficiary() string     { return b.blk.Header().Beneficiary().String() }
+func (b *blockResolver) TxsRoot() string         { return b.blk.Header().TxsRoot().String() }
+func (b *blockResolver) StateRoot() string       { return b.blk.Header().StateRoot().String() }
+func (b *blockResolver) ReceiptsRoot() string    { return b.blk.Header().ReceiptsRoot().String() }
+func (b *blockResolver) Size() Long              { return Long(b.blk.Size()) }
+func (b *blockResolver) TransactionCount() int32 { return int32(len(b.blk.Transactions())) }
+
+func (b *blockResolver) Signer() (string, error) {
+	signer, err := b.blk.Header().Signer()
+	if err != nil {
+		return "", err
+	}
+	return signer.String(), nil
+}
+
+func (b *blockResolver) Parent(ctx context.Context) (*blockResolver, error) {
+	if b.blk.Header().Number() == 0 {
+		return nil, nil
+	}
+	return b.g.block(ctx, b.blk.Header().ParentID())
+}
+
+func (b *blockResolver) IsTrunk() (bool, error) {
+	header := b.blk.Header()
+	ancestorID, err := b.g.chain.GetAncestorBlockID(b.g.chain.BestBlock().Header().ID(), header.Number())
+	if err != nil {
+		return false, err
+	}
+	return ancestorID == header.ID(), nil
+}
+
+func (b *blockResolver) Transactions(ctx context.Context) ([]*txResolver, error) {
+	txs := b.blk.Transactions()
+	if err := charge(ctx, uint64(len(txs))); err != nil {
+		return nil, err
+	}
+	resolvers := make([]*txResolver, len(txs))
+	for i, t := range txs {
+		resolvers[i] = &txResolver{b.g, t, b.blk.Header(), uint64(i)}
+	}
+	return resolvers, nil
+}
+
+func (b *blockResolver) Account(ctx context.Context, args struct{ Address string }) (*accountResolver, error) {
+	return b.g.account(ctx, args.Address, b.blk.Header())
+}
+
+type txResolver struct {
+	g      *GraphQL
+	tx     *tx.Transaction
+	header *block.Header
+	index  uint64
+}
+
+func (t *txResolver) ID() string       { return t.tx.ID().String() }
+func (t *txResolver) ChainTag() int32  { return int32(t.tx.ChainTag()) }
+func (t *txResolver) Expiration() Long { return Long(t.tx.Expiration()) }
+func (t *txResolver) Gas() Long        { return Long(t.tx.Gas()) }
+func (t *txResolver) Nonce() string    { return hexutil.EncodeUint64(t.tx.Nonce()) }
+func (t *txResolver) Size() Long       { return Long(t.tx.Size()) }
+func (t *txResolver) Index() int32     { return int32(t.index) }
+
+func (t *txResolver) BlockRef() string {
+	br := t.tx.BlockRef()
+	return hexutil.Encode(br[:])
+}
+
+func (t *txResolver) DependsOn() *string {
+	dependsOn := t.tx.DependsOn()
+	return optionalString(dependsOn, dependsOn != nil)
+}
+
+func (t *txResolver) Origin() (string, error) {
+	signer, err := t.tx.Signer()
+	if err != nil {
+		return "", err
+	}
+	return signer.String(), nil
+}
+
+func (t *txResolver) Delegator() (*string, error) {
+	delegator, err := t.tx.Delegator()
+	if err != nil {
+		return nil, err
+	}
+	return optionalString(delegator, delegator != nil), nil
+}
+
+func (t *txResolver) Clauses() []*clauseResolver {
+	clauses := make([]*clauseResolver, len(t.tx.Clauses()))
+	for i, c := range t.tx.Clauses() {
+		clauses[i] = &clauseResolver{c}
+	}
+	return clauses
+}
+
+func (t *txResolver) Block(ctx context.Context) (*blockResolver, error) {
+	return t.g.block(ctx, t.header.ID())
+}
+
+func (t *txResolver) Receipt(ctx context.Context) (*receiptResolver, error) {
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	receipt, err := t.g.chain.GetTransactionReceipt(t.header.ID(), t.index)
+	if err != nil {
+		return nil, err
+	}
+	r, err := transactions.ConvertReceipt(receipt, t.header, t.tx)
+	if err != nil {
+		return nil, err
+	}
+	return &receiptResolver{r}, nil
+}
+
+type clauseResolver struct {
+	c *tx.Clause
+}
+
+func (c *clauseResolver) To() *string {
+	to := c.c.To()
+	return optionalString(to, to != nil)
+}
+
+func (c *clauseResolver) Value() string { return encodeBig(c.c.Value()) }
+func (c *clauseResolver) Data() string  { return hexutil.Encode(c.c.Data()) }
+
+type receiptResolver struct {
+	r *transactions.Receipt
+}
+
+func (r *receiptResolver) GasUsed() Long    { return Long(r.r.GasUsed) }
+func (r *receiptResolver) GasPayer() string { return r.r.GasPayer.String() }
+func (r *receiptResolver) Paid() string     { return encodeBig((*big.Int)(r.r.Paid)) }
+func (r *receiptResolver) Reward() string   { return encodeBig((*big.Int)(r.r.Reward)) }
+func (r *receiptResolver) Reverted() bool   { return r.r.Reverted }
+
+func (r *receiptResolver) RevertReason() *string {
+	if r.r.RevertReason == "" {
+		return nil
+	}
+	return &r.r.RevertReason
+}
+
+func (r *receiptResolver) Outputs() []*outputResolver {
+	outputs := make([]*outputResolver, len(r.r.Outputs))
+	for i, o := range r.r.Outputs {
+		outputs[i] = &outputResolver{o}
+	}
+	return outputs
+}
+
+type outputResolver struct {
+	o *transactions.Output
+}
+
+func (o *outputResolver) ContractAddress() *string {
+	return optionalString(o.o.ContractAddress, o.o.ContractAddress != nil)
+}
+
+func (o *outputResolver) Events() []*outputEventResolver {
+	events := make([]*outputEventResolver, len(o.o.Events))
+	for i, ev := range o.o.Events {
+		events[i] = &outputEventResolver{ev}
+	}
+	return events
+}
+
+func (o *outputResolver) Transfers() []*outputTransferResolver {
+	transfers := make([]*outputTransferResolver, len(o.o.Transfers))
+	for i, tr := range o.o.Transfers {
+		transfers[i] = &outputTransferResolver{tr}
+	}
+	return transfers
+}
+
+type outputEventResolver struct {
+	ev *transactions.Event
+}
+
+func (e *outputEventResolver) Address() string { return e.ev.Address.String() }
+func (e *outputEventResolver) Data() string    { return e.ev.Data }
+
+func (e *outputEventResolver) Topics() []string {
+	topics := make([]string, len(e.ev.Topics))
+	for i, topic := range e.ev.Topics {
+		topics[i] = topic.String()
+	}
+	return topics
+}
+
+type outputTransferResolver struct {
+	tr *transactions.Transfer
+}
+
+func (t *outputTransferResolver) Sender() string    { return t.tr.Sender.String() }
+func (t *outputTransferResolver) Recipient() string { return t.tr.Recipient.String() }
+func (t *outputTransferResolver) Amount() string    { return encodeBig((*big.Int)(t.tr.Amount)) }
+
+type accountResolver struct {
+	addr  polo.Address
+	state *state.State
+}
+
+func (a *accountResolver) Address() string { return a.addr.String() }
+
+func (a *accountResolver) Balance() (string, error) {
+	balance := a.state.GetBalance(a.addr)
+	if err := a.state.Err(); err != nil {
+		return "", err
+	}
+	return encodeBig(balance), nil
+}
+
+func (a *accountResolver) Code() (string, error) {
+	code := a.state.GetCode(a.addr)
+	if err := a.state.Err(); err != nil {
+		return "", err
+	}
+	return hexutil.Encode(code), nil
+}
+
+func (a *accountResolver) HasCode() (bool, error) {
+	hasCode := len(a.state.GetCode(a.addr)) > 0
+	if err := a.state.Err(); err != nil {
+		return false, err
+	}
+	return hasCode, nil
+}
+
+func (a *accountResolver) Storage(args struct{ Key string }) (string, error) {
+	key, err := polo.ParseBytes32(args.Key)
+	if err != nil {
+		return "", errors.WithMessage(err, "key")
+	}
+	value := a.state.GetStorage(a.addr, key)
+	if err := a.state.Err(); err != nil {
+		return "", err
+	}
+	return value.String(), nil
+}
+
+// logMeta resolves meta fields shared by events and transfers.
+type logMeta struct {
+	g           *GraphQL
+	blockID     polo.Bytes32
+	blockNumber uint32
+	blockTime   uint64
+	txID        polo.Bytes32
+	txOrigin    polo.Address
+}
+
+func (m *logMeta) BlockID() string      { return m.blockID.String() }
+func (m *logMeta) BlockNumber() Long    { return Long(m.blockNumber) }
+func (m *logMeta) BlockTimestamp() Long { return Long(m.blockTime) }
+func (m *logMeta) TxID() string         { return m.txID.String() }
+func (m *logMeta) TxOrigin() string     { return m.txOrigin.String() }
+
+func (m *logMeta) Block(ctx context.Context) (*blockResolver, error) {
+	return m.g.block(ctx, m.blockID)
+}
+
+func (m *logMeta) Transaction(ctx context.Context) (*txResolver, error) {
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	meta, err := m.g.chain.GetTransactionMeta(m.txID, m.blockID)
+	if err != nil {
+		return nil, err
+	}
+	return m.g.transaction(meta.BlockID, meta.Index)
+}
+
+type eventResolver struct {
+	*logMeta
+	ev *logdb.Event
+}
+
+func newEventResolver(g *GraphQL, ev *logdb.Event) *eventResolver {
+	return &eventResolver{&logMeta{g, ev.BlockID, ev.BlockNumber, ev.BlockTime, ev.TxID, ev.TxOrigin}, ev}
+}
+
+func (e *eventResolver) Address() string { return e.ev.Address.String() }
+func (e *eventResolver) Data() string    { return hexutil.Encode(e.ev.Data) }
+
+func (e *eventResolver) Topics() []string {
+	topics := []string{}
+	for _, topic := range e.ev.Topics {
+		if topic != nil {
+			topics = append(topics, topic.String())
+		}
+	}
+	return topics
+}
+
+type transferResolver struct {
+	*logMeta
+	tr *logdb.Transfer
+}
+
+func newTransferResolver(g *GraphQL, tr *logdb.Transfer) *transferResolver {
+	return &transferResolver{&logMeta{g, tr.BlockID, tr.BlockNumber, tr.BlockTime, tr.TxID, tr.TxOrigin}, tr}
+}
+
+func (t *transferResolver) Sender() string    { return t.tr.Sender.String() }
+func (t *transferResolver) Recipient() string { return t.tr.Recipient.String() }
+func (t *transferResolver) Amount() string    { return encodeBig(t.tr.Amount) }
+func (t *transferResolver) Reverted() bool    { return t.tr.Reverted }
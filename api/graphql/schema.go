@@ -0,0 +1,178 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package graphql
+
+// schema the GraphQL schema. Hashes, addresses and byte arrays are 0x-prefixed hex strings,
+// and big numbers (balances, amounts) are hex strings, as REST APIs are.
+const schema = `
+schema {
+	query: Query
+}
+
+# Long is a 64 bit unsigned integer, accepted as number or decimal/hex string.
+scalar Long
+
+type Query {
+	# block by revision, which is "best", a block number or a block ID, "best" by default.
+	block(revision: String): Block
+	# trunk blocks in the number range, inclusive.
+	blocks(from: Long!, to: Long!): [Block!]!
+	# transaction on trunk by ID.
+	transaction(id: String!): Transaction
+	# account at revision, "best" by default.
+	account(address: String!, revision: String): Account!
+	events(filter: EventFilter!): [Event!]!
+	transfers(filter: TransferFilter!): [Transfer!]!
+}
+
+type Block {
+	number: Long!
+	id: String!
+	parentID: String!
+	parent: Block
+	timestamp: Long!
+	gasLimit: Long!
+	gasUsed: Long!
+	totalScore: Long!
+	beneficiary: String!
+	signer: String!
+	txsRoot: String!
+	stateRoot: String!
+	receiptsRoot: String!
+	size: Long!
+	isTrunk: Boolean!
+	transactionCount: Int!
+	transactions: [Transaction!]!
+	# account at this block.
+	account(address: String!): Account!
+}
+
+type Transaction {
+	id: String!
+	chainTag: Int!
+	blockRef: String!
+	expiration: Long!
+	gas: Long!
+	nonce: String!
+	dependsOn: String
+	origin: String!
+	delegator: String
+	size: Long!
+	clauses: [Clause!]!
+	index: Int!
+	block: Block!
+	receipt: Receipt!
+}
+
+type Clause {
+	to: String
+	value: String!
+	data: String!
+}
+
+type Receipt {
+	gasUsed: Long!
+	gasPayer: String!
+	paid: String!
+	reward: String!
+	reverted: Boolean!
+	revertReason: String
+	outputs: [Output!]!
+}
+
+type Output {
+	contractAddress: String
+	events: [OutputEvent!]!
+	transfers: [OutputTransfer!]!
+}
+
+type OutputEvent {
+	address: String!
+	topics: [String!]!
+	data: String!
+}
+
+type OutputTransfer {
+	sender: String!
+	recipient: String!
+	amount: String!
+}
+
+type Account {
+	address: String!
+	balance: String!
+	code: String!
+	hasCode: Boolean!
+	storage(key: String!): String!
+}
+
+input Range {
+	# "block" or "time", "block" by default.
+	unit: String
+	from: Long!
+	to: Long!
+}
+
+input Options {
+	offset: Long
+	limit: Long
+}
+
+input EventCriteria {
+	address: String
+	topic0: String
+	topic1: String
+	topic2: String
+	topic3: String
+	topic4: String
+}
+
+input EventFilter {
+	criteriaSet: [EventCriteria!]
+	range: Range
+	options: Options
+	# "asc" or "desc", "asc" by default.
+	order: String
+}
+
+type Event {
+	address: String!
+	topics: [String!]!
+	data: String!
+	blockID: String!
+	blockNumber: Long!
+	blockTimestamp: Long!
+	txID: String!
+	txOrigin: String!
+	block: Block!
+	transaction: Transaction!
+}
+
+input TransferCriteria {
+	txOrigin: String
+	sender: String
+	recipient: String
+}
+
+input TransferFilter {
+	txID: String
+	criteriaSet: [TransferCriteria!]
+	range: Range
+	options: Options
+	order: String
+}
+
+type Transfer {
+	sender: String!
+	recipient: String!
+	amount: String!
+	reverted: Boolean!
+	blockID: String!
+	blockNumber: Long!
+	blockTimestamp: Long!
+	txID: String!
+	txOrigin: String!
+	block: Block!
+	transaction: Transaction!
+}
+`
@@ -0,0 +1,222 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/HiNounou029/nounouchain/nounou/logdb"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/pkg/errors"
+)
+
+// Long the Long scalar.
+type Long uint64
+
+// ImplementsGraphQLType maps Long to the Long scalar.
+func (Long) ImplementsGraphQLType(name string) bool {
+	return name == "Long"
+}
+
+// UnmarshalGraphQL decodes Long from literal or variable.
+func (l *Long) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case int32:
+		if input < 0 {
+			return errors.New("negative value")
+		}
+		*l = Long(input)
+	case float64:
+		if input < 0 || input > math.MaxUint64 || input != math.Trunc(input) {
+			return fmt.Errorf("invalid value %v", input)
+		}
+		*l = Long(input)
+	case string:
+		n, err := strconv.ParseUint(input, 0, 64)
+		if err != nil {
+			return err
+		}
+		*l = Long(n)
+	default:
+		return fmt.Errorf("unexpected type %T for Long", input)
+	}
+	return nil
+}
+
+// MarshalJSON encodes Long as number.
+func (l Long) MarshalJSON() ([]byte, error) {
+	return json.Marshal(uint64(l))
+}
+
+type rangeInput struct {
+	Unit *string
+	From Long
+	To   Long
+}
+
+type optionsInput struct {
+	Offset *Long
+	Limit  *Long
+}
+
+type eventCriteriaInput struct {
+	Address *string
+	Topic0  *string
+	Topic1  *string
+	Topic2  *string
+	Topic3  *string
+	Topic4  *string
+}
+
+type eventFilterInput struct {
+	CriteriaSet *[]*eventCriteriaInput
+	Range       *rangeInput
+	Options     *optionsInput
+	Order       *string
+}
+
+type transferCriteriaInput struct {
+	TxOrigin  *string
+	Sender    *string
+	Recipient *string
+}
+
+type transferFilterInput struct {
+	TxID        *string
+	CriteriaSet *[]*transferCriteriaInput
+	Range       *rangeInput
+	Options     *optionsInput
+	Order       *string
+}
+
+func parseAddress(s *string, name string) (*polo.Address, error) {
+	if s == nil {
+		return nil, nil
+	}
+	addr, err := polo.ParseAddress(*s)
+	if err != nil {
+		return nil, errors.WithMessage(err, name)
+	}
+	return &addr, nil
+}
+
+func parseBytes32(s *string, name string) (*polo.Bytes32, error) {
+	if s == nil {
+		return nil, nil
+	}
+	b, err := polo.ParseBytes32(*s)
+	if err != nil {
+		return nil, errors.WithMessage(err, name)
+	}
+	return &b, nil
+}
+
+func convertRange(r *rangeInput) (*logdb.Range, error) {
+	if r == nil {
+		return nil, nil
+	}
+	unit := logdb.Block
+	if r.Unit != nil {
+		unit = logdb.RangeType(*r.Unit)
+		if unit != logdb.Block && unit != logdb.Time {
+			return nil, fmt.Errorf("range.unit: invalid value %v", *r.Unit)
+		}
+	}
+	return &logdb.Range{Unit: unit, From: uint64(r.From), To: uint64(r.To)}, nil
+}
+
+func convertOrder(order *string) (logdb.Order, error) {
+	if order == nil {
+		return logdb.ASC, nil
+	}
+	o := logdb.Order(*order)
+	if o != logdb.ASC && o != logdb.DESC {
+		return "", fmt.Errorf("order: invalid value %v", *order)
+	}
+	return o, nil
+}
+
+// convertOptions converts options, and caps the limit to maxLimit.
+func convertOptions(opts *optionsInput, maxLimit uint64) *logdb.Options {
+	options := &logdb.Options{Limit: maxLimit}
+	if opts != nil {
+		if opts.Offset != nil {
+			options.Offset = uint64(*opts.Offset)
+		}
+		if opts.Limit != nil && uint64(*opts.Limit) < maxLimit {
+			options.Limit = uint64(*opts.Limit)
+		}
+	}
+	return options
+}
+
+func convertEventFilter(filter *eventFilterInput, maxLimit uint64) (*logdb.EventFilter, error) {
+	rng, err := convertRange(filter.Range)
+	if err != nil {
+		return nil, err
+	}
+	order, err := convertOrder(filter.Order)
+	if err != nil {
+		return nil, err
+	}
+	f := &logdb.EventFilter{
+		Range:   rng,
+		Options: convertOptions(filter.Options, maxLimit),
+		Order:   order,
+	}
+	if filter.CriteriaSet != nil {
+		for i, c := range *filter.CriteriaSet {
+			name := fmt.Sprintf("criteriaSet[%v]", i)
+			criteria := &logdb.EventCriteria{}
+			if criteria.Address, err = parseAddress(c.Address, name+".address"); err != nil {
+				return nil, err
+			}
+			for j, topic := range []*string{c.Topic0, c.Topic1, c.Topic2, c.Topic3, c.Topic4} {
+				if criteria.Topics[j], err = parseBytes32(topic, fmt.Sprintf("%v.topic%v", name, j)); err != nil {
+					return nil, err
+				}
+			}
+			f.CriteriaSet = append(f.CriteriaSet, criteria)
+		}
+	}
+	return f, nil
+}
+
+func convertTransferFilter(filter *transferFilterInput, maxLimit uint64) (*logdb.TransferFilter, error) {
+	rng, err := convertRange(filter.Range)
+	if err != nil {
+		return nil, err
+	}
+	order, err := convertOrder(filter.Order)
+	if err != nil {
+		return nil, err
+	}
+	f := &logdb.TransferFilter{
+		Range:   rng,
+		Options: convertOptions(filter.Options, maxLimit),
+		Order:   order,
+	}
+	if f.TxID, err = parseBytes32(filter.TxID, "txID"); err != nil {
+		return nil, err
+	}
+	if filter.CriteriaSet != nil {
+		for i, c := range *filter.CriteriaSet {
+			name := fmt.Sprintf("criteriaSet[%v]", i)
+			criteria := &logdb.TransferCriteria{}
+			if criteria.TxOrigin, err = parseAddress(c.TxOrigin, name+".txOrigin"); err != nil {
+				return nil, err
+			}
+			if criteria.Sender, err = parseAddress(c.Sender, name+".sender"); err != nil {
+				return nil, err
+			}
+			if criteria.Recipient, err = parseAddress(c.Recipient, name+".recipient"); err != nil {
+				return nil, err
+			}
+			f.CriteriaSet = append(f.CriteriaSet, criteria)
+		}
+	}
+	return f, nil
+}
@@ -0,0 +1,29 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/HiNounou029/nounouchain/common/metric"
+	"github.com/gorilla/mux"
+)
+
+var requestDuration = metric.NewHistogramVec("api_request_duration_seconds", "duration of API requests by route", nil, "route", "method")
+
+// handleMetrics measures latency of requests per route template, e.g. /blocks/{revision}.
+func handleMetrics(router *mux.Router, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// unmatched requests are aggregated, to bound cardinality of labels
+		route, method := "unmatched", "other"
+		var match mux.RouteMatch
+		if router.Match(req, &match) && match.Route != nil {
+			if tmpl, err := match.Route.GetPathTemplate(); err == nil {
+				route, method = tmpl, req.Method
+			}
+		}
+		defer requestDuration.With(route, method).ObserveSince(time.Now())
+		h.ServeHTTP(w, req)
+	})
+}
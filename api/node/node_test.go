@@ -43,11 +43,15 @@ func initCommServer(t *testing.T) {
 		t.Fatal(err)
 	}
 	chain, _ := chain.New(db, b)
-	comm := comm.New(chain, txpool.New(chain, stateC, txpool.Options{
+	pool, err := txpool.New(chain, stateC, txpool.Options{
 		Limit:           10000,
 		LimitPerAccount: 16,
 		MaxLifetime:     10 * time.Minute,
-		}), "", false, nil)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	comm := comm.New(chain, pool, "", false, nil)
 	router := mux.NewRouter()
 	node.New(comm).Mount(router, "/node")
 	ts = httptest.NewServer(router)
@@ -0,0 +1,88 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package subscriptions
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/HiNounou029/nounouchain/core/txpool"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// max count of pending tx messages buffered for a subscriber
+const pendingTxBufferLimit = 4096
+
+var errPendingTxOverflow = errors.New("too many pending tx messages unread, subscriber too slow")
+
+// pendingTxReader reads txs events of the pool.
+// Events are relayed into a bounded buffer, so that a slow subscriber never blocks the pool.
+type pendingTxReader struct {
+	filter *PendingTxFilter
+	sub    event.Subscription
+	notify chan struct{}
+
+	lock     sync.Mutex
+	msgs     []interface{}
+	overflow bool
+}
+
+func newPendingTxReader(txPool *txpool.TxPool, filter *PendingTxFilter) *pendingTxReader {
+	r := &pendingTxReader{
+		filter: filter,
+		notify: make(chan struct{}, 1),
+	}
+	ch := make(chan *txpool.TxEvent)
+	r.sub = txPool.SubscribeTxEvent(ch)
+	go func() {
+		for {
+			select {
+			case txEv := <-ch:
+				r.relay(txEv)
+			case <-r.sub.Err():
+				return
+			}
+		}
+	}()
+	return r
+}
+
+func (r *pendingTxReader) relay(txEv *txpool.TxEvent) {
+	origin, err := txEv.Tx.Signer()
+	if err != nil || !r.filter.Match(txEv.Tx, origin) {
+		return
+	}
+	r.lock.Lock()
+	if len(r.msgs) >= pendingTxBufferLimit {
+		r.overflow = true
+	} else {
+		r.msgs = append(r.msgs, convertPendingTx(txEv, origin))
+	}
+	r.lock.Unlock()
+
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (r *pendingTxReader) Read() ([]interface{}, bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.overflow {
+		return nil, false, errPendingTxOverflow
+	}
+	msgs := r.msgs
+	r.msgs = nil
+	return msgs, false, nil
+}
+
+// Notify returns the channel signaled when new messages available.
+func (r *pendingTxReader) Notify() <-chan struct{} {
+	return r.notify
+}
+
+// Close unsubscribes tx events.
+func (r *pendingTxReader) Close() {
+	r.sub.Unsubscribe()
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/HiNounou029/nounouchain/polo"
 	"github.com/HiNounou029/nounouchain/core/block"
 	"github.com/HiNounou029/nounouchain/core/chain"
+	"github.com/HiNounou029/nounouchain/core/txpool"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"github.com/inconshreveable/log15"
@@ -19,6 +20,7 @@ import (
 type Subscriptions struct {
 	backtraceLimit uint32
 	chain          *chain.Chain
+	txPool         *txpool.TxPool
 	upgrader       *websocket.Upgrader
 	done           chan struct{}
 	wg             sync.WaitGroup
@@ -28,14 +30,20 @@ type msgReader interface {
 	Read() (msgs []interface{}, hasMore bool, err error)
 }
 
+// msgNotifier is implemented by readers of messages not driven by new blocks.
+type msgNotifier interface {
+	Notify() <-chan struct{}
+}
+
 var (
 	log = log15.New("pkg", "subscriptions")
 )
 
-func New(chain *chain.Chain, allowedOrigins []string, backtraceLimit uint32) *Subscriptions {
+func New(chain *chain.Chain, txPool *txpool.TxPool, allowedOrigins []string, backtraceLimit uint32) *Subscriptions {
 	return &Subscriptions{
 		backtraceLimit: backtraceLimit,
 		chain:          chain,
+		txPool:         txPool,
 		upgrader: &websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				origin := r.Header.Get("Origin")
@@ -152,6 +160,22 @@ func (s *Subscriptions) handleTransactionReader(w http.ResponseWriter, req *http
 	return newTransactionReader(s.chain, position, transactionFilter), nil
 }
 
+func (s *Subscriptions) handlePendingTxReader(w http.ResponseWriter, req *http.Request) (*pendingTxReader, error) {
+	origin, err := parseAddress(req.URL.Query().Get("origin"))
+	if err != nil {
+		return nil, utils.BadRequest(errors.WithMessage(err, "origin"))
+	}
+	to, err := parseAddress(req.URL.Query().Get("to"))
+	if err != nil {
+		return nil, utils.BadRequest(errors.WithMessage(err, "to"))
+	}
+	pendingTxFilter := &PendingTxFilter{
+		Origin: origin,
+		To:     to,
+	}
+	return newPendingTxReader(s.txPool, pendingTxFilter), nil
+}
+
 func (s *Subscriptions) handleSubject(w http.ResponseWriter, req *http.Request) error {
 	s.wg.Add(1)
 	defer s.wg.Done()
@@ -181,6 +205,13 @@ func (s *Subscriptions) handleSubject(w http.ResponseWriter, req *http.Request)
 		if reader, err = s.handleTransactionReader(w, req); err != nil {
 			return err
 		}
+	case "pendingtx":
+		pendingTxReader, err := s.handlePendingTxReader(w, req)
+		if err != nil {
+			return err
+		}
+		defer pendingTxReader.Close()
+		reader = pendingTxReader
 	default:
 		return utils.HTTPError(errors.New("not found"), http.StatusNotFound)
 	}
@@ -226,6 +257,11 @@ func (s *Subscriptions) pipe(conn *websocket.Conn, reader msgReader) error {
 		}
 	}()
 	ticker := s.chain.NewTicker()
+	// nil channel never signaled
+	var notify <-chan struct{}
+	if notifier, ok := reader.(msgNotifier); ok {
+		notify = notifier.Notify()
+	}
 	for {
 		msgs, hasMore, err := reader.Read()
 		if err != nil {
@@ -243,6 +279,7 @@ func (s *Subscriptions) pipe(conn *websocket.Conn, reader msgReader) error {
 			case <-closed:
 				return nil
 			case <-ticker.C():
+			case <-notify:
 			}
 		} else {
 			select {
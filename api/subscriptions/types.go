@@ -3,10 +3,12 @@
 package subscriptions
 
 import (
+	"github.com/HiNounou029/nounouchain/api/transactions"
 	"github.com/HiNounou029/nounouchain/polo"
 	"github.com/HiNounou029/nounouchain/core/block"
 	"github.com/HiNounou029/nounouchain/core/chain"
 	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/core/txpool"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
 )
@@ -208,3 +210,65 @@ func (tf *TranscationFilter) Match(tx *tx.Transaction) bool {
 	}
 	return true
 }
+
+// PendingTxFilter contains options for pending tx filtering.
+type PendingTxFilter struct {
+	Origin *polo.Address // who sends the tx
+	To     *polo.Address // recipient of any clause
+}
+
+// Match returns whether the tx matches filter
+func (pf *PendingTxFilter) Match(trx *tx.Transaction, origin polo.Address) bool {
+	if (pf.Origin != nil) && (*pf.Origin != origin) {
+		return false
+	}
+	if pf.To == nil {
+		return true
+	}
+	for _, clause := range trx.Clauses() {
+		if to := clause.To(); to != nil && *to == *pf.To {
+			return true
+		}
+	}
+	return false
+}
+
+// types of pending tx message
+const (
+	PendingTxAdded      = "added"      // added into pool, not executable yet or unknown
+	PendingTxExecutable = "executable" // executable, when added or became
+	PendingTxRemoved    = "removed"    // removed from pool, packed in block, expired or dropped
+)
+
+// PendingTxMessage tx in pool piped by websocket
+type PendingTxMessage struct {
+	Type    string               `json:"type"`
+	ID      polo.Bytes32         `json:"id"`
+	Origin  polo.Address         `json:"origin"`
+	Gas     uint64               `json:"gas"`
+	Clauses transactions.Clauses `json:"clauses"`
+}
+
+func convertPendingTx(txEv *txpool.TxEvent, origin polo.Address) *PendingTxMessage {
+	msgType := PendingTxAdded
+	if txEv.Removed {
+		msgType = PendingTxRemoved
+	} else if txEv.Executable != nil && *txEv.Executable {
+		msgType = PendingTxExecutable
+	}
+	clauses := make(transactions.Clauses, len(txEv.Tx.Clauses()))
+	for i, c := range txEv.Tx.Clauses() {
+		clauses[i] = transactions.Clause{
+			To:    c.To(),
+			Value: math.HexOrDecimal256(*c.Value()),
+			Data:  hexutil.Encode(c.Data()),
+		}
+	}
+	return &PendingTxMessage{
+		Type:    msgType,
+		ID:      txEv.Tx.ID(),
+		Origin:  origin,
+		Gas:     txEv.Tx.Gas(),
+		Clauses: clauses,
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package subscriptions_test
+
+import (
+	"testing"
+
+	"github.com/HiNounou029/nounouchain/api/subscriptions"
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingTxFilter(t *testing.T) {
+	var (
+		origin = polo.BytesToAddress([]byte("origin"))
+		other  = polo.BytesToAddress([]byte("other"))
+		to1    = polo.BytesToAddress([]byte("to1"))
+		to2    = polo.BytesToAddress([]byte("to2"))
+	)
+	trx := new(tx.Builder).
+		Clause(tx.NewClause(nil)).
+		Clause(tx.NewClause(&to1)).
+		Clause(tx.NewClause(&to2)).
+		Build()
+
+	tests := []struct {
+		filter   subscriptions.PendingTxFilter
+		origin   polo.Address
+		expected bool
+	}{
+		{subscriptions.PendingTxFilter{}, origin, true},
+		{subscriptions.PendingTxFilter{Origin: &origin}, origin, true},
+		{subscriptions.PendingTxFilter{Origin: &origin}, other, false},
+		{subscriptions.PendingTxFilter{To: &to2}, origin, true},
+		{subscriptions.PendingTxFilter{To: &other}, origin, false},
+		{subscriptions.PendingTxFilter{Origin: &origin, To: &to1}, origin, true},
+		{subscriptions.PendingTxFilter{Origin: &origin, To: &to1}, other, false},
+		{subscriptions.PendingTxFilter{Origin: &origin, To: &other}, origin, false},
+	}
+	for i, tt := range tests {
+		assert.Equal(t, tt.expected, tt.filter.Match(trx, tt.origin), "case %v", i)
+	}
+
+	// contract creation has no clause target
+	creation := new(tx.Builder).Clause(tx.NewClause(nil)).Build()
+	assert.False(t, (&subscriptions.PendingTxFilter{To: &to1}).Match(creation, origin))
+	assert.True(t, (&subscriptions.PendingTxFilter{Origin: &origin}).Match(creation, origin))
+}
@@ -4,15 +4,22 @@ package transactions
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"time"
 
+	"github.com/HiNounou029/nounouchain/api/auth"
 	"github.com/HiNounou029/nounouchain/api/utils"
+	"github.com/HiNounou029/nounouchain/common/metric"
+	"github.com/HiNounou029/nounouchain/common/ratelimit"
+	"github.com/HiNounou029/nounouchain/consensus"
 	"github.com/HiNounou029/nounouchain/polo"
 	"github.com/HiNounou029/nounouchain/core/chain"
 	"github.com/HiNounou029/nounouchain/core/tx"
 	"github.com/HiNounou029/nounouchain/core/txpool"
+	"github.com/HiNounou029/nounouchain/state"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/gorilla/mux"
@@ -22,17 +29,35 @@ import (
 
 var (
 	log = log15.New()
+
+	rateLimitedTxsCounter = metric.NewCounter("api_rate_limited_txs", "txs rejected due to client IP rate limit")
+
+	errReplayBusy = errors.New("too many replays in progress")
+)
+
+const (
+	// maxReplays limits concurrent receipt replays.
+	maxReplays = 4
+	// replayTimeout limits the duration of a single receipt replay.
+	replayTimeout = 5 * time.Second
 )
 
 type Transactions struct {
-	chain *chain.Chain
-	pool  *txpool.TxPool
+	chain     *chain.Chain
+	pool      *txpool.TxPool
+	limiter   *ratelimit.Limiter
+	consensus *consensus.Consensus
+	replays   chan struct{}
 }
 
-func New(chain *chain.Chain, pool *txpool.TxPool) *Transactions {
+// New creates transactions API. The limiter limits txs sent per client IP, nil means no limitation.
+func New(chain *chain.Chain, stateCreator *state.Creator, pool *txpool.TxPool, limiter *ratelimit.Limiter) *Transactions {
 	return &Transactions{
 		chain,
 		pool,
+		limiter,
+		consensus.New(chain, stateCreator),
+		make(chan struct{}, maxReplays),
 	}
 }
 
@@ -85,8 +110,9 @@ func (t *Transactions) getTransactionByID(txID polo.Bytes32, blockID polo.Bytes3
 	return convertTransaction(tx, h, txMeta.Index, clauses)
 }
 
-//GetTransactionReceiptByID get tx's receipt
-func (t *Transactions) getTransactionReceiptByID(txID polo.Bytes32, blockID polo.Bytes32) (*Receipt, error) {
+// GetTransactionReceiptByID get tx's receipt.
+// If replay is set, revert data not stored in the receipt is recomputed by replaying the block.
+func (t *Transactions) getTransactionReceiptByID(ctx context.Context, txID polo.Bytes32, blockID polo.Bytes32, replay bool) (*Receipt, error) {
 	txMeta, err := t.chain.GetTransactionMeta(txID, blockID)
 	if err != nil {
 		if t.chain.IsNotFound(err) {
@@ -106,9 +132,71 @@ func (t *Transactions) getTransactionReceiptByID(txID polo.Bytes32, blockID polo
 	if err != nil {
 		return nil, err
 	}
-	return ConvertReceipt(receipt, h, tx)
+	converted, err := ConvertReceipt(receipt, h, tx)
+	if err != nil {
+		return nil, err
+	}
+	if replay && receipt.Reverted && len(receipt.RevertData) == 0 {
+		// not stored before the fork, recompute it
+		data, err := t.replayRevertData(ctx, txMeta.BlockID, txMeta.Index)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > 0 {
+			converted.setRevertData(data)
+		}
+	}
+	return converted, nil
+}
+
+// replayRevertData replays the block up to the tx, and returns revert data of the failed clause.
+// Replays are limited in concurrency and duration.
+func (t *Transactions) replayRevertData(ctx context.Context, blockID polo.Bytes32, txIndex uint64) ([]byte, error) {
+	select {
+	case t.replays <- struct{}{}:
+		defer func() { <-t.replays }()
+	default:
+		return nil, utils.HTTPError(errReplayBusy, http.StatusServiceUnavailable)
+	}
+	ctx, cancel := context.WithTimeout(ctx, replayTimeout)
+	defer cancel()
+
+	blk, err := t.chain.GetBlock(blockID)
+	if err != nil {
+		return nil, err
+	}
+	rt, err := t.consensus.NewRuntimeForReplay(blk.Header())
+	if err != nil {
+		return nil, err
+	}
+	txs := blk.Transactions()
+	for _, tx := range txs[:txIndex] {
+		if ctx.Err() != nil {
+			return nil, utils.HTTPError(ctx.Err(), http.StatusServiceUnavailable)
+		}
+		if _, err := rt.ExecuteTransaction(tx); err != nil {
+			return nil, err
+		}
+	}
+	executor, err := rt.PrepareTransaction(txs[txIndex])
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	for executor.HasNextClause() {
+		_, output, err := executor.NextClause()
+		if err != nil {
+			return nil, err
+		}
+		data = output.RevertData()
+	}
+	return data, nil
 }
 func (t *Transactions) handleSendTransaction(w http.ResponseWriter, req *http.Request) error {
+	if !t.limiter.Allow(utils.ClientIP(req)) {
+		rateLimitedTxsCounter.Inc()
+		return utils.HTTPError(errors.New("rate limit exceeded"), http.StatusTooManyRequests)
+	}
 	data, err := ioutil.ReadAll(req.Body)
 	if err != nil {
 		return err
@@ -132,6 +220,7 @@ func (t *Transactions) handleSendTransaction(w http.ResponseWriter, req *http.Re
 
 			return err
 		}
+		log.Debug("tx submitted", "id", tx.ID(), "remote", utils.ClientIP(req), "client", auth.ClientSubject(req))
 
 		return utils.WriteJSON(w, map[string]string{
 			"id": tx.ID().String(),
@@ -235,6 +324,10 @@ func (t *Transactions) handleGetTransactionReceiptByID(w http.ResponseWriter, re
 	if err != nil {
 		return utils.BadRequest(errors.WithMessage(err, "head"))
 	}
+	replay := req.URL.Query().Get("replay")
+	if replay != "" && replay != "false" && replay != "true" {
+		return utils.BadRequest(errors.WithMessage(errors.New("should be boolean"), "replay"))
+	}
 	h, err := t.chain.GetBlockHeader(head)
 	if err != nil {
 		if t.chain.IsNotFound(err) {
@@ -242,7 +335,7 @@ func (t *Transactions) handleGetTransactionReceiptByID(w http.ResponseWriter, re
 		}
 		return err
 	}
-	receipt, err := t.getTransactionReceiptByID(txID, h.ID())
+	receipt, err := t.getTransactionReceiptByID(req.Context(), txID, h.ID(), replay == "true")
 	if err != nil {
 		return err
 	}
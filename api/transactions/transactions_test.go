@@ -69,6 +69,20 @@ func getTxReceipt(t *testing.T) {
 		t.Fatal(err)
 	}
 	assert.Equal(t, uint64(receipt.GasUsed), transaction.Gas(), "gas should be equal")
+
+	r = httpGet(t, ts.URL+"/transactions/"+transaction.ID().String()+"/receipt?replay=true")
+	if err := json.Unmarshal(r, &receipt); err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, receipt.Reverted)
+	assert.Empty(t, receipt.RevertData, "nothing to replay")
+
+	res, err := http.Get(ts.URL + "/transactions/" + transaction.ID().String() + "/receipt?replay=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode, "replay should be boolean")
 }
 
 func senTx(t *testing.T) {
@@ -158,7 +172,7 @@ func initTransactionServer(t *testing.T) {
 		}
 		header = new(block.Builder).ParentID(header.ID()).Build().Header()
 		if err := logDB.Prepare(header).ForTransaction(polo.Bytes32{}, from).
-			Insert(nil, tx.Transfers{transLog}).Commit(); err != nil {
+			Insert(nil, tx.Transfers{transLog}, false).Commit(); err != nil {
 			t.Fatal(err)
 		}
 	}
@@ -204,7 +218,11 @@ func initTransactionServer(t *testing.T) {
 		t.Fatal(err)
 	}
 	router := mux.NewRouter()
-	transactions.New(c, txpool.New(c, stateC, txpool.Options{Limit: 10000, LimitPerAccount: 16, MaxLifetime: 10 * time.Minute})).Mount(router, "/transactions")
+	pool, err := txpool.New(c, stateC, txpool.Options{Limit: 10000, LimitPerAccount: 16, MaxLifetime: 10 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transactions.New(c, stateC, pool, nil).Mount(router, "/transactions")
 	ts = httptest.NewServer(router)
 
 }
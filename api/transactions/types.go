@@ -10,6 +10,7 @@ import (
 	"github.com/HiNounou029/nounouchain/polo"
 	"github.com/HiNounou029/nounouchain/core/block"
 	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/vm"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -57,12 +58,14 @@ func hasKey(m map[string]interface{}, key string) bool {
 //Transaction transaction
 type Transaction struct {
 	ID           polo.Bytes32        `json:"id"`
+	Type         uint8               `json:"type"`
 	ChainTag     byte                `json:"chainTag"`
 	BlockRef     string              `json:"blockRef"`
 	Expiration   uint32              `json:"expiration"`
 	Clauses      Clauses             `json:"clauses"`
 	Gas          uint64              `json:"gas"`
 	Origin       polo.Address        `json:"origin"`
+	Delegator    *polo.Address       `json:"delegator"`
 	Nonce        math.HexOrDecimal64 `json:"nonce"`
 	DependsOn    *polo.Bytes32       `json:"dependsOn"`
 	Size         uint32              `json:"size"`
@@ -72,6 +75,7 @@ type Transaction struct {
 //Transaction transaction
 type PlainTransaction struct {
 	ID         polo.Bytes32         `json:"id"`
+	Type       uint8                `json:"type"`
 	ChainTag   byte                 `json:"chainTag"`
 	Expiration uint32               `json:"expiration"`
 	Gas        uint64               `json:"gas"`
@@ -84,6 +88,7 @@ type PlainTransaction struct {
 }
 
 type UnSignedTx struct {
+	Type         uint8               `json:"type"`
 	ChainTag     uint8               `json:"chainTag"`
 	BlockRef     string              `json:"blockRef"`
 	Expiration   uint32              `json:"expiration"`
@@ -91,10 +96,14 @@ type UnSignedTx struct {
 	Gas          uint64              `json:"gas"`
 	DependsOn    *polo.Bytes32       `json:"dependsOn"`
 	Nonce        math.HexOrDecimal64 `json:"nonce"`
+	Delegated    bool                `json:"delegated"`
 }
 
 func (ustx *UnSignedTx) decode() (*tx.Transaction, error) {
-	txBuilder := new(tx.Builder)
+	if !tx.IsTypeSupported(ustx.Type) {
+		return nil, errors.Errorf("type: unsupported %v", ustx.Type)
+	}
+	txBuilder := new(tx.Builder).Type(ustx.Type)
 	for _, clause := range ustx.Clauses {
 		data, err := hexutil.Decode(clause.Data)
 		if err != nil {
@@ -110,13 +119,22 @@ func (ustx *UnSignedTx) decode() (*tx.Transaction, error) {
 	var bf tx.BlockRef
 	copy(bf[:], blockRef[:])
 
-	return txBuilder.ChainTag(ustx.ChainTag).
+	var features tx.Features
+	features.SetDelegated(ustx.Delegated)
+
+	trx := txBuilder.ChainTag(ustx.ChainTag).
+		Features(features).
 		BlockRef(bf).
 		Expiration(ustx.Expiration).
 		Gas(ustx.Gas).
 		DependsOn(ustx.DependsOn).
 		Nonce(uint64(ustx.Nonce)).
-		Build(), nil
+		Build()
+	// fields not fit in the type are reported by encoding
+	if _, err := rlp.EncodeToBytes(trx); err != nil {
+		return nil, errors.WithMessage(err, "type")
+	}
+	return trx, nil
 }
 
 type SignedTx struct {
@@ -160,6 +178,10 @@ type PlainTx struct {
 	Plain string `json:"plain"`
 }
 
+func (plaintx *PlainTx) Decode() (*tx.Transaction, error) {
+	return plaintx.decode()
+}
+
 func (plaintx *PlainTx) decode() (*tx.Transaction, error) {
 	plaindata, err := hexutil.Decode(plaintx.Plain)
 	if err != nil {
@@ -248,6 +270,7 @@ func convertTransaction(tx *tx.Transaction, header *block.Header, txIndex uint64
 
 	if clauses == false {
 		t := &PlainTransaction{
+			Type:     tx.Type(),
 			ChainTag: tx.ChainTag(),
 			ID:       tx.ID(),
 			Origin:   signer,
@@ -265,11 +288,17 @@ func convertTransaction(tx *tx.Transaction, header *block.Header, txIndex uint64
 
 		return t, nil
 	} else {
+		delegator, err := tx.Delegator()
+		if err != nil {
+			return nil, err
+		}
 		br := tx.BlockRef()
 		t := &Transaction{
+			Type:         tx.Type(),
 			ChainTag:     tx.ChainTag(),
 			ID:           tx.ID(),
 			Origin:       signer,
+			Delegator:    delegator,
 			BlockRef:     hexutil.Encode(br[:]),
 			Expiration:   tx.Expiration(),
 			Nonce:        math.HexOrDecimal64(tx.Nonce()),
@@ -311,6 +340,9 @@ type Receipt struct {
 	Reverted bool                  `json:"reverted"`
 	Meta     LogMeta               `json:"meta"`
 	Outputs  []*Output             `json:"outputs"`
+
+	RevertData   string `json:"revertData,omitempty"`
+	RevertReason string `json:"revertReason,omitempty"`
 }
 
 // Output output of clause execution.
@@ -390,5 +422,13 @@ func ConvertReceipt(txReceipt *tx.Receipt, header *block.Header, tx *tx.Transact
 		}
 		receipt.Outputs[i] = otp
 	}
+	if len(txReceipt.RevertData) > 0 {
+		receipt.setRevertData(txReceipt.RevertData[0])
+	}
 	return receipt, nil
 }
+
+func (r *Receipt) setRevertData(data []byte) {
+	r.RevertData = hexutil.Encode(data)
+	r.RevertReason, _ = vm.DecodeRevertReason(data)
+}
@@ -4,6 +4,7 @@ package transfers
 
 import (
 	"context"
+	"math"
 	"net/http"
 
 	"github.com/HiNounou029/nounouchain/api/utils"
@@ -40,6 +41,14 @@ func (t *Transfers) handleFilterTransferLogs(w http.ResponseWriter, req *http.Re
 	if err := utils.ParseJSON(req.Body, &filter); err != nil {
 		return utils.BadRequest(errors.WithMessage(err, "body"))
 	}
+	if utils.AcceptsNDJSON(req) {
+		return t.stream(w, req, &filter)
+	}
+	options, err := utils.PageOptions(filter.Options)
+	if err != nil {
+		return err
+	}
+	filter.Options = options
 	tLogs, err := t.filter(req.Context(), &filter)
 	if err != nil {
 		return err
@@ -47,6 +56,41 @@ func (t *Transfers) handleFilterTransferLogs(w http.ResponseWriter, req *http.Re
 	return utils.WriteJSON(w, tLogs)
 }
 
+// stream writes all transfers matched in NDJSON, by pages queried with cursor.
+func (t *Transfers) stream(w http.ResponseWriter, req *http.Request, filter *logdb.TransferFilter) error {
+	offset, remaining := uint64(0), uint64(math.MaxUint64)
+	if filter.Options != nil {
+		offset, remaining = filter.Options.Offset, filter.Options.Limit
+	}
+	nw := utils.NewNDJSONWriter(w)
+	for remaining > 0 {
+		limit := remaining
+		if limit > utils.MaxPageSize {
+			limit = utils.MaxPageSize
+		}
+		filter.Options = &logdb.Options{Offset: offset, Limit: limit}
+		transfers, err := t.db.FilterTransfers(req.Context(), filter)
+		if err != nil {
+			return nw.Fail(err)
+		}
+		for _, transfer := range transfers {
+			if err := nw.Write(convertTransfer(transfer)); err != nil {
+				// client gone
+				return nil
+			}
+		}
+		nw.Flush()
+		if uint64(len(transfers)) < limit {
+			break
+		}
+		last := transfers[len(transfers)-1]
+		filter.Cursor = &logdb.Cursor{BlockNumber: last.BlockNumber, Index: last.Index}
+		offset = 0
+		remaining -= limit
+	}
+	return nil
+}
+
 func (t *Transfers) Mount(root *mux.Router, pathPrefix string) {
 	sub := root.PathPrefix(pathPrefix).Subrouter()
 
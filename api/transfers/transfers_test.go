@@ -10,8 +10,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/HiNounou029/nounouchain/api/transfers"
+	"github.com/HiNounou029/nounouchain/api/utils"
 	"github.com/HiNounou029/nounouchain/polo"
 	"github.com/HiNounou029/nounouchain/nounou/logdb"
 	"github.com/HiNounou029/nounouchain/core/block"
@@ -26,6 +28,7 @@ func TestTransfers(t *testing.T) {
 	initLogServer(t)
 	defer ts.Close()
 	getTransfers(t)
+	getTransfersByCursor(t)
 }
 
 func getTransfers(t *testing.T) {
@@ -58,6 +61,111 @@ func getTransfers(t *testing.T) {
 	assert.Equal(t, limit, len(tLogs), "should be `limit` transfers")
 }
 
+func getTransfersByCursor(t *testing.T) {
+	tf := &logdb.TransferFilter{
+		Options: &logdb.Options{Limit: 30},
+		Order:   logdb.DESC,
+	}
+	var all []*transfers.FilteredTransfer
+	for {
+		var page []*transfers.FilteredTransfer
+		if err := json.Unmarshal(httpPost(t, ts.URL+"/logs/transfer", tf), &page); err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, page...)
+		if len(page) < 30 {
+			break
+		}
+		tf.Cursor = &page[len(page)-1].Cursor
+	}
+	if assert.Len(t, all, 100) {
+		for i := 1; i < len(all); i++ {
+			assert.True(t, all[i-1].Meta.BlockNumber > all[i].Meta.BlockNumber, "in order without duplicates")
+		}
+	}
+
+	tf.Options.Limit = utils.MaxPageSize + 1
+	data, _ := json.Marshal(tf)
+	res, err := http.Post(ts.URL+"/logs/transfer", "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode, "page size exceeded")
+}
+
+func TestStream(t *testing.T) {
+	db, err := logdb.NewMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := polo.BytesToAddress([]byte("from"))
+	header := new(block.Builder).Build().Header()
+	// more than a page
+	count := utils.MaxPageSize + 200
+	for i := 0; i < count/100; i++ {
+		header = new(block.Builder).ParentID(header.ID()).Build().Header()
+		batch := db.Prepare(header)
+		for j := 0; j < 100; j++ {
+			batch.ForTransaction(polo.Bytes32{}, from).Insert(nil, tx.Transfers{&tx.Transfer{
+				Sender:    from,
+				Recipient: polo.BytesToAddress([]byte{byte(j)}),
+				Amount:    big.NewInt(int64(j)),
+			}}, false)
+		}
+		if err := batch.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	router := mux.NewRouter()
+	transfers.New(db).Mount(router, "/logs/transfer")
+	// exports take longer than the API timeout
+	ts := httptest.NewServer(utils.HandleTimeout(router, time.Nanosecond, 0))
+	defer ts.Close()
+
+	res, err := http.Post(ts.URL+"/logs/transfer", "application/json", bytes.NewReader([]byte("{}")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode, "timed out without streaming")
+
+	stream := func(filter *logdb.TransferFilter) []*transfers.FilteredTransfer {
+		data, _ := json.Marshal(filter)
+		req, _ := http.NewRequest("POST", ts.URL+"/logs/transfer", bytes.NewReader(data))
+		req.Header.Set("Accept", utils.NDJSONContentType)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		assert.Equal(t, utils.NDJSONContentType, res.Header.Get("Content-Type"))
+		var list []*transfers.FilteredTransfer
+		decoder := json.NewDecoder(res.Body)
+		for decoder.More() {
+			var tr transfers.FilteredTransfer
+			if err := decoder.Decode(&tr); err != nil {
+				t.Fatal(err)
+			}
+			list = append(list, &tr)
+		}
+		return list
+	}
+
+	all := stream(&logdb.TransferFilter{})
+	if assert.Len(t, all, count) {
+		for i := 1; i < len(all); i++ {
+			prev, cur := all[i-1].Cursor, all[i].Cursor
+			assert.True(t, prev.BlockNumber < cur.BlockNumber || (prev.BlockNumber == cur.BlockNumber && prev.Index < cur.Index))
+		}
+	}
+	some := stream(&logdb.TransferFilter{Options: &logdb.Options{Offset: 50, Limit: utils.MaxPageSize + 100}, Order: logdb.DESC})
+	if assert.Len(t, some, utils.MaxPageSize+100) {
+		assert.Equal(t, all[count-51].Cursor, some[0].Cursor)
+		assert.Equal(t, all[50].Cursor, some[len(some)-1].Cursor)
+	}
+}
+
 func initLogServer(t *testing.T) {
 	db, err := logdb.NewMem()
 	if err != nil {
@@ -76,7 +184,7 @@ func initLogServer(t *testing.T) {
 			Amount:    value,
 		}
 		header = new(block.Builder).ParentID(header.ID()).Build().Header()
-		if err := db.Prepare(header).ForTransaction(polo.Bytes32{}, from).Insert(nil, tx.Transfers{transLog}).
+		if err := db.Prepare(header).ForTransaction(polo.Bytes32{}, from).Insert(nil, tx.Transfers{transLog}, false).
 			Commit(); err != nil {
 			t.Fatal(err)
 		}
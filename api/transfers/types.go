@@ -15,6 +15,7 @@ type FilteredTransfer struct {
 	Amount    *math.HexOrDecimal256 `json:"amount"`
 	Meta      transactions.LogMeta  `json:"meta"`
 	Reverted  bool                  `json:"reverted"`
+	Cursor    logdb.Cursor          `json:"cursor"`
 }
 
 func convertTransfer(transfer *logdb.Transfer) *FilteredTransfer {
@@ -31,5 +32,6 @@ func convertTransfer(transfer *logdb.Transfer) *FilteredTransfer {
 			TxOrigin:       transfer.TxOrigin,
 		},
 		Reverted: transfer.Reverted,
+		Cursor:   logdb.Cursor{BlockNumber: transfer.BlockNumber, Index: transfer.Index},
 	}
 }
@@ -46,6 +46,11 @@ func (t *TransfersLegacy) handleFilterTransferLogs(w http.ResponseWriter, req *h
 	} else {
 		filter.Order = logdb.DESC
 	}
+	options, err := utils.PageOptions(filter.Options)
+	if err != nil {
+		return err
+	}
+	filter.Options = options
 	tLogs, err := t.filter(req.Context(), convertTransferFilter(&filter))
 	if err != nil {
 		return err
@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/HiNounou029/nounouchain/api/transferslegacy"
+	"github.com/HiNounou029/nounouchain/api/utils"
 	"github.com/HiNounou029/nounouchain/polo"
 	"github.com/HiNounou029/nounouchain/nounou/logdb"
 	"github.com/HiNounou029/nounouchain/core/block"
@@ -56,6 +57,15 @@ func getTransfers(t *testing.T) {
 		t.Fatal(err)
 	}
 	assert.Equal(t, limit, len(tLogs), "should be `limit` transfers")
+
+	tf.Options.Limit = utils.MaxPageSize + 1
+	data, _ := json.Marshal(tf)
+	resp, err := http.Post(ts.URL+"/logs/transfers", "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "limit exceeds page size")
 }
 
 func initLogServer(t *testing.T) {
@@ -76,7 +86,7 @@ func initLogServer(t *testing.T) {
 			Amount:    value,
 		}
 		header = new(block.Builder).ParentID(header.ID()).Build().Header()
-		if err := db.Prepare(header).ForTransaction(polo.Bytes32{}, from).Insert(nil, tx.Transfers{transLog}).
+		if err := db.Prepare(header).ForTransaction(polo.Bytes32{}, from).Insert(nil, tx.Transfers{transLog}, false).
 			Commit(); err != nil {
 			t.Fatal(err)
 		}
@@ -7,6 +7,7 @@ import (
 	"github.com/HiNounou029/nounouchain/polo"
 	"github.com/gorilla/websocket"
 	"io"
+	"net"
 	"net/http"
 )
 
@@ -43,6 +44,15 @@ func Forbidden(cause error) error {
 	}
 }
 
+// ClientIP returns IP of the remote peer, port stripped.
+func ClientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
 // HandlerFunc like http.HandlerFunc, bu it returns an error.
 // If the returned error is httpError type, httpError.status will be responded,
 // otherwise http.StatusInternalServerError responded.
@@ -0,0 +1,63 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package utils
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/HiNounou029/nounouchain/core/block"
+	"github.com/HiNounou029/nounouchain/core/chain"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/pkg/errors"
+)
+
+// ParseRevision resolves the revision of call-like endpoints into a block header.
+// The revision is one of 'best' (or empty), block ID, trunk block number, or time prefixed by TimeRevisionPrefix.
+func ParseRevision(chain *chain.Chain, revision string) (*block.Header, error) {
+	if revision == "" || revision == "best" {
+		return chain.BestBlock().Header(), nil
+	}
+	if len(revision) == 66 || len(revision) == 64 {
+		blockID, err := polo.ParseBytes32(revision)
+		if err != nil {
+			return nil, BadRequest(errors.WithMessage(err, "revision"))
+		}
+		h, err := chain.GetBlockHeader(blockID)
+		if err != nil {
+			if chain.IsNotFound(err) {
+				return nil, BadRequest(errors.WithMessage(err, "revision"))
+			}
+			return nil, err
+		}
+		return h, nil
+	}
+	if timestamp, ok, err := ParseTimeRevision(revision); ok {
+		if err != nil {
+			return nil, BadRequest(errors.WithMessage(err, "revision"))
+		}
+		h, err := chain.GetTrunkBlockHeaderByTime(timestamp)
+		if err != nil {
+			if chain.IsNotFound(err) {
+				return nil, BadRequest(errors.WithMessage(errors.New("time before genesis block"), "revision"))
+			}
+			return nil, err
+		}
+		return h, nil
+	}
+	n, err := strconv.ParseUint(revision, 0, 0)
+	if err != nil {
+		return nil, BadRequest(errors.WithMessage(err, "revision"))
+	}
+	if n > math.MaxUint32 {
+		return nil, BadRequest(errors.WithMessage(errors.New("block number out of max uint32"), "revision"))
+	}
+	h, err := chain.GetTrunkBlockHeader(uint32(n))
+	if err != nil {
+		if chain.IsNotFound(err) {
+			return nil, BadRequest(errors.WithMessage(err, "revision"))
+		}
+		return nil, err
+	}
+	return h, nil
+}
@@ -0,0 +1,94 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/HiNounou029/nounouchain/nounou/logdb"
+	"github.com/pkg/errors"
+)
+
+// NDJSONContentType content type of newline delimited JSON, for streaming response.
+const NDJSONContentType = "application/x-ndjson"
+
+// MaxPageSize max number of logs in a page, enforced on non-streaming responses.
+const MaxPageSize = 1000
+
+// PageOptions returns options of a page, which defaults to the max page size.
+func PageOptions(options *logdb.Options) (*logdb.Options, error) {
+	if options == nil {
+		return &logdb.Options{Limit: MaxPageSize}, nil
+	}
+	if options.Limit > MaxPageSize {
+		return nil, BadRequest(errors.Errorf("options.limit: exceeds %v, use cursor or streaming mode", MaxPageSize))
+	}
+	return options, nil
+}
+
+// AcceptsNDJSON returns whether the client accepts newline delimited JSON streaming response.
+func AcceptsNDJSON(req *http.Request) bool {
+	for _, accept := range strings.Split(req.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(accept); err == nil && mediaType == NDJSONContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleTimeout is the middleware for request timeout, where streaming requests have their own
+// timeout, since exports may take much longer than other requests. Zero timeout means no timeout.
+func HandleTimeout(h http.Handler, timeout, streamTimeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t := timeout
+		if AcceptsNDJSON(r) {
+			t = streamTimeout
+		}
+		if t > 0 {
+			ctx, cancel := context.WithTimeout(r.Context(), t)
+			defer cancel()
+			r = r.WithContext(ctx)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// NDJSONWriter writes objects in newline delimited JSON.
+type NDJSONWriter struct {
+	w       http.ResponseWriter
+	enc     *json.Encoder
+	written bool
+}
+
+// NewNDJSONWriter creates a NDJSON writer.
+func NewNDJSONWriter(w http.ResponseWriter) *NDJSONWriter {
+	w.Header().Set("Content-Type", NDJSONContentType)
+	return &NDJSONWriter{w: w, enc: json.NewEncoder(w)}
+}
+
+// Write writes an object in a line.
+func (nw *NDJSONWriter) Write(obj interface{}) error {
+	nw.written = true
+	return nw.enc.Encode(obj)
+}
+
+// Flush sends buffered lines to the client.
+func (nw *NDJSONWriter) Flush() {
+	if f, ok := nw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Fail returns the error if nothing written yet, otherwise the response is aborted,
+// since status can't be changed any more, to let the client know it's incomplete.
+func (nw *NDJSONWriter) Fail(err error) error {
+	if !nw.written {
+		return err
+	}
+	panic(http.ErrAbortHandler)
+}
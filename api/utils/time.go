@@ -0,0 +1,40 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TimeRevisionPrefix prefix of revision given by time, e.g. 't:1782863999' or 't:2026-06-30T23:59:59Z',
+// which is resolved to the latest trunk block not after the time.
+const TimeRevisionPrefix = "t:"
+
+// ParseTime parses time in unix timestamp or RFC3339 format, into unix timestamp.
+func ParseTime(s string) (uint64, error) {
+	if ts, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return ts, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, errors.New("invalid time, should be unix timestamp or RFC3339")
+	}
+	if t.Unix() < 0 {
+		return 0, errors.New("time before unix epoch")
+	}
+	return uint64(t.Unix()), nil
+}
+
+// ParseTimeRevision parses the revision given by time.
+// ok is false if the revision is not prefixed by TimeRevisionPrefix.
+func ParseTimeRevision(revision string) (timestamp uint64, ok bool, err error) {
+	if !strings.HasPrefix(revision, TimeRevisionPrefix) {
+		return 0, false, nil
+	}
+	timestamp, err = ParseTime(strings.TrimPrefix(revision, TimeRevisionPrefix))
+	return timestamp, true, err
+}
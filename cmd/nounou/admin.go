@@ -0,0 +1,219 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HiNounou029/nounouchain/api/admin"
+	"github.com/HiNounou029/nounouchain/api/auth"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// prefix of admin addr and url to use unix socket
+const unixScheme = "unix:"
+
+// adminClient calls the admin API of a running node.
+type adminClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func newAdminClient(ctx *cli.Context) (*adminClient, error) {
+	url := ctx.String(adminURLFlag.Name)
+	if url == "" {
+		return nil, fmt.Errorf("missing flag %s", adminURLFlag.Name)
+	}
+	c := &adminClient{
+		baseURL: strings.TrimSuffix(url, "/"),
+		apiKey:  ctx.String(adminAPIKeyFlag.Name),
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+	if strings.HasPrefix(url, unixScheme) {
+		path := strings.TrimPrefix(url, unixScheme)
+		c.baseURL = "http://unix"
+		c.client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", path)
+			},
+		}
+	}
+	return c, nil
+}
+
+// call calls the admin API, and prints the JSON result.
+func (c *adminClient) call(method, path string, body interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseURL+"/admin"+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set(auth.KeyHeader, c.apiKey)
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%v: %v", res.Status, strings.TrimSpace(string(data)))
+	}
+	_, err = os.Stdout.Write(append(bytes.TrimSpace(data), '\n'))
+	return err
+}
+
+// adminAction creates the action calling admin API, with request built from args.
+func adminAction(nArgs int, usage string, request func(args cli.Args, ctx *cli.Context) (method, path string, body interface{}, err error)) cli.ActionFunc {
+	return func(ctx *cli.Context) error {
+		if len(ctx.Args()) != nArgs {
+			return fmt.Errorf("usage: %v", usage)
+		}
+		method, path, body, err := request(ctx.Args(), ctx)
+		if err != nil {
+			return err
+		}
+		client, err := newAdminClient(ctx)
+		if err != nil {
+			return err
+		}
+		return client.call(method, path, body)
+	}
+}
+
+var adminCommand = cli.Command{
+	Name:  "admin",
+	Usage: "manage the running node via admin API",
+	Subcommands: []cli.Command{
+		{
+			Name:  "peers",
+			Usage: "list connected peers",
+			Flags: []cli.Flag{adminURLFlag, adminAPIKeyFlag},
+			Action: adminAction(0, "peers", func(args cli.Args, ctx *cli.Context) (string, string, interface{}, error) {
+				return "GET", "/peers", nil, nil
+			}),
+		},
+		{
+			Name:  "add-peer",
+			Usage: "add a static peer, which is kept connected",
+			Flags: []cli.Flag{adminURLFlag, adminAPIKeyFlag},
+			Action: adminAction(1, "add-peer <enode>", func(args cli.Args, ctx *cli.Context) (string, string, interface{}, error) {
+				return "POST", "/peers/static", &admin.StaticPeer{Enode: args[0]}, nil
+			}),
+		},
+		{
+			Name:  "remove-peer",
+			Usage: "remove a static peer",
+			Flags: []cli.Flag{adminURLFlag, adminAPIKeyFlag},
+			Action: adminAction(1, "remove-peer <node-id>", func(args cli.Args, ctx *cli.Context) (string, string, interface{}, error) {
+				return "DELETE", "/peers/static/" + args[0], nil, nil
+			}),
+		},
+		{
+			Name:  "disconnect",
+			Usage: "disconnect a peer",
+			Flags: []cli.Flag{adminURLFlag, adminAPIKeyFlag},
+			Action: adminAction(1, "disconnect <node-id>", func(args cli.Args, ctx *cli.Context) (string, string, interface{}, error) {
+				return "POST", "/peers/" + args[0] + "/disconnect", nil, nil
+			}),
+		},
+		{
+			Name:  "ban",
+			Usage: "disconnect a peer and reject its connections",
+			Flags: []cli.Flag{adminURLFlag, adminAPIKeyFlag, banDurationFlag},
+			Action: adminAction(1, "ban [--duration seconds] <node-id>", func(args cli.Args, ctx *cli.Context) (string, string, interface{}, error) {
+				return "POST", "/peers/" + args[0] + "/ban", &admin.Ban{Duration: ctx.Uint64(banDurationFlag.Name)}, nil
+			}),
+		},
+		{
+			Name:  "unban",
+			Usage: "lift the ban of a peer",
+			Flags: []cli.Flag{adminURLFlag, adminAPIKeyFlag},
+			Action: adminAction(1, "unban <node-id>", func(args cli.Args, ctx *cli.Context) (string, string, interface{}, error) {
+				return "DELETE", "/peers/" + args[0] + "/ban", nil, nil
+			}),
+		},
+		{
+			Name:  "banned",
+			Usage: "list banned peers",
+			Flags: []cli.Flag{adminURLFlag, adminAPIKeyFlag},
+			Action: adminAction(0, "banned", func(args cli.Args, ctx *cli.Context) (string, string, interface{}, error) {
+				return "GET", "/peers/banned", nil, nil
+			}),
+		},
+		{
+			Name:  "remove-tx",
+			Usage: "remove a tx from tx pool",
+			Flags: []cli.Flag{adminURLFlag, adminAPIKeyFlag},
+			Action: adminAction(1, "remove-tx <tx-id>", func(args cli.Args, ctx *cli.Context) (string, string, interface{}, error) {
+				return "DELETE", "/txpool/txs/" + args[0], nil, nil
+			}),
+		},
+		{
+			Name:  "flush-txpool",
+			Usage: "remove all txs from tx pool",
+			Flags: []cli.Flag{adminURLFlag, adminAPIKeyFlag},
+			Action: adminAction(0, "flush-txpool", func(args cli.Args, ctx *cli.Context) (string, string, interface{}, error) {
+				return "DELETE", "/txpool/txs", nil, nil
+			}),
+		},
+		{
+			Name:  "wash",
+			Usage: "wash tx pool immediately",
+			Flags: []cli.Flag{adminURLFlag, adminAPIKeyFlag},
+			Action: adminAction(0, "wash", func(args cli.Args, ctx *cli.Context) (string, string, interface{}, error) {
+				return "POST", "/txpool/wash", nil, nil
+			}),
+		},
+		{
+			Name:  "verbosity",
+			Usage: "get or set log verbosity (0-9)",
+			Flags: []cli.Flag{adminURLFlag, adminAPIKeyFlag},
+			Action: func(ctx *cli.Context) error {
+				if len(ctx.Args()) > 1 {
+					return fmt.Errorf("usage: verbosity [level]")
+				}
+				client, err := newAdminClient(ctx)
+				if err != nil {
+					return err
+				}
+				if len(ctx.Args()) == 0 {
+					return client.call("GET", "/log/verbosity", nil)
+				}
+				lvl, err := strconv.Atoi(ctx.Args()[0])
+				if err != nil {
+					return errors.WithMessage(err, "level")
+				}
+				return client.call("PUT", "/log/verbosity", &admin.Verbosity{Verbosity: lvl})
+			},
+		},
+	},
+}
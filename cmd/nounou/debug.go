@@ -0,0 +1,119 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/HiNounou029/nounouchain/consensus"
+	"github.com/HiNounou029/nounouchain/core/block"
+	"github.com/HiNounou029/nounouchain/core/chain"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// debugReplayAction re-executes a block, and compares results with the stored header.
+// When mismatched, it prints the receipts and state diff.
+func debugReplayAction(ctx *cli.Context) error {
+	if !ctx.IsSet(blockFlag.Name) {
+		return fmt.Errorf("missing flag %s", blockFlag.Name)
+	}
+	if err := readConfig(); err != nil {
+		return err
+	}
+	initLogger(ctx)
+	gene := selectGenesis(ctx)
+	instanceDir := makeInstanceDir(ctx, gene)
+
+	mainDB := openMainDB(ctx, instanceDir)
+	defer mainDB.Close()
+
+	stateCreator := state.NewCreator(mainDB)
+	genesisBlock, _, err := gene.Build(stateCreator)
+	if err != nil {
+		return errors.WithMessage(err, "build genesis block")
+	}
+	chain, err := chain.New(mainDB, genesisBlock)
+	if err != nil {
+		return errors.WithMessage(err, "initialize block chain")
+	}
+
+	blk, err := loadReplayBlock(chain, ctx.String(blockFlag.Name))
+	if err != nil {
+		return err
+	}
+	header := blk.Header()
+	if header.Number() == 0 {
+		return errors.New("genesis block can't be replayed")
+	}
+
+	result, err := consensus.New(chain, stateCreator).Replay(blk)
+	if err != nil {
+		return errors.WithMessage(err, "replay")
+	}
+
+	fmt.Println("block:", header.Number(), header.ID())
+	matched := true
+	check := func(name string, want, have interface{}) {
+		ok := fmt.Sprint(want) == fmt.Sprint(have)
+		matched = matched && ok
+		if ok {
+			fmt.Printf("%v: %v\n", name, want)
+		} else {
+			fmt.Printf("%v: want %v, have %v\n", name, want, have)
+		}
+	}
+	check("gas used", header.GasUsed(), result.GasUsed)
+	check("receipts root", header.ReceiptsRoot(), result.ReceiptsRoot)
+	check("state root", header.StateRoot(), result.StateRoot)
+	if matched {
+		fmt.Println("replay matched")
+		return nil
+	}
+
+	receipts, err := chain.GetBlockReceipts(header.ID())
+	if err != nil {
+		return errors.WithMessage(err, "stored receipts")
+	}
+	fmt.Println("=== receipts diff ===")
+	for _, d := range result.ReceiptsDiff(receipts) {
+		fmt.Println(d)
+	}
+
+	stored, err := stateCreator.NewState(header.StateRoot())
+	if err != nil {
+		return errors.WithMessage(err, "stored state")
+	}
+	diffs, err := result.StateDiff(stored)
+	if err != nil {
+		return err
+	}
+	fmt.Println("=== state diff ===")
+	for _, d := range diffs {
+		fmt.Println(d)
+	}
+	return errors.New("replay mismatched")
+}
+
+// loadReplayBlock loads the block by number on trunk, or by ID.
+func loadReplayBlock(chain *chain.Chain, str string) (*block.Block, error) {
+	if num, err := strconv.ParseUint(str, 0, 32); err == nil {
+		blk, err := chain.GetTrunkBlock(uint32(num))
+		if err != nil {
+			return nil, errors.WithMessage(err, "load block")
+		}
+		return blk, nil
+	}
+	id, err := polo.ParseBytes32(str)
+	if err != nil {
+		return nil, errors.WithMessage(err, blockFlag.Name)
+	}
+	blk, err := chain.GetBlock(id)
+	if err != nil {
+		return nil, errors.WithMessage(err, "load block")
+	}
+	return blk, nil
+}
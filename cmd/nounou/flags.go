@@ -3,6 +3,7 @@
 package main
 
 import (
+	"github.com/HiNounou029/nounouchain/common/tlsconf"
 	"github.com/inconshreveable/log15"
 	cli "gopkg.in/urfave/cli.v1"
 )
@@ -43,11 +44,21 @@ var (
 		Value: 10000,
 		Usage: "API request timeout value in milliseconds",
 	}
+	apiStreamTimeoutFlag = cli.IntFlag{
+		Name:  "api-stream-timeout",
+		Value: 600000,
+		Usage: "API streaming (NDJSON) request timeout value in milliseconds, 0 for no timeout",
+	}
 	apiCallGasLimitFlag = cli.IntFlag{
 		Name:  "api-call-gas-limit",
 		Value: 50000000,
 		Usage: "limit contract call gas",
 	}
+	apiGraphQLCostLimitFlag = cli.IntFlag{
+		Name:  "api-graphql-cost-limit",
+		Value: 10000,
+		Usage: "limit GraphQL query cost, each block, tx, receipt, account or log resolved costs 1",
+	}
 	apiBacktraceLimitFlag = cli.IntFlag{
 		Name:  "api-backtrace-limit",
 		Value: 1000,
@@ -152,4 +163,61 @@ var (
 		Name:  "vercert",
 		Usage: "verify peer cert",
 	}
+	txPoolPolicyFlag = cli.StringFlag{
+		Name:  "txpool-policy",
+		Usage: "path of tx admission policy file (JSON), reloaded when modified",
+	}
+	txPoolOriginRateFlag = cli.Float64Flag{
+		Name:  "txpool-origin-rate",
+		Usage: "max txs per second accepted from one origin (0 unlimited)",
+	}
+	apiTxRateFlag = cli.Float64Flag{
+		Name:  "api-tx-rate",
+		Usage: "max txs per second accepted from one API client IP (0 unlimited)",
+	}
+	p2pTxRateFlag = cli.Float64Flag{
+		Name:  "p2p-tx-rate",
+		Usage: "max txs per second accepted from one P2P peer (0 unlimited)",
+	}
+	apiAuthFlag = cli.StringFlag{
+		Name:  "api-auth",
+		Usage: "path of the API auth config file (JSON), which enables authentication, route permissions and quotas",
+	}
+	apiTLSCertFlag = cli.StringFlag{
+		Name:  "api-tls-cert",
+		Usage: "path of the TLS certificate (PEM) of the API server, which enables HTTPS; reloaded on SIGHUP",
+	}
+	apiTLSKeyFlag = cli.StringFlag{
+		Name:  "api-tls-key",
+		Usage: "path of the TLS private key (PEM) of the API server",
+	}
+	apiTLSClientAuthFlag = cli.StringFlag{
+		Name:  "api-tls-client-auth",
+		Value: tlsconf.ClientAuthRequest,
+		Usage: "client certificate mode of the API server (request, verify, require), verified against the root CA",
+	}
+	adminAddrFlag = cli.StringFlag{
+		Name:  "admin-addr",
+		Usage: "listening address (host:port or unix:path) of the admin server, which serves Prometheus metrics at /metrics and admin API at /admin (disabled if empty)",
+	}
+	apiDebugFlag = cli.BoolFlag{
+		Name:  "api-debug",
+		Usage: "enable debug APIs, e.g. tx and call tracing",
+	}
+	adminURLFlag = cli.StringFlag{
+		Name:  "admin-url",
+		Usage: "URL of the admin server, e.g. http://localhost:2113 or unix:/path/to/admin.sock",
+	}
+	adminAPIKeyFlag = cli.StringFlag{
+		Name:  "api-key",
+		Usage: "API key of the admin role, required by admin server on TCP",
+	}
+	banDurationFlag = cli.Uint64Flag{
+		Name:  "duration",
+		Usage: "ban duration in seconds, 0 for forever",
+	}
+	blockFlag = cli.StringFlag{
+		Name:  "block",
+		Usage: "number (on trunk) or ID of the block",
+	}
 )
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/HiNounou029/nounouchain/api"
+	"github.com/HiNounou029/nounouchain/api/admin"
 	"github.com/HiNounou029/nounouchain/polo"
 	"github.com/HiNounou029/nounouchain/cmd/nounou/node"
 	"github.com/HiNounou029/nounouchain/core/txpool"
@@ -60,7 +61,9 @@ func main() {
 			apiAddrFlag,
 			apiCorsFlag,
 			apiTimeoutFlag,
+			apiStreamTimeoutFlag,
 			apiCallGasLimitFlag,
+			apiGraphQLCostLimitFlag,
 			apiBacktraceLimitFlag,
 			apiProtocolFlag,
 			verbosityFlag,
@@ -78,6 +81,16 @@ func main() {
 			needValCertFlag,
 			remoteNodeAddrFlag,
 			accountPwdFlag,
+			txPoolPolicyFlag,
+			txPoolOriginRateFlag,
+			apiTxRateFlag,
+			p2pTxRateFlag,
+			apiDebugFlag,
+			apiAuthFlag,
+			apiTLSCertFlag,
+			apiTLSKeyFlag,
+			apiTLSClientAuthFlag,
+			adminAddrFlag,
 		},
 		Action: defaultAction,
 		Commands: []cli.Command{
@@ -91,6 +104,23 @@ func main() {
 				},
 				Action: masterKeyAction,
 			},
+			{
+				Name:  "debug",
+				Usage: "debug tools for block-chain data",
+				Subcommands: []cli.Command{
+					{
+						Name:   "replay",
+						Usage:  "re-execute a block and compare results with the stored header",
+						Action: debugReplayAction,
+						Flags: []cli.Flag{
+							configDirFlag,
+							dataDirFlag,
+							verbosityFlag,
+							blockFlag,
+						},
+					},
+				},
+			},
 			{
 				Name:  "certificate",
 				Usage: "Certificate application service",
@@ -147,6 +177,7 @@ func main() {
 					},
 				},
 			},
+			adminCommand,
 		},
 	}
 
@@ -201,7 +232,14 @@ func defaultAction(ctx *cli.Context) error {
 
 	chain := initChain(gene, mainDB, logDB)
 
-	txPool := txpool.New(chain, state.NewCreator(mainDB), defaultTxPoolOptions)
+	txPoolOptions := defaultTxPoolOptions
+	txPoolOptions.PolicyFile = ctx.String(txPoolPolicyFlag.Name)
+	txPoolOptions.OriginRateLimit = ctx.Float64(txPoolOriginRateFlag.Name)
+	txPoolOptions.OriginRateBurst = rateBurst(txPoolOptions.OriginRateLimit)
+	txPool, err := txpool.New(chain, state.NewCreator(mainDB), txPoolOptions)
+	if err != nil {
+		fatal("initialize tx pool:", err)
+	}
 	defer func() { log.Info("closing tx pool..."); txPool.Close() }()
 
 	// Get current node cert info
@@ -211,10 +249,11 @@ func defaultAction(ctx *cli.Context) error {
 	//certBuf, _ := ioutil.ReadFile(certPath)
 	p2pcom := newP2PComm(ctx, chain, txPool, instanceDir, rootCaPath, ctx.Bool(needCertFlag.Name), certBuf)
 
-	apiHandler, apiCloser := api.New(chain, state.NewCreator(mainDB), txPool, logDB, p2pcom.comm, ctx.String(apiCorsFlag.Name), uint32(ctx.Int(apiBacktraceLimitFlag.Name)), uint64(ctx.Int(apiCallGasLimitFlag.Name)), rootCaPath)
+	apiAuth := newAPIAuth(ctx, rootCaPath)
+	apiHandler, apiCloser := api.New(chain, state.NewCreator(mainDB), txPool, logDB, p2pcom.comm, ctx.String(apiCorsFlag.Name), uint32(ctx.Int(apiBacktraceLimitFlag.Name)), uint64(ctx.Int(apiCallGasLimitFlag.Name)), uint64(ctx.Int(apiGraphQLCostLimitFlag.Name)), rootCaPath, newAPITxLimiter(ctx), ctx.Bool(apiDebugFlag.Name), apiAuth)
 	defer func() { log.Info("closing API..."); apiCloser() }()
 
-	str, srvCloser := startAPIServer(ctx, apiHandler, chain.GenesisBlock().Header().ID())
+	str, srvCloser := startAPIServer(ctx, apiHandler, chain.GenesisBlock().Header().ID(), rootCaPath)
 	log.Info("api server: ", "listener", str)
 	defer func() { log.Info("stopping API server..."); srvCloser() }()
 
@@ -223,6 +262,14 @@ func defaultAction(ctx *cli.Context) error {
 	p2pcom.Start()
 	defer p2pcom.Stop()
 
+	// static peers can be added only after p2p server started
+	registerMetrics(chain, mainDB, logDB, p2pcom.comm)
+	adminAPI := admin.New(&adminNetwork{p2pcom.p2pSrv, p2pcom.comm}, txPool, &rootLogLevel)
+	if str, adminCloser := startAdminServer(ctx, adminAPI, apiAuth); adminCloser != nil {
+		log.Info("admin server: ", "listener", str)
+		defer func() { log.Info("stopping admin server..."); adminCloser() }()
+	}
+
 	return node.New(
 		master,
 		chain,
@@ -6,7 +6,13 @@ import (
 	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
+	"github.com/HiNounou029/nounouchain/api/admin"
+	"github.com/HiNounou029/nounouchain/api/auth"
+	"github.com/HiNounou029/nounouchain/api/utils"
 	"github.com/HiNounou029/nounouchain/common/co"
+	"github.com/HiNounou029/nounouchain/common/metric"
+	"github.com/HiNounou029/nounouchain/common/ratelimit"
+	"github.com/HiNounou029/nounouchain/common/tlsconf"
 	"github.com/HiNounou029/nounouchain/core/txpool"
 	"github.com/HiNounou029/nounouchain/network"
 	"github.com/HiNounou029/nounouchain/network/comm"
@@ -14,11 +20,18 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/discover"
 	"github.com/ethereum/go-ethereum/p2p/nat"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/gorilla/mux"
+	"github.com/syndtr/goleveldb/leveldb"
 	"io/ioutil"
+	"math"
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"errors"
@@ -36,9 +49,21 @@ import (
 	"gopkg.in/urfave/cli.v1"
 )
 
+// logLevel the log verbosity, which can be changed at runtime.
+type logLevel struct {
+	lvl int32
+}
+
+var rootLogLevel logLevel
+
+func (l *logLevel) Level() log15.Lvl       { return log15.Lvl(atomic.LoadInt32(&l.lvl)) }
+func (l *logLevel) SetLevel(lvl log15.Lvl) { atomic.StoreInt32(&l.lvl, int32(lvl)) }
+
 func initLogger(ctx *cli.Context) {
-	logLevel := ctx.Int(verbosityFlag.Name)
-	log15.Root().SetHandler(log15.LvlFilterHandler(log15.Lvl(logLevel), log15.StderrHandler))
+	rootLogLevel.SetLevel(log15.Lvl(ctx.Int(verbosityFlag.Name)))
+	log15.Root().SetHandler(log15.FilterHandler(func(r *log15.Record) bool {
+		return r.Lvl <= rootLogLevel.Level()
+	}, log15.StderrHandler))
 	// set go-ethereum log lvl to Warn
 	ethLogHandler := ethlog.NewGlogHandler(ethlog.StreamHandler(os.Stderr, ethlog.TerminalFormat(true)))
 	ethLogHandler.Verbosity(ethlog.LvlWarn)
@@ -284,8 +309,12 @@ func newP2PComm(ctx *cli.Context, chain *chain.Chain, txPool *txpool.TxPool, ins
 		log.Warn("failed to load peers cache", "err", err)
 	}
 
+	communicator := comm.New(chain, txPool, rootCaPaht, isStartWithCert, certBuf)
+	rate := ctx.Float64(p2pTxRateFlag.Name)
+	communicator.SetTxRateLimit(rate, rateBurst(rate))
+
 	return &p2pComm{
-		comm:           comm.New(chain, txPool, rootCaPaht, isStartWithCert, certBuf),
+		comm:           communicator,
 		p2pSrv:         network.New(opts),
 		peersCachePath: peersCachePath,
 	}
@@ -318,32 +347,232 @@ func (p *p2pComm) Stop() {
 	}
 }
 
-func startAPIServer(ctx *cli.Context, handler http.Handler, genesisID polo.Bytes32) (string, func()) {
+// rateBurst returns burst size for the given rate, which allows txs of 10 seconds in a burst.
+func rateBurst(rate float64) int {
+	if burst := int(rate * 10); burst > 1 {
+		return burst
+	}
+	return 1
+}
+
+func newAPITxLimiter(ctx *cli.Context) *ratelimit.Limiter {
+	rate := ctx.Float64(apiTxRateFlag.Name)
+	return ratelimit.New(rate, rateBurst(rate))
+}
+
+// newAPIAuth loads API auth config, nil returned if not configured.
+func newAPIAuth(ctx *cli.Context, rootCaPath string) *auth.Auth {
+	path := ctx.String(apiAuthFlag.Name)
+	if path == "" {
+		return nil
+	}
+	a, err := auth.Load(path, rootCaPath)
+	if err != nil {
+		fatal(fmt.Sprintf("load API auth config [%v]: %v", path, err))
+	}
+	return a
+}
+
+func startAPIServer(ctx *cli.Context, handler http.Handler, genesisID polo.Bytes32, rootCaPath string) (string, func()) {
 	addr := ctx.String(apiAddrFlag.Name)
 	listener, err := net.Listen("tcp", addr)
 	if err != nil {
 		fatal(fmt.Sprintf("listen API addr [%v]: %v", addr, err))
 	}
-	timeout := ctx.Int(apiTimeoutFlag.Name)
-	if timeout > 0 {
-		handler = handleAPITimeout(handler, time.Duration(timeout)*time.Millisecond)
-	}
+	handler = utils.HandleTimeout(handler,
+		time.Duration(ctx.Int(apiTimeoutFlag.Name))*time.Millisecond,
+		time.Duration(ctx.Int(apiStreamTimeoutFlag.Name))*time.Millisecond)
 	polo.ApiProtocol = ctx.Int(apiProtocolFlag.Name)
 	handler = handleXGenesisID(handler, genesisID)
 	handler = handleXPoloChainVersion(handler)
 	handler = requestBodyLimit(handler)
+
+	certFile, keyFile := ctx.String(apiTLSCertFlag.Name), ctx.String(apiTLSKeyFlag.Name)
+	if certFile == "" && keyFile != "" {
+		fatal(fmt.Sprintf("flag %v required by %v", apiTLSCertFlag.Name, apiTLSKeyFlag.Name))
+	}
+	if certFile == "" && (polo.ApiProtocol == 1 || polo.ApiProtocol == 3) {
+		// legacy https/wss protocol
+		certFile, keyFile = "server.crt", "server.key"
+	}
+	if certFile != "" && keyFile == "" {
+		fatal(fmt.Sprintf("flag %v required by %v", apiTLSKeyFlag.Name, apiTLSCertFlag.Name))
+	}
+
 	srv := &http.Server{Handler: handler}
 	var goes co.Goes
+	var tlsServer *tlsconf.Server
+	if certFile != "" {
+		tlsServer, err = tlsconf.NewServer(certFile, keyFile, rootCaPath, ctx.String(apiTLSClientAuthFlag.Name))
+		if err != nil {
+			fatal(fmt.Sprintf("API TLS: %v", err))
+		}
+		srv.TLSConfig = tlsServer.Config()
+	}
+
+	stopReload := make(chan struct{})
 	goes.Go(func() {
-		if polo.ApiProtocol == 0 || polo.ApiProtocol == 2 {
+		if tlsServer == nil {
 			srv.Serve(listener)
-		} else {
-			err := srv.ServeTLS(listener, "server.crt", "server.key")
+		} else if err := srv.ServeTLS(listener, "", ""); err != http.ErrServerClosed {
 			fatal(fmt.Sprintf("ServeTLS addr [%v]: %v", addr, err))
 		}
-		//		srv.Serve(listener)
 	})
-	return "http://" + listener.Addr().String() + "/", func() {
+	if tlsServer != nil {
+		goes.Go(func() { reloadOnSignal(tlsServer, stopReload) })
+	}
+
+	scheme := "http://"
+	if tlsServer != nil {
+		scheme = "https://"
+	}
+	return scheme + listener.Addr().String() + "/", func() {
+		close(stopReload)
+		srv.Close()
+		goes.Wait()
+	}
+}
+
+// reloadOnSignal reloads the TLS certificate of the API server on SIGHUP, until stop closed.
+func reloadOnSignal(tlsServer *tlsconf.Server, stop <-chan struct{}) {
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+	defer signal.Stop(hupCh)
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-hupCh:
+			if err := tlsServer.Reload(); err != nil {
+				log.Warn("failed to reload API TLS certificate", "err", err)
+			} else {
+				log.Info("API TLS certificate reloaded")
+			}
+		}
+	}
+}
+
+// registerMetrics registers metrics of which values are collected on demand.
+func registerMetrics(chain *chain.Chain, mainDB *storage.LevelDB, logDB *logdb.LogDB, comm *comm.Communicator) {
+	metric.NewGaugeFunc("chain_best_block_number", "number of the best block", func() float64 {
+		return float64(chain.BestBlock().Header().Number())
+	})
+	metric.NewGaugeFunc("chain_best_block_gas_used", "gas used by the best block", func() float64 {
+		return float64(chain.BestBlock().Header().GasUsed())
+	})
+	metric.NewGaugeFunc("p2p_peers", "connected p2p peers", func() float64 {
+		return float64(comm.PeerCount())
+	})
+
+	leveldbStat := func(name, help string, value func(*leveldb.DBStats) float64) {
+		metric.NewGaugeFunc(name, help, func() float64 {
+			stats, err := mainDB.Stats()
+			if err != nil {
+				return math.NaN()
+			}
+			return value(stats)
+		})
+	}
+	leveldbStat("leveldb_io_read_bytes", "bytes read by main db", func(s *leveldb.DBStats) float64 { return float64(s.IORead) })
+	leveldbStat("leveldb_io_write_bytes", "bytes written by main db", func(s *leveldb.DBStats) float64 { return float64(s.IOWrite) })
+	leveldbStat("leveldb_write_delays", "write delays of main db due to compaction", func(s *leveldb.DBStats) float64 { return float64(s.WriteDelayCount) })
+	leveldbStat("leveldb_alive_iterators", "alive iterators of main db", func(s *leveldb.DBStats) float64 { return float64(s.AliveIterators) })
+	leveldbStat("leveldb_opened_tables", "opened tables of main db", func(s *leveldb.DBStats) float64 { return float64(s.OpenedTablesCount) })
+	leveldbStat("leveldb_block_cache_bytes", "block cache size of main db", func(s *leveldb.DBStats) float64 { return float64(s.BlockCacheSize) })
+	leveldbStat("leveldb_size_bytes", "size of tables of main db", func(s *leveldb.DBStats) float64 {
+		var size int64
+		for _, n := range s.LevelSizes {
+			size += n
+		}
+		return float64(size)
+	})
+
+	metric.NewGaugeFunc("logdb_size_bytes", "file size of log db", func() float64 {
+		info, err := os.Stat(logDB.Path())
+		if err != nil {
+			return math.NaN()
+		}
+		return float64(info.Size())
+	})
+}
+
+// adminNetwork combines the p2p server and communicator for admin API.
+type adminNetwork struct {
+	*network.Server
+	*comm.Communicator
+}
+
+// listenUnix listens on the unix socket path, which is accessible by the owner only.
+// A stale socket left by unclean exit is removed, while other files are kept untouched.
+func listenUnix(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, errors.New("file exists and is not a socket")
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, errors.New("socket in use")
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// startAdminServer starts the admin server if admin addr set, or returns nil closer.
+// The admin API is served on unix socket, of which access is controlled by file mode,
+// or on TCP only if API auth configured.
+func startAdminServer(ctx *cli.Context, adminAPI *admin.Admin, authenticator *auth.Auth) (string, func()) {
+	addr := ctx.String(adminAddrFlag.Name)
+	if addr == "" {
+		return "", nil
+	}
+	var listener net.Listener
+	if strings.HasPrefix(addr, unixScheme) {
+		l, err := listenUnix(strings.TrimPrefix(addr, unixScheme))
+		if err != nil {
+			fatal(fmt.Sprintf("listen admin addr [%v]: %v", addr, err))
+		}
+		listener = l
+	} else {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			fatal(fmt.Sprintf("listen admin addr [%v]: %v", addr, err))
+		}
+		listener = l
+		addr = "http://" + l.Addr().String() + "/"
+	}
+
+	router := http.NewServeMux()
+	router.Handle("/metrics", metric.Handler())
+
+	adminRouter := mux.NewRouter()
+	adminAPI.Mount(adminRouter, "/admin")
+	switch {
+	case listener.Addr().Network() == "unix":
+		router.Handle("/admin/", adminRouter)
+	case authenticator != nil:
+		router.Handle("/admin/", authenticator.Handler(adminRouter))
+	default:
+		log.Warn("admin API disabled on TCP without API auth, use unix socket or flag " + apiAuthFlag.Name)
+	}
+
+	srv := &http.Server{Handler: router}
+	var goes co.Goes
+	goes.Go(func() {
+		srv.Serve(listener)
+	})
+	return addr, func() {
 		srv.Close()
 		goes.Wait()
 	}
@@ -65,6 +65,7 @@ func (n *Node) minerLoop(ctx context.Context) {
 		if flow.ParentHeader().ID() != best.Header().ID() {
 			flow = nil
 			nopackcount++
+			minerSlotsCounter.With("missed").Inc()
 			log.Debug("re-schedule miner due to new best block")
 			continue
 		}
@@ -79,6 +80,9 @@ func (n *Node) minerLoop(ctx context.Context) {
 		if now+1 >= flow.When() {
 			if err := n.pack(flow); err != nil {
 				log.Error("failed to create block", "err", err)
+				minerSlotsCounter.With("failed").Inc()
+			} else {
+				minerSlotsCounter.With("produced").Inc()
 			}
 			flow = nil
 			nopackcount = 0
@@ -132,6 +136,10 @@ func (n *Node) pack(flow *miner.Flow) error {
 		return errors.WithMessage(err, "commit block")
 	}
 	commitElapsed := mclock.Now() - startTime - execElapsed
+	blockPackDuration.ObserveDuration(time.Duration(execElapsed + commitElapsed))
+	blocksCounter.With("mined").Inc()
+	blockGasCounter.Add(newBlock.Header().GasUsed())
+	blockTxsCounter.Add(uint64(len(receipts)))
 
 	n.processFork(fork)
 
@@ -75,6 +75,7 @@ func (n *Node) Run(ctx context.Context) error {
 	n.goes.Go(func() { n.houseKeeping(ctx) })
 	n.goes.Go(func() { n.txStashLoop(ctx) })
 	n.goes.Go(func() { n.minerLoop(ctx) })
+	n.goes.Go(func() { n.backfillAccountTxs(ctx) })
 
 	n.goes.Wait()
 	return nil
@@ -217,8 +218,8 @@ func (n *Node) txStashLoop(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case txEv := <-txCh:
-			// skip executables
-			if txEv.Executable != nil && *txEv.Executable {
+			// skip executables and removed
+			if txEv.Removed || (txEv.Executable != nil && *txEv.Executable) {
 				continue
 			}
 			// only stash non-executable txs
@@ -267,6 +268,10 @@ func (n *Node) processBlock(blk *block.Block, stats *blockStats) (bool, error) {
 	}
 	commitElapsed := mclock.Now() - startTime - execElapsed
 	stats.UpdateProcessed(1, len(receipts), execElapsed, commitElapsed, blk.Header().GasUsed())
+	blockImportDuration.ObserveDuration(time.Duration(execElapsed + commitElapsed))
+	blocksCounter.With("imported").Inc()
+	blockGasCounter.Add(blk.Header().GasUsed())
+	blockTxsCounter.Add(uint64(len(receipts)))
 	n.processFork(fork)
 	return len(fork.Trunk) > 0, nil
 }
@@ -288,6 +293,7 @@ func (n *Node) commitBlock(newBlock *block.Block, receipts tx.Receipts) (*chain.
 	batch := n.logDB.Prepare(newBlock.Header())
 	for i, trx := range newBlock.Transactions() {
 		origin, _ := trx.Signer()
+		batch.InsertTx(uint32(i), trx, origin, receipts[i].Reverted)
 		txBatch := batch.ForTransaction(trx.ID(), origin)
 		for _, output := range receipts[i].Outputs {
 			txBatch.Insert(output.Events, output.Transfers, receipts[i].Reverted)
@@ -328,6 +334,63 @@ func (n *Node) commitBlock(newBlock *block.Block, receipts tx.Receipts) (*chain.
 	return fork, nil
 }
 
+// backfillAccountTxs indexes txs by account for trunk blocks committed before the accountTx index,
+// from the recorded block number down to the genesis. Progress is recorded per block, to be resumed.
+func (n *Node) backfillAccountTxs(ctx context.Context) {
+	// blocks committed from now on are indexed by commitBlock
+	n.commitLock.Lock()
+	from, ok, err := n.logDB.AccountTxsIndexedFrom()
+	if err == nil && !ok {
+		from = n.chain.BestBlock().Header().Number() + 1
+		err = n.logDB.SetAccountTxsIndexedFrom(from)
+	}
+	n.commitLock.Unlock()
+	if err != nil {
+		log.Warn("failed to start account txs backfill", "err", err)
+		return
+	}
+	if from <= 1 {
+		return
+	}
+
+	log.Info("backfilling account txs", "from", from-1)
+	for num := from - 1; num > 0; num-- {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		if err := n.backfillAccountTxsOfBlock(num); err != nil {
+			log.Warn("failed to backfill account txs", "block", num, "err", err)
+			return
+		}
+		if num%10000 == 0 {
+			log.Info("backfilling account txs", "block", num)
+		}
+	}
+	log.Info("account txs backfilled")
+}
+
+func (n *Node) backfillAccountTxsOfBlock(num uint32) error {
+	blk, err := n.chain.GetTrunkBlock(num)
+	if err != nil {
+		return err
+	}
+	receipts, err := n.chain.GetBlockReceipts(blk.Header().ID())
+	if err != nil {
+		return err
+	}
+	batch := n.logDB.Prepare(blk.Header())
+	for i, trx := range blk.Transactions() {
+		origin, _ := trx.Signer()
+		batch.InsertTx(uint32(i), trx, origin, receipts[i].Reverted)
+	}
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+	return n.logDB.SetAccountTxsIndexedFrom(num)
+}
+
 func (n *Node) processFork(fork *chain.Fork) {
 	if len(fork.Branch) >= 2 {
 		trunkLen := len(fork.Trunk)
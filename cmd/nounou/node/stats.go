@@ -5,12 +5,22 @@ package node
 import (
 	"fmt"
 
+	"github.com/HiNounou029/nounouchain/common/metric"
 	"github.com/HiNounou029/nounouchain/polo"
 	"github.com/HiNounou029/nounouchain/core/block"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/mclock"
 )
 
+var (
+	blockImportDuration = metric.NewHistogram("block_import_duration_seconds", "duration of executing and committing imported blocks", nil)
+	blockPackDuration   = metric.NewHistogram("block_pack_duration_seconds", "duration of packing and committing mined blocks", nil)
+	blocksCounter       = metric.NewCounterVec("blocks", "blocks processed by source (imported, mined)", "source")
+	blockGasCounter     = metric.NewCounter("block_gas_used", "gas used by imported and mined blocks")
+	blockTxsCounter     = metric.NewCounter("block_txs", "txs in imported and mined blocks")
+	minerSlotsCounter   = metric.NewCounterVec("miner_slots", "scheduled block producing slots by outcome (produced, missed, failed)", "outcome")
+)
+
 type blockStats struct {
 	exec, commit               mclock.AbsTime
 	txs                        int
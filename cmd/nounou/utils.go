@@ -20,7 +20,6 @@ import (
 	"path/filepath"
 	"runtime"
 	"syscall"
-	"time"
 
 	"github.com/HiNounou029/nounouchain/polo"
 	"github.com/HiNounou029/nounouchain/crypto"
@@ -226,16 +225,6 @@ func handleXPoloChainVersion(h http.Handler) http.Handler {
 	})
 }
 
-// middleware for http request timeout.
-func handleAPITimeout(h http.Handler, timeout time.Duration) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx, cancel := context.WithTimeout(r.Context(), timeout)
-		defer cancel()
-		r = r.WithContext(ctx)
-		h.ServeHTTP(w, r)
-	})
-}
-
 func readPasswordFromNewTTY(prompt string) (string, error) {
 	t, err := tty.Open()
 	if err != nil {
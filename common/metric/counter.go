@@ -0,0 +1,141 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package metric
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Counter is a monotonically increasing value, safe for concurrent use.
+type Counter struct {
+	name  string
+	help  string
+	value uint64
+}
+
+// NewCounter creates a counter and registers it by name.
+// The registered one is returned if name already used.
+func NewCounter(name, help string) *Counter {
+	return register(name, func() collector {
+		return &Counter{name: name, help: help}
+	}).(*Counter)
+}
+
+// Counters returns all registered counters sorted by name.
+func Counters() []*Counter {
+	var list []*Counter
+	for _, c := range collectors() {
+		if c, ok := c.(*Counter); ok {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// Name returns name of the counter.
+func (c *Counter) Name() string { return c.name }
+
+// Help returns description of the counter.
+func (c *Counter) Help() string { return c.help }
+
+// Inc increases the counter by 1.
+func (c *Counter) Inc() { atomic.AddUint64(&c.value, 1) }
+
+// Add increases the counter by n.
+func (c *Counter) Add(n uint64) { atomic.AddUint64(&c.value, n) }
+
+// Value returns current value.
+func (c *Counter) Value() uint64 { return atomic.LoadUint64(&c.value) }
+
+func (c *Counter) metricType() string { return "counter" }
+
+func (c *Counter) writeSamples(w io.Writer) {
+	fmt.Fprintf(w, "%s %d\n", c.name, c.Value())
+}
+
+// CounterVec is a set of counters partitioned by label values.
+type CounterVec struct {
+	name       string
+	help       string
+	labelNames []string
+
+	lock     sync.Mutex
+	children map[string]*labeled
+}
+
+type labeled struct {
+	values  []string
+	counter *Counter
+	hist    *Histogram
+}
+
+// NewCounterVec creates a counter vector and registers it by name.
+// The registered one is returned if name already used.
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return register(name, func() collector {
+		return &CounterVec{
+			name:       name,
+			help:       help,
+			labelNames: labelNames,
+			children:   make(map[string]*labeled),
+		}
+	}).(*CounterVec)
+}
+
+// Name returns name of the counter vector.
+func (v *CounterVec) Name() string { return v.name }
+
+// Help returns description of the counter vector.
+func (v *CounterVec) Help() string { return v.help }
+
+// With returns the counter of given label values, which are in order of label names.
+func (v *CounterVec) With(labelValues ...string) *Counter {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	key := labelKey(v.labelNames, labelValues)
+	child, ok := v.children[key]
+	if !ok {
+		child = &labeled{values: append([]string(nil), labelValues...), counter: &Counter{name: v.name, help: v.help}}
+		v.children[key] = child
+	}
+	return child.counter
+}
+
+func (v *CounterVec) metricType() string { return "counter" }
+
+func (v *CounterVec) writeSamples(w io.Writer) {
+	for _, child := range sortedChildren(&v.lock, v.children) {
+		fmt.Fprintf(w, "%s%s %d\n", v.name, formatLabels(v.labelNames, child.values), child.counter.Value())
+	}
+}
+
+func labelKey(names, values []string) string {
+	if len(names) != len(values) {
+		panic(fmt.Sprintf("metric: %v label values required, got %v", len(names), len(values)))
+	}
+	key := ""
+	for _, v := range values {
+		key += v + "\xff"
+	}
+	return key
+}
+
+func sortedChildren(lock *sync.Mutex, children map[string]*labeled) []*labeled {
+	lock.Lock()
+	keys := make([]string, 0, len(children))
+	for k := range children {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	list := make([]*labeled, 0, len(keys))
+	for _, k := range keys {
+		list = append(list, children[k])
+	}
+	lock.Unlock()
+	return list
+}
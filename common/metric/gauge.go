@@ -0,0 +1,87 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package metric
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sync/atomic"
+)
+
+// Gauge is a value that can go up and down, safe for concurrent use.
+type Gauge struct {
+	name string
+	help string
+	bits uint64
+}
+
+// NewGauge creates a gauge and registers it by name.
+// The registered one is returned if name already used.
+func NewGauge(name, help string) *Gauge {
+	return register(name, func() collector {
+		return &Gauge{name: name, help: help}
+	}).(*Gauge)
+}
+
+// Name returns name of the gauge.
+func (g *Gauge) Name() string { return g.name }
+
+// Help returns description of the gauge.
+func (g *Gauge) Help() string { return g.help }
+
+// Set sets the gauge to v.
+func (g *Gauge) Set(v float64) { atomic.StoreUint64(&g.bits, math.Float64bits(v)) }
+
+// Add adds delta, which can be negative, to the gauge.
+func (g *Gauge) Add(delta float64) { addFloat(&g.bits, delta) }
+
+// Value returns current value.
+func (g *Gauge) Value() float64 { return math.Float64frombits(atomic.LoadUint64(&g.bits)) }
+
+func (g *Gauge) metricType() string { return "gauge" }
+
+func (g *Gauge) writeSamples(w io.Writer) {
+	fmt.Fprintf(w, "%s %s\n", g.name, formatFloat(g.Value()))
+}
+
+// GaugeFunc is a gauge of which the value is computed at collecting.
+type GaugeFunc struct {
+	name string
+	help string
+	fn   atomic.Value
+}
+
+// NewGaugeFunc creates a gauge func and registers it by name.
+// If name already used, the function of the registered one is replaced by fn.
+func NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	g := register(name, func() collector {
+		return &GaugeFunc{name: name, help: help}
+	}).(*GaugeFunc)
+	g.fn.Store(fn)
+	return g
+}
+
+// Name returns name of the gauge.
+func (g *GaugeFunc) Name() string { return g.name }
+
+// Help returns description of the gauge.
+func (g *GaugeFunc) Help() string { return g.help }
+
+// Value returns current value.
+func (g *GaugeFunc) Value() float64 { return g.fn.Load().(func() float64)() }
+
+func (g *GaugeFunc) metricType() string { return "gauge" }
+
+func (g *GaugeFunc) writeSamples(w io.Writer) {
+	fmt.Fprintf(w, "%s %s\n", g.name, formatFloat(g.Value()))
+}
+
+func addFloat(bits *uint64, delta float64) {
+	for {
+		old := atomic.LoadUint64(bits)
+		if atomic.CompareAndSwapUint64(bits, old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package metric
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefBuckets default histogram buckets, for durations in seconds.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Histogram counts observations in buckets, safe for concurrent use.
+type Histogram struct {
+	name    string
+	help    string
+	buckets []float64 // upper bounds, sorted
+	counts  []uint64  // not cumulative
+	count   uint64
+	sumBits uint64
+}
+
+func newHistogram(name, help string, buckets []float64) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Histogram{
+		name:    name,
+		help:    help,
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+// NewHistogram creates a histogram and registers it by name.
+// DefBuckets used if buckets empty. The registered one is returned if name already used.
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	return register(name, func() collector {
+		return newHistogram(name, help, buckets)
+	}).(*Histogram)
+}
+
+// Name returns name of the histogram.
+func (h *Histogram) Name() string { return h.name }
+
+// Help returns description of the histogram.
+func (h *Histogram) Help() string { return h.help }
+
+// Observe adds an observation.
+func (h *Histogram) Observe(v float64) {
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		atomic.AddUint64(&h.counts[i], 1)
+	}
+	addFloat(&h.sumBits, v)
+	atomic.AddUint64(&h.count, 1)
+}
+
+// ObserveDuration adds an observation of duration in seconds.
+func (h *Histogram) ObserveDuration(d time.Duration) {
+	h.Observe(d.Seconds())
+}
+
+// ObserveSince adds an observation of duration since start in seconds.
+// It's handy to be deferred, e.g. defer h.ObserveSince(time.Now()).
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.ObserveDuration(time.Since(start))
+}
+
+// Count returns count of observations.
+func (h *Histogram) Count() uint64 { return atomic.LoadUint64(&h.count) }
+
+// Sum returns sum of observations.
+func (h *Histogram) Sum() float64 { return math.Float64frombits(atomic.LoadUint64(&h.sumBits)) }
+
+func (h *Histogram) metricType() string { return "histogram" }
+
+func (h *Histogram) writeSamples(w io.Writer) {
+	h.writeLabeled(w, nil, nil)
+}
+
+func (h *Histogram) writeLabeled(w io.Writer, names, values []string) {
+	count := h.Count()
+	leNames := append(append([]string(nil), names...), "le")
+	leValues := append(append([]string(nil), values...), "")
+	var cumulative uint64
+	for i, le := range h.buckets {
+		cumulative += atomic.LoadUint64(&h.counts[i])
+		leValues[len(values)] = formatFloat(le)
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(leNames, leValues), cumulative)
+	}
+	leValues[len(values)] = "+Inf"
+	fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(leNames, leValues), count)
+	fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(names, values), formatFloat(h.Sum()))
+	fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(names, values), count)
+}
+
+// HistogramVec is a set of histograms partitioned by label values.
+type HistogramVec struct {
+	name       string
+	help       string
+	buckets    []float64
+	labelNames []string
+
+	lock     sync.Mutex
+	children map[string]*labeled
+}
+
+// NewHistogramVec creates a histogram vector and registers it by name.
+// DefBuckets used if buckets empty. The registered one is returned if name already used.
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	return register(name, func() collector {
+		return &HistogramVec{
+			name:       name,
+			help:       help,
+			buckets:    buckets,
+			labelNames: labelNames,
+			children:   make(map[string]*labeled),
+		}
+	}).(*HistogramVec)
+}
+
+// Name returns name of the histogram vector.
+func (v *HistogramVec) Name() string { return v.name }
+
+// Help returns description of the histogram vector.
+func (v *HistogramVec) Help() string { return v.help }
+
+// With returns the histogram of given label values, which are in order of label names.
+func (v *HistogramVec) With(labelValues ...string) *Histogram {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	key := labelKey(v.labelNames, labelValues)
+	child, ok := v.children[key]
+	if !ok {
+		child = &labeled{values: append([]string(nil), labelValues...), hist: newHistogram(v.name, v.help, v.buckets)}
+		v.children[key] = child
+	}
+	return child.hist
+}
+
+func (v *HistogramVec) metricType() string { return "histogram" }
+
+func (v *HistogramVec) writeSamples(w io.Writer) {
+	for _, child := range sortedChildren(&v.lock, v.children) {
+		child.hist.writeLabeled(w, v.labelNames, child.values)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package metric
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	c := NewCounter("test_counter", "a counter")
+	c.Add(3)
+	assert.Equal(t, c, NewCounter("test_counter", ""), "registered one returned")
+
+	g := NewGauge("test_gauge", "a gauge")
+	g.Set(1.5)
+	g.Add(-0.5)
+	NewGaugeFunc("test_gauge_func", "a gauge func", func() float64 { return 1 })
+	NewGaugeFunc("test_gauge_func", "a gauge func", func() float64 { return 2 })
+
+	cv := NewCounterVec("test_counter_vec", "a counter vec", "msg")
+	cv.With("b").Inc()
+	cv.With(`a"`).Add(2)
+
+	h := NewHistogram("test_histogram", "a histogram\nhelp", []float64{1, 0.1})
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(3)
+
+	hv := NewHistogramVec("test_histogram_vec", "a histogram vec", []float64{1}, "route", "method")
+	hv.With("/blocks/{revision}", "GET").Observe(0.25)
+
+	var buf bytes.Buffer
+	assert.Nil(t, WritePrometheus(&buf))
+
+	var lines []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.Contains(line, "test_") {
+			lines = append(lines, line)
+		}
+	}
+	assert.Equal(t, []string{
+		"# HELP test_counter a counter",
+		"# TYPE test_counter counter",
+		"test_counter 3",
+		"# HELP test_counter_vec a counter vec",
+		"# TYPE test_counter_vec counter",
+		`test_counter_vec{msg="a\""} 2`,
+		`test_counter_vec{msg="b"} 1`,
+		"# HELP test_gauge a gauge",
+		"# TYPE test_gauge gauge",
+		"test_gauge 1",
+		"# HELP test_gauge_func a gauge func",
+		"# TYPE test_gauge_func gauge",
+		"test_gauge_func 2",
+		`# HELP test_histogram a histogram\nhelp`,
+		"# TYPE test_histogram histogram",
+		`test_histogram_bucket{le="0.1"} 1`,
+		`test_histogram_bucket{le="1"} 2`,
+		`test_histogram_bucket{le="+Inf"} 3`,
+		"test_histogram_sum 3.55",
+		"test_histogram_count 3",
+		"# HELP test_histogram_vec a histogram vec",
+		"# TYPE test_histogram_vec histogram",
+		`test_histogram_vec_bucket{route="/blocks/{revision}",method="GET",le="1"} 1`,
+		`test_histogram_vec_bucket{route="/blocks/{revision}",method="GET",le="+Inf"} 1`,
+		`test_histogram_vec_sum{route="/blocks/{revision}",method="GET"} 0.25`,
+		`test_histogram_vec_count{route="/blocks/{revision}",method="GET"} 1`,
+	}, lines)
+
+	assert.Panics(t, func() { cv.With("a", "b") }, "label values mismatch")
+}
@@ -0,0 +1,120 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package metric
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// collector is a registered metric.
+type collector interface {
+	Name() string
+	Help() string
+	metricType() string
+	// writeSamples writes samples in Prometheus text format.
+	writeSamples(w io.Writer)
+}
+
+var registry struct {
+	sync.Mutex
+	m map[string]collector
+}
+
+// register registers the collector created by create, or returns the one already registered by name.
+func register(name string, create func() collector) collector {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if c, ok := registry.m[name]; ok {
+		return c
+	}
+	if registry.m == nil {
+		registry.m = make(map[string]collector)
+	}
+	c := create()
+	registry.m[name] = c
+	return c
+}
+
+// collectors returns all registered collectors sorted by name.
+func collectors() []collector {
+	registry.Lock()
+	defer registry.Unlock()
+
+	list := make([]collector, 0, len(registry.m))
+	for _, c := range registry.m {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+	return list
+}
+
+// WritePrometheus writes all registered metrics in Prometheus text exposition format.
+func WritePrometheus(w io.Writer) error {
+	var buf bytes.Buffer
+	for _, c := range collectors() {
+		fmt.Fprintf(&buf, "# HELP %s %s\n", c.Name(), escapeHelp(c.Help()))
+		fmt.Fprintf(&buf, "# TYPE %s %s\n", c.Name(), c.metricType())
+		c.writeSamples(&buf)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Handler returns the http handler serving metrics for Prometheus to scrape.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WritePrometheus(w)
+	})
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(values[i]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
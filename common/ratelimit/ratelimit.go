@@ -0,0 +1,85 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// max count of keys tracked, least recently used keys are evicted beyond it.
+const maxKeys = 65536
+
+// Limiter limits rate of events per key, with token bucket algorithm.
+// A nil Limiter allows all events.
+type Limiter struct {
+	rate    float64 // tokens added per second
+	burst   float64 // capacity of bucket
+	buckets *lru.Cache
+	lock    sync.Mutex
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New create a limiter which allows rate events per second for each key, with bursts of at most burst events.
+// Nil returned if rate is not positive, which means no limitation.
+func New(rate float64, burst int) *Limiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	buckets, _ := lru.New(maxKeys)
+	return &Limiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: buckets,
+		now:     time.Now,
+	}
+}
+
+// Allow consumes one token from the bucket of key, and returns false if the bucket is empty.
+func (l *Limiter) Allow(key interface{}) bool {
+	if l == nil {
+		return true
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	var b *bucket
+	if v, ok := l.buckets.Get(key); ok {
+		b = v.(*bucket)
+		b.tokens += now.Sub(b.last).Seconds() * l.rate
+		if b.tokens > l.burst {
+			b.tokens = l.burst
+		}
+		b.last = now
+	} else {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets.Add(key, b)
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Forget drops the bucket of key.
+func (l *Limiter) Forget(key interface{}) {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.buckets.Remove(key)
+}
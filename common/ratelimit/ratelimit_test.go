@@ -0,0 +1,43 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := New(2, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, l.Allow("a"))
+	}
+	assert.False(t, l.Allow("a"), "burst exhausted")
+	assert.True(t, l.Allow("b"), "buckets are per key")
+
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, l.Allow("a"), "one token refilled")
+	assert.False(t, l.Allow("a"))
+
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		assert.True(t, l.Allow("a"))
+	}
+	assert.False(t, l.Allow("a"), "refill capped by burst")
+
+	l.Forget("a")
+	assert.True(t, l.Allow("a"))
+}
+
+func TestNilLimiter(t *testing.T) {
+	l := New(0, 10)
+	assert.Nil(t, l)
+	for i := 0; i < 100; i++ {
+		assert.True(t, l.Allow("a"))
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+// Package tlsconf provides TLS config of servers, of which the certificate and the client CA
+// can be reloaded without restarting.
+package tlsconf
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// client auth modes
+const (
+	// ClientAuthRequest requests client certificates without verifying, which may be verified later, e.g. by API auth.
+	ClientAuthRequest = "request"
+	// ClientAuthVerify verifies client certificates against the client CA, if given.
+	ClientAuthVerify = "verify"
+	// ClientAuthRequire requires client certificates verified against the client CA.
+	ClientAuthRequire = "require"
+)
+
+func parseClientAuth(mode string) (tls.ClientAuthType, error) {
+	switch mode {
+	case "", ClientAuthRequest:
+		return tls.RequestClientCert, nil
+	case ClientAuthVerify:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	}
+	return tls.NoClientCert, fmt.Errorf("unknown client auth mode %v", mode)
+}
+
+// Server TLS config of a server.
+type Server struct {
+	certFile   string
+	keyFile    string
+	caFile     string
+	clientAuth tls.ClientAuthType
+
+	lock      sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+// NewServer creates the TLS config with the certificate key pair. The client CA file is
+// required if clientAuth mode is verify or require.
+func NewServer(certFile, keyFile, caFile, clientAuth string) (*Server, error) {
+	mode, err := parseClientAuth(clientAuth)
+	if err != nil {
+		return nil, err
+	}
+	if mode != tls.RequestClientCert && caFile == "" {
+		return nil, fmt.Errorf("client CA required by client auth mode %v", clientAuth)
+	}
+	s := &Server{
+		certFile:   certFile,
+		keyFile:    keyFile,
+		clientAuth: mode,
+	}
+	if mode != tls.RequestClientCert {
+		s.caFile = caFile
+	}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload reloads the certificate and the client CA from files.
+// The loaded ones are kept if failed.
+func (s *Server) Reload() error {
+	cert, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+	if err != nil {
+		return errors.WithMessage(err, "load certificate")
+	}
+	var clientCAs *x509.CertPool
+	if s.caFile != "" {
+		data, err := ioutil.ReadFile(s.caFile)
+		if err != nil {
+			return errors.WithMessage(err, "load client CA")
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return errors.New("load client CA: no certificate found")
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.cert = &cert
+	s.clientCAs = clientCAs
+	return nil
+}
+
+func (s *Server) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.cert, nil
+}
+
+// Config returns the tls.Config, which always uses the latest loaded certificate and client CA.
+func (s *Server) Config() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: s.getCertificate,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			s.lock.RLock()
+			defer s.lock.RUnlock()
+			return &tls.Config{
+				MinVersion:     tls.VersionTLS12,
+				GetCertificate: s.getCertificate,
+				ClientAuth:     s.clientAuth,
+				ClientCAs:      s.clientCAs,
+				NextProtos:     []string{"h2", "http/1.1"},
+			}, nil
+		},
+	}
+}
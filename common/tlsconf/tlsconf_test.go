@@ -0,0 +1,152 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package tlsconf
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newCert(t *testing.T, cn string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCert{cert, key}
+}
+
+func (c *testCert) write(t *testing.T, certFile, keyFile string) {
+	keyDER, _ := x509.MarshalECPrivateKey(c.key)
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if keyFile != "" {
+		if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func (c *testCert) tlsCert() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+func TestServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlsconf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile, caFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.pem")
+
+	ca := newCert(t, "ca", nil)
+	ca.write(t, caFile, "")
+	server1 := newCert(t, "server1", ca)
+	server1.write(t, certFile, keyFile)
+	client := newCert(t, "client", ca)
+	stranger := newCert(t, "stranger", newCert(t, "other ca", nil))
+
+	_, err = NewServer(certFile, keyFile, "", ClientAuthRequire)
+	assert.NotNil(t, err, "client CA required")
+	_, err = NewServer(certFile, keyFile, caFile, "always")
+	assert.NotNil(t, err, "unknown mode")
+	_, err = NewServer(certFile, caFile, caFile, ClientAuthRequest)
+	assert.NotNil(t, err, "bad key")
+
+	s, err := NewServer(certFile, keyFile, caFile, ClientAuthRequire)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{
+		TLSConfig: s.Config(),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Write([]byte(req.TLS.VerifiedChains[0][0].Subject.CommonName))
+		}),
+	}
+	go srv.ServeTLS(listener, "", "")
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(certs ...tls.Certificate) (string, string, error) {
+		transport := &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs}}
+		defer transport.CloseIdleConnections()
+		resp, err := (&http.Client{Transport: transport}).Get("https://" + listener.Addr().String())
+		if err != nil {
+			return "", "", err
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp.TLS.PeerCertificates[0].Subject.CommonName, string(body), nil
+	}
+
+	serverCN, clientCN, err := get(client.tlsCert())
+	assert.Nil(t, err)
+	assert.Equal(t, "server1", serverCN)
+	assert.Equal(t, "client", clientCN)
+
+	_, _, err = get()
+	assert.NotNil(t, err, "client cert required")
+	_, _, err = get(stranger.tlsCert())
+	assert.NotNil(t, err, "client cert of unknown CA")
+
+	// reload
+	newCert(t, "server2", ca).write(t, certFile, keyFile)
+	assert.Nil(t, s.Reload())
+	serverCN, _, err = get(client.tlsCert())
+	assert.Nil(t, err)
+	assert.Equal(t, "server2", serverCN)
+
+	// failed reload keeps the loaded
+	ioutil.WriteFile(keyFile, []byte("bad"), 0600)
+	assert.NotNil(t, s.Reload())
+	serverCN, _, err = get(client.tlsCert())
+	assert.Nil(t, err)
+	assert.Equal(t, "server2", serverCN)
+}
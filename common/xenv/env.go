@@ -75,6 +75,25 @@ func (env *Environment) BlockContext() *BlockContext             { return env.bl
 func (env *Environment) Caller() polo.Address                    { return polo.Address(env.contract.Caller()) }
 func (env *Environment) To() polo.Address                        { return polo.Address(env.contract.Address()) }
 
+// OuterCaller returns the caller of the contract, when the native method is called by
+// the contract itself, e.g. msg.sender of a forwarder. Otherwise the caller is returned.
+func (env *Environment) OuterCaller() polo.Address {
+	if parent := env.contract.Parent(); parent != nil && parent.Address() == env.contract.Caller() {
+		return polo.Address(parent.Caller())
+	}
+	return env.Caller()
+}
+
+// revert is panicked to abort the native call with revert data.
+type revert struct {
+	data []byte
+}
+
+// Revert aborts the native call, and reverts with the reason.
+func (env *Environment) Revert(reason string) {
+	panic(&revert{vm.EncodeRevertReason(reason)})
+}
+
 func (env *Environment) UseGas(gas uint64) {
 	if !env.contract.UseGas(gas) {
 		panic(vm.ErrOutOfGas)
@@ -83,8 +102,8 @@ func (env *Environment) UseGas(gas uint64) {
 
 func (env *Environment) ParseArgs(val interface{}) {
 	if err := env.abi.DecodeInput(env.contract.Input, val); err != nil {
-		// as vm error
-		panic(errors.WithMessage(err, "decode native input"))
+		// input may be arbitrary, if forwarded from calldata
+		env.Revert("builtin: malformed input")
 	}
 }
 
@@ -112,6 +131,8 @@ func (env *Environment) Call(proc func(env *Environment) []interface{}) (output
 		if e := recover(); e != nil {
 			if e == vm.ErrOutOfGas {
 				err = vm.ErrOutOfGas
+			} else if r, ok := e.(*revert); ok {
+				output, err = r.data, vm.ErrExecutionReverted
 			} else {
 				panic(e)
 			}
@@ -7,6 +7,8 @@ import (
 	"github.com/HiNounou029/nounouchain/core/block"
 	"github.com/HiNounou029/nounouchain/core/chain"
 	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/nounou/builtin"
+	"github.com/HiNounou029/nounouchain/polo"
 	"github.com/HiNounou029/nounouchain/state"
 	"github.com/HiNounou029/nounouchain/vm/runtime"
 )
@@ -16,13 +18,15 @@ import (
 type Consensus struct {
 	chain        *chain.Chain
 	stateCreator *state.Creator
+	forkConfig   polo.ForkConfig
 }
 
 // New create a Consensus instance.
 func New(chain *chain.Chain, stateCreator *state.Creator) *Consensus {
 	return &Consensus{
 		chain:        chain,
-		stateCreator: stateCreator}
+		stateCreator: stateCreator,
+		forkConfig:   polo.GetForkConfig(chain.GenesisBlock().Header().ID())}
 }
 
 // Process process a block.
@@ -77,6 +81,7 @@ func (c *Consensus) NewRuntimeForReplay(header *block.Header) (*runtime.Runtime,
 	if err := c.validateProposer(header, parentHeader, state); err != nil {
 		return nil, err
 	}
+	builtin.ActivatePlugins(state, c.forkConfig, header.Number())
 
 	return runtime.New(
 		c.chain.NewSeeker(header.ParentID()),
@@ -323,6 +323,17 @@ func (tc *testConsensus) TestValidateBlockBody() {
 		expect := consensusError("tx ref future block: ref 100, current 1")
 		tc.assert.Equal(err, expect)
 	}
+	triggers["triggerErrTxTypeUnsupported"] = func() {
+		err := tc.consent(
+			tc.sign(
+				tc.originalBuilder().Transaction(
+					txSign(txBuilder(tc.tag).Type(tx.TypeNative)),
+				).Build(),
+			),
+		)
+		expect := consensusError("tx type unsupported before fork: type 1")
+		tc.assert.Equal(err, expect)
+	}
 
 	for _, trigger := range triggers {
 		trigger()
@@ -379,3 +390,35 @@ func (tc *testConsensus) TestValidateProposer() {
 		trigger()
 	}
 }
+
+func (tc *testConsensus) TestReplay() {
+	result, err := tc.con.Replay(tc.original)
+	tc.assert.Nil(err)
+	tc.assert.Equal(tc.original.Header().GasUsed(), result.GasUsed)
+	tc.assert.Equal(tc.original.Header().ReceiptsRoot(), result.ReceiptsRoot)
+	tc.assert.Equal(tc.original.Header().StateRoot(), result.StateRoot)
+
+	root, err := result.State.Stage().Commit()
+	tc.assert.Nil(err)
+	stored, err := tc.con.stateCreator.NewState(root)
+	tc.assert.Nil(err)
+
+	diffs, err := result.StateDiff(stored)
+	tc.assert.Nil(err)
+	tc.assert.Empty(diffs)
+
+	addr := polo.BytesToAddress([]byte("addr"))
+	key := polo.BytesToBytes32([]byte("key"))
+	result.State.SetBalance(addr, big.NewInt(1))
+	result.State.SetStorage(addr, key, polo.BytesToBytes32([]byte{1}))
+	diffs, err = result.StateDiff(stored)
+	tc.assert.Nil(err)
+	tc.assert.Equal([]Diff{
+		{addr.String() + ".balance", "0", "1"},
+		{fmt.Sprintf("%v.storage[%v]", addr, key), "0x", "0x01"},
+	}, diffs)
+
+	tc.assert.Empty(result.ReceiptsDiff(result.Receipts))
+	stored2 := tx.Receipts{{GasUsed: 1, Paid: new(big.Int), Reward: new(big.Int)}}
+	tc.assert.Equal([]Diff{{"receipts.len", "1", "0"}}, result.ReceiptsDiff(stored2))
+}
@@ -0,0 +1,152 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package consensus
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/HiNounou029/nounouchain/core/block"
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/pkg/errors"
+)
+
+// ReplayResult is the outcome of re-executing a block.
+type ReplayResult struct {
+	// State is the state after execution, not committed.
+	State        *state.State
+	Receipts     tx.Receipts
+	GasUsed      uint64
+	StateRoot    polo.Bytes32
+	ReceiptsRoot polo.Bytes32
+}
+
+// Diff is a mismatched item, between stored (want) and replayed (have) values.
+type Diff struct {
+	Name string
+	Want string
+	Have string
+}
+
+func (d Diff) String() string {
+	return fmt.Sprintf("%v: want %v, have %v", d.Name, d.Want, d.Have)
+}
+
+// Replay re-executes txs of the block on its parent state. Unlike Process, results are
+// not validated against the header, so that they can be compared in detail.
+func (c *Consensus) Replay(blk *block.Block) (*ReplayResult, error) {
+	rt, err := c.NewRuntimeForReplay(blk.Header())
+	if err != nil {
+		return nil, err
+	}
+
+	var gasUsed uint64
+	receipts := make(tx.Receipts, 0, len(blk.Transactions()))
+	for _, tx := range blk.Transactions() {
+		receipt, err := rt.ExecuteTransaction(tx)
+		if err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("execute tx %v", tx.ID()))
+		}
+		gasUsed += receipt.GasUsed
+		receipts = append(receipts, receipt)
+	}
+	if err := rt.Seeker().Err(); err != nil {
+		return nil, errors.WithMessage(err, "chain")
+	}
+
+	stateRoot, err := rt.State().Stage().Hash()
+	if err != nil {
+		return nil, err
+	}
+	return &ReplayResult{
+		State:        rt.State(),
+		Receipts:     receipts,
+		GasUsed:      gasUsed,
+		StateRoot:    stateRoot,
+		ReceiptsRoot: receipts.RootHash(),
+	}, nil
+}
+
+// StateDiff compares accounts touched by the replay, with the stored state after the block.
+// Accounts touched only by the stored execution can't be detected.
+func (r *ReplayResult) StateDiff(stored *state.State) ([]Diff, error) {
+	touched := r.State.Touched()
+	addrs := make([]polo.Address, 0, len(touched))
+	for addr := range touched {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+
+	var diffs []Diff
+	add := func(name string, want, have interface{}) {
+		if w, h := fmt.Sprint(want), fmt.Sprint(have); w != h {
+			diffs = append(diffs, Diff{name, w, h})
+		}
+	}
+	for _, addr := range addrs {
+		add(addr.String()+".balance", stored.GetBalance(addr), r.State.GetBalance(addr))
+		add(addr.String()+".master", stored.GetMaster(addr), r.State.GetMaster(addr))
+		add(addr.String()+".codeHash", stored.GetCodeHash(addr), r.State.GetCodeHash(addr))
+
+		keys := touched[addr]
+		sort.Slice(keys, func(i, j int) bool {
+			return bytes.Compare(keys[i][:], keys[j][:]) < 0
+		})
+		for _, key := range keys {
+			add(fmt.Sprintf("%v.storage[%v]", addr, key),
+				hexutil.Bytes(stored.GetRawStorage(addr, key)),
+				hexutil.Bytes(r.State.GetRawStorage(addr, key)))
+		}
+	}
+	if err := stored.Err(); err != nil {
+		return nil, errors.WithMessage(err, "stored state")
+	}
+	if err := r.State.Err(); err != nil {
+		return nil, errors.WithMessage(err, "replayed state")
+	}
+	return diffs, nil
+}
+
+// ReceiptsDiff compares replayed receipts with stored ones, field by field.
+func (r *ReplayResult) ReceiptsDiff(stored tx.Receipts) []Diff {
+	var diffs []Diff
+	add := func(name string, want, have interface{}) {
+		if w, h := fmt.Sprint(want), fmt.Sprint(have); w != h {
+			diffs = append(diffs, Diff{name, w, h})
+		}
+	}
+	add("receipts.len", len(stored), len(r.Receipts))
+
+	for i := 0; i < len(stored) && i < len(r.Receipts); i++ {
+		want, have := stored[i], r.Receipts[i]
+		prefix := fmt.Sprintf("receipts[%v]", i)
+		add(prefix+".gasUsed", want.GasUsed, have.GasUsed)
+		add(prefix+".gasPayer", want.GasPayer, have.GasPayer)
+		add(prefix+".paid", want.Paid, have.Paid)
+		add(prefix+".reward", want.Reward, have.Reward)
+		add(prefix+".reverted", want.Reverted, have.Reverted)
+		add(prefix+".revertData", hexRLP(want.RevertData), hexRLP(have.RevertData))
+		add(prefix+".outputs.len", len(want.Outputs), len(have.Outputs))
+		for j := 0; j < len(want.Outputs) && j < len(have.Outputs); j++ {
+			add(fmt.Sprintf("%v.outputs[%v].events", prefix, j), hexRLP(want.Outputs[j].Events), hexRLP(have.Outputs[j].Events))
+			add(fmt.Sprintf("%v.outputs[%v].transfers", prefix, j), hexRLP(want.Outputs[j].Transfers), hexRLP(have.Outputs[j].Transfers))
+		}
+	}
+	return diffs
+}
+
+// hexRLP encodes val in rlp, and returns in hex, to compare values in depth.
+func hexRLP(val interface{}) string {
+	data, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		return err.Error()
+	}
+	return hexutil.Encode(data)
+}
@@ -32,6 +32,7 @@ func (c *Consensus) validate(
 	if err := c.validateProposer(header, parentHeader, state); err != nil {
 		return nil, nil, err
 	}
+	builtin.ActivatePlugins(state, c.forkConfig, header.Number())
 
 	if err := c.validateBlockBody(block); err != nil {
 		return nil, nil, err
@@ -131,8 +132,10 @@ func (c *Consensus) validateBlockBody(blk *block.Block) error {
 			return consensusError(fmt.Sprintf("tx ref future block: ref %v, current %v", tx.BlockRef().Number(), header.Number()))
 		case tx.IsExpired(header.Number()):
 			return consensusError(fmt.Sprintf("tx expired: ref %v, current %v, expiration %v", tx.BlockRef().Number(), header.Number(), tx.Expiration()))
-		case tx.HasReservedFields():
+		case tx.HasReservedFields(c.forkConfig, header.Number()):
 			return consensusError(fmt.Sprintf("tx reserved fields not empty"))
+		case tx.IsTyped() && header.Number() < c.forkConfig.TypedTx:
+			return consensusError(fmt.Sprintf("tx type unsupported before fork: type %v", tx.Type()))
 		}
 	}
 
@@ -4,6 +4,7 @@ package chain
 
 import (
 	"bytes"
+	"sort"
 	"sync"
 
 	"github.com/HiNounou029/nounouchain/polo"
@@ -323,6 +324,52 @@ func (c *Chain) GetTrunkBlockHeader(num uint32) (*block.Header, error) {
 	return c.getBlockHeader(id)
 }
 
+// GetTrunkBlockHeaderByTime get the latest block header on trunk, of which timestamp is not after
+// the given timestamp. Not found error returned if the timestamp is before genesis block.
+func (c *Chain) GetTrunkBlockHeaderByTime(timestamp uint64) (*block.Header, error) {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+	best := c.bestBlock.Header()
+	if timestamp >= best.Timestamp() {
+		return best, nil
+	}
+
+	var err error
+	header := func(num uint32) *block.Header {
+		id, e := c.ancestorTrie.GetAncestor(best.ID(), num)
+		if e != nil {
+			err = e
+			return nil
+		}
+		h, e := c.getBlockHeader(id)
+		if e != nil {
+			err = e
+			return nil
+		}
+		return h
+	}
+	// block timestamps are strictly increasing along the trunk,
+	// so search the first block after the timestamp
+	n := sort.Search(int(best.Number()), func(i int) bool {
+		if err != nil {
+			return true
+		}
+		h := header(uint32(i))
+		return h == nil || h.Timestamp() > timestamp
+	})
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, errNotFound
+	}
+	h := header(uint32(n - 1))
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
 // GetTrunkBlock get block on trunk by given block number.
 func (c *Chain) GetTrunkBlock(num uint32) (*block.Block, error) {
 	c.rw.RLock()
@@ -71,3 +71,45 @@ func TestAdd(t *testing.T) {
 		}
 	}
 }
+
+func TestGetTrunkBlockHeaderByTime(t *testing.T) {
+	ch := initChain()
+	b0 := ch.GenesisBlock()
+	parent := b0
+	var blocks []*block.Block
+	for i := 1; i <= 5; i++ {
+		b := new(block.Builder).
+			ParentID(parent.Header().ID()).
+			Timestamp(b0.Header().Timestamp() + uint64(i)*10).
+			TotalScore(parent.Header().TotalScore() + 1).
+			Build()
+		sig, _ := crypto.Sign(b.Header().SigningHash().Bytes(), privateKey)
+		b = b.WithSignature(sig)
+		if _, err := ch.AddBlock(b, nil); err != nil {
+			t.Fatal(err)
+		}
+		blocks = append(blocks, b)
+		parent = b
+	}
+	genesisTime := b0.Header().Timestamp()
+
+	tests := []struct {
+		timestamp uint64
+		want      *block.Block
+	}{
+		{genesisTime, b0},
+		{genesisTime + 9, b0},
+		{genesisTime + 10, blocks[0]},
+		{genesisTime + 35, blocks[2]},
+		{genesisTime + 50, blocks[4]},
+		{genesisTime + 1000, blocks[4]},
+	}
+	for _, tt := range tests {
+		h, err := ch.GetTrunkBlockHeaderByTime(tt.timestamp)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.want.Header().ID(), h.ID(), "timestamp %v", tt.timestamp)
+	}
+
+	_, err := ch.GetTrunkBlockHeaderByTime(genesisTime - 1)
+	assert.True(t, ch.IsNotFound(err), "before genesis")
+}
@@ -6,11 +6,20 @@ import (
 	"encoding/binary"
 
 	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 // Builder to make it easy to build transaction.
 type Builder struct {
-	body body
+	txType byte
+	body   body
+}
+
+// Type set tx type. TypeLegacy by default.
+func (b *Builder) Type(txType byte) *Builder {
+	b.txType = txType
+	b.body.Plain = txType == TypePlain
+	return b
 }
 
 // ChainTag set chain tag.
@@ -60,8 +69,25 @@ func (b *Builder) DependsOn(txID *polo.Bytes32) *Builder {
 	return b
 }
 
+// MultiSig set the multisig account which sends the tx.
+// Signatures of members should be concatenated as the tx signature.
+func (b *Builder) MultiSig(ms *MultiSig) *Builder {
+	var data []byte
+	if ms != nil {
+		data, _ = rlp.EncodeToBytes(ms)
+	}
+	b.body.Reserved = setReserved(b.body.Reserved, reservedMultiSig, data)
+	return b
+}
+
+// Features set features.
+func (b *Builder) Features(features Features) *Builder {
+	b.body.Reserved = setReserved(b.body.Reserved, reservedFeatures, features.encode())
+	return b
+}
+
 // Build build tx object.
 func (b *Builder) Build() *Transaction {
-	tx := Transaction{body: b.body}
+	tx := Transaction{txType: b.txType, body: b.body}
 	return &tx
 }
@@ -0,0 +1,135 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package tx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Tx types.
+//
+// A legacy tx is encoded as a plain RLP list, where the trailing Plain flag
+// switches the signing hash layout. Other types are encoded as an RLP string
+// holding the type byte followed by the RLP encoded payload, so the decoder can
+// dispatch on the type without guessing.
+const (
+	TypeLegacy byte = 0x00
+	TypeNative byte = 0x01
+	TypePlain  byte = 0x02
+)
+
+var errUnsupportedType = errors.New("unsupported tx type")
+
+// nativeBody is the payload of TypeNative.
+type nativeBody struct {
+	ChainTag   byte
+	BlockRef   uint64
+	Expiration uint32
+	Clauses    []*Clause
+	Gas        uint64
+	DependsOn  *polo.Bytes32 `rlp:"nil"`
+	Nonce      uint64
+	Reserved   []interface{}
+	Signature  []byte
+}
+
+// plainBody is the payload of TypePlain, an Ethereum-like single call.
+type plainBody struct {
+	ChainTag  byte
+	To        *polo.Address `rlp:"nil"`
+	Value     *big.Int
+	Data      []byte
+	Gas       uint64
+	Nonce     uint64
+	Signature []byte
+}
+
+// IsTypeSupported returns whether the tx type is known.
+func IsTypeSupported(txType byte) bool {
+	switch txType {
+	case TypeLegacy, TypeNative, TypePlain:
+		return true
+	}
+	return false
+}
+
+// IsTyped returns whether the tx is encoded in typed envelope.
+// Typed txs are accepted since the TypedTx fork.
+func (t *Transaction) IsTyped() bool {
+	return t.txType != TypeLegacy
+}
+
+func encodeTyped(w io.Writer, txType byte, b *body) error {
+	var payload interface{}
+	switch txType {
+	case TypeNative:
+		payload = &nativeBody{
+			b.ChainTag, b.BlockRef, b.Expiration, b.Clauses, b.Gas,
+			b.DependsOn, b.Nonce, b.Reserved, b.Signature,
+		}
+	case TypePlain:
+		if len(b.Clauses) != 1 {
+			return errors.New("plain tx requires exactly one clause")
+		}
+		if len(b.Reserved) > 0 || b.DependsOn != nil || b.BlockRef != 0 || b.Expiration != 0 {
+			return errors.New("plain tx carries only one clause, gas and nonce")
+		}
+		clause := b.Clauses[0]
+		payload = &plainBody{
+			b.ChainTag, clause.body.To, clause.body.Value, clause.body.Data,
+			b.Gas, b.Nonce, b.Signature,
+		}
+	default:
+		return fmt.Errorf("%v: %v", errUnsupportedType, txType)
+	}
+	data, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		return err
+	}
+	return rlp.Encode(w, append([]byte{txType}, data...))
+}
+
+func decodeTyped(data []byte) (byte, *body, error) {
+	if len(data) == 0 {
+		return 0, nil, errors.New("empty typed tx")
+	}
+	switch txType, payload := data[0], data[1:]; txType {
+	case TypeNative:
+		var nb nativeBody
+		if err := rlp.DecodeBytes(payload, &nb); err != nil {
+			return 0, nil, err
+		}
+		return txType, &body{
+			ChainTag:   nb.ChainTag,
+			BlockRef:   nb.BlockRef,
+			Expiration: nb.Expiration,
+			Clauses:    nb.Clauses,
+			Gas:        nb.Gas,
+			DependsOn:  nb.DependsOn,
+			Nonce:      nb.Nonce,
+			Reserved:   nb.Reserved,
+			Signature:  nb.Signature,
+		}, nil
+	case TypePlain:
+		var pb plainBody
+		if err := rlp.DecodeBytes(payload, &pb); err != nil {
+			return 0, nil, err
+		}
+		return txType, &body{
+			ChainTag:  pb.ChainTag,
+			Clauses:   []*Clause{{clauseBody{pb.To, pb.Value, pb.Data}}},
+			Gas:       pb.Gas,
+			Nonce:     pb.Nonce,
+			Signature: pb.Signature,
+			Plain:     true,
+		}, nil
+	default:
+		return 0, nil, fmt.Errorf("%v: %v", errUnsupportedType, txType)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package tx_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/crypto"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvelope(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	to := polo.BytesToAddress([]byte("to"))
+	clause := tx.NewClause(&to).WithValue(big.NewInt(10)).WithData([]byte{1, 2})
+
+	build := func(txType byte) *tx.Transaction {
+		trx := new(tx.Builder).Type(txType).ChainTag(1).Clause(clause).Gas(21000).Nonce(1).Build()
+		sig, _ := crypto.Sign(trx.SigningHash().Bytes(), key)
+		return trx.WithSignature(sig)
+	}
+
+	hashes := make(map[polo.Bytes32]bool)
+	for _, txType := range []byte{tx.TypeLegacy, tx.TypeNative, tx.TypePlain} {
+		trx := build(txType)
+		hashes[trx.SigningHash()] = true
+
+		data, err := rlp.EncodeToBytes(trx)
+		assert.Nil(t, err)
+		assert.Equal(t, txType != tx.TypeLegacy, data[0] < 0xc0, "typed tx is encoded as rlp string")
+
+		var decoded *tx.Transaction
+		assert.Nil(t, rlp.DecodeBytes(data, &decoded))
+		assert.Equal(t, txType, decoded.Type())
+		assert.Equal(t, txType == tx.TypePlain, decoded.Plain())
+		assert.Equal(t, trx.ID(), decoded.ID())
+		assert.Equal(t, int(decoded.Size()), len(data))
+
+		signer, err := decoded.Signer()
+		assert.Nil(t, err)
+		assert.Equal(t, polo.Address(crypto.PubkeyToAddress(key.PublicKey)), signer)
+	}
+	assert.Equal(t, 3, len(hashes), "signing hash should differ by type")
+
+	_, err := rlp.EncodeToBytes(new(tx.Builder).Type(tx.TypePlain).Build())
+	assert.NotNil(t, err, "plain tx requires one clause")
+
+	var decoded *tx.Transaction
+	data, _ := rlp.EncodeToBytes([]byte{0x7f, 0xc0})
+	assert.NotNil(t, rlp.DecodeBytes(data, &decoded), "unknown type")
+}
@@ -0,0 +1,136 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package tx
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/HiNounou029/nounouchain/crypto"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// MaxMultiSigKeys is the max count of public keys in a multisig account.
+	MaxMultiSigKeys = 16
+	// SignatureLength is the length of a single signature, which depends on the crypto build.
+	SignatureLength = crypto.SigLen
+	// MultiSigVerifyGas is the intrinsic gas charged for each public key of a multisig account,
+	// which bounds cost of signature recoveries, as the ecrecover precompile.
+	MultiSigVerifyGas uint64 = 3000
+)
+
+var (
+	errMultiSigThreshold = errors.New("multisig: invalid threshold")
+	errMultiSigKeys      = errors.New("multisig: invalid public keys")
+)
+
+// MultiSig describes an M-of-N multisig account.
+// The account address is derived from threshold and public keys, so the
+// descriptor travels with each tx sent by the account.
+type MultiSig struct {
+	Threshold uint8
+	PubKeys   [][]byte // uncompressed public keys, sorted
+}
+
+// NewMultiSig creates a multisig descriptor. Order of keys does not matter.
+func NewMultiSig(threshold uint8, pubKeys []*ecdsa.PublicKey) (*MultiSig, error) {
+	ms := &MultiSig{Threshold: threshold}
+	for _, pub := range pubKeys {
+		ms.PubKeys = append(ms.PubKeys, crypto.FromECDSAPub(pub))
+	}
+	sort.Slice(ms.PubKeys, func(i, j int) bool {
+		return bytes.Compare(ms.PubKeys[i], ms.PubKeys[j]) < 0
+	})
+	if err := ms.Validate(); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+// Validate checks whether the descriptor is well formed.
+func (ms *MultiSig) Validate() error {
+	n := len(ms.PubKeys)
+	if n == 0 || n > MaxMultiSigKeys {
+		return errMultiSigKeys
+	}
+	if ms.Threshold == 0 || int(ms.Threshold) > n {
+		return errMultiSigThreshold
+	}
+	for i, key := range ms.PubKeys {
+		if i > 0 && bytes.Compare(ms.PubKeys[i-1], key) >= 0 {
+			return errMultiSigKeys // unsorted or duplicated
+		}
+		if _, err := crypto.UnmarshalPubkey(key); err != nil {
+			return errMultiSigKeys
+		}
+	}
+	return nil
+}
+
+// Address returns the account address of the multisig.
+func (ms *MultiSig) Address() polo.Address {
+	data, _ := rlp.EncodeToBytes(ms)
+	hash := polo.Blake2b([]byte("multisig"), data)
+	return polo.BytesToAddress(hash[12:])
+}
+
+// Member returns index of the key which produced the signature, or -1 if not a member.
+func (ms *MultiSig) Member(hash []byte, sig []byte) (int, error) {
+	pub, err := recoverPubkey(hash, sig)
+	if err != nil {
+		return -1, err
+	}
+	key := crypto.FromECDSAPub(pub)
+	for i, k := range ms.PubKeys {
+		if bytes.Equal(k, key) {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// Verify checks concatenated signatures against the hash.
+// It requires at least threshold signatures from distinct members.
+func (ms *MultiSig) Verify(hash []byte, sigs []byte) error {
+	if len(sigs) == 0 || len(sigs)%SignatureLength != 0 {
+		return errors.New("multisig: invalid signature length")
+	}
+	count := len(sigs) / SignatureLength
+	if count > len(ms.PubKeys) {
+		return errors.New("multisig: too many signatures")
+	}
+	signed := make(map[int]bool, count)
+	for i := 0; i < count; i++ {
+		index, err := ms.Member(hash, sigs[i*SignatureLength:(i+1)*SignatureLength])
+		if err != nil {
+			return err
+		}
+		if index < 0 {
+			return fmt.Errorf("multisig: signature #%v not from member", i)
+		}
+		if signed[index] {
+			return fmt.Errorf("multisig: signature #%v duplicated", i)
+		}
+		signed[index] = true
+	}
+	if len(signed) < int(ms.Threshold) {
+		return fmt.Errorf("multisig: insufficient signatures want %v, have %v", ms.Threshold, len(signed))
+	}
+	return nil
+}
+
+func decodeMultiSig(data []byte) (*MultiSig, error) {
+	var ms MultiSig
+	if err := rlp.DecodeBytes(data, &ms); err != nil {
+		return nil, err
+	}
+	if err := ms.Validate(); err != nil {
+		return nil, err
+	}
+	return &ms, nil
+}
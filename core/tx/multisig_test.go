@@ -0,0 +1,90 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package tx_test
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/crypto"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiSig(t *testing.T) {
+	var keys []*ecdsa.PrivateKey
+	var pubs []*ecdsa.PublicKey
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+		pubs = append(pubs, &key.PublicKey)
+	}
+
+	_, err := tx.NewMultiSig(0, pubs)
+	assert.NotNil(t, err)
+	_, err = tx.NewMultiSig(4, pubs)
+	assert.NotNil(t, err)
+	_, err = tx.NewMultiSig(2, append(pubs, pubs[0]))
+	assert.NotNil(t, err)
+
+	ms, err := tx.NewMultiSig(2, pubs)
+	assert.Nil(t, err)
+	reordered, _ := tx.NewMultiSig(2, []*ecdsa.PublicKey{pubs[2], pubs[0], pubs[1]})
+	assert.Equal(t, ms.Address(), reordered.Address())
+
+	to := polo.BytesToAddress([]byte("to"))
+	trx := new(tx.Builder).ChainTag(1).
+		Clause(tx.NewClause(&to).WithValue(big.NewInt(100))).
+		Gas(21000).
+		MultiSig(ms).
+		Build()
+	assert.False(t, trx.HasReservedFields(polo.ForkConfig{}, 0))
+	assert.True(t, trx.HasReservedFields(polo.ForkConfig{MultiSig: 10}, 9), "not recognized before fork")
+	assert.False(t, trx.HasReservedFields(polo.ForkConfig{MultiSig: 10}, 10))
+
+	plainGas, _ := new(tx.Builder).Clause(tx.NewClause(&to).WithValue(big.NewInt(100))).Build().IntrinsicGas()
+	gas, err := trx.IntrinsicGas()
+	assert.Nil(t, err)
+	assert.Equal(t, plainGas+3*tx.MultiSigVerifyGas, gas)
+
+	hash := trx.SigningHash().Bytes()
+	sig0, _ := crypto.Sign(hash, keys[0])
+	sig2, _ := crypto.Sign(hash, keys[2])
+	outsider, _ := crypto.GenerateKey()
+	sigX, _ := crypto.Sign(hash, outsider)
+
+	tests := []struct {
+		sigs   []byte
+		signer bool
+	}{
+		{sig0, false},
+		{append(append([]byte(nil), sig0...), sig0...), false},
+		{append(append([]byte(nil), sig0...), sigX...), false},
+		{append(append([]byte(nil), sig0...), sig2...), true},
+		{append(append([]byte(nil), sig2...), sig0...), true},
+	}
+	for _, tt := range tests {
+		signer, err := trx.WithSignature(tt.sigs).Signer()
+		if tt.signer {
+			assert.Nil(t, err)
+			assert.Equal(t, ms.Address(), signer)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+
+	// survive rlp round trip
+	data, _ := rlp.EncodeToBytes(trx.WithSignature(append(sig0, sig2...)))
+	var decoded *tx.Transaction
+	assert.Nil(t, rlp.DecodeBytes(data, &decoded))
+	signer, err := decoded.Signer()
+	assert.Nil(t, err)
+	assert.Equal(t, ms.Address(), signer)
+
+	// descriptor not covered by signing hash of plain tx
+	_, err = trx.WithPlain(true).MultiSig()
+	assert.NotNil(t, err)
+}
@@ -24,6 +24,9 @@ type Receipt struct {
 	Reverted bool
 	// outputs of clauses in tx
 	Outputs []*Output
+	// revert data of the failed clause, set since the RevertReason fork.
+	// It's in the rlp tail, so receipts without it are encoded as before.
+	RevertData [][]byte `rlp:"tail"`
 }
 
 // Output output of clause execution.
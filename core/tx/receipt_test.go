@@ -4,9 +4,13 @@ package tx_test
 
 import (
 	"fmt"
+	"math/big"
 	"testing"
 
 	. "github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestReceipt(t *testing.T) {
@@ -16,3 +20,33 @@ func TestReceipt(t *testing.T) {
 	var txs Transactions
 	fmt.Println(txs.RootHash())
 }
+
+func TestReceiptRevertData(t *testing.T) {
+	// receipt layout before revert data introduced
+	type legacyReceipt struct {
+		GasUsed  uint64
+		GasPayer polo.Address
+		Paid     *big.Int
+		Reward   *big.Int
+		Reverted bool
+		Outputs  []*Output
+	}
+	r := &Receipt{GasUsed: 1, Paid: big.NewInt(2), Reward: big.NewInt(3), Reverted: true}
+	legacy := &legacyReceipt{GasUsed: 1, Paid: big.NewInt(2), Reward: big.NewInt(3), Reverted: true}
+
+	data, _ := rlp.EncodeToBytes(r)
+	legacyData, _ := rlp.EncodeToBytes(legacy)
+	assert.Equal(t, legacyData, data, "should be encoded as before without revert data")
+
+	r.RevertData = [][]byte{{1, 2, 3}}
+	data, _ = rlp.EncodeToBytes(r)
+	assert.NotEqual(t, legacyData, data)
+
+	var decoded Receipt
+	assert.Nil(t, rlp.DecodeBytes(data, &decoded))
+	assert.Equal(t, r.RevertData, decoded.RevertData)
+
+	decoded = Receipt{}
+	assert.Nil(t, rlp.DecodeBytes(legacyData, &decoded))
+	assert.Empty(t, decoded.RevertData)
+}
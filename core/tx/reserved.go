@@ -0,0 +1,80 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package tx
+
+import (
+	"errors"
+	"math/big"
+)
+
+// slots of tx reserved fields. Each slot is a byte string, and empty means absent.
+const (
+	reservedMultiSig = iota
+	reservedFeatures
+	reservedSlots
+)
+
+// Features bitset of tx.
+type Features uint32
+
+const (
+	// DelegationFeature indicates the gas is paid by a delegator.
+	DelegationFeature Features = 1
+)
+
+// IsDelegated returns whether the delegation feature is set.
+func (f Features) IsDelegated() bool {
+	return f&DelegationFeature == DelegationFeature
+}
+
+// SetDelegated sets or clears the delegation feature.
+func (f *Features) SetDelegated(flag bool) {
+	if flag {
+		*f |= DelegationFeature
+	} else {
+		*f &^= DelegationFeature
+	}
+}
+
+func (f Features) encode() []byte {
+	return new(big.Int).SetUint64(uint64(f)).Bytes()
+}
+
+func decodeFeatures(data []byte) (Features, error) {
+	if len(data) > 4 || (len(data) > 0 && data[0] == 0) {
+		return 0, errors.New("malformed features")
+	}
+	return Features(new(big.Int).SetBytes(data).Uint64()), nil
+}
+
+// reservedSlot returns content of the slot, or nil if absent.
+func (t *Transaction) reservedSlot(slot int) ([]byte, error) {
+	if len(t.body.Reserved) <= slot {
+		return nil, nil
+	}
+	if t.body.Plain {
+		// reserved fields are not covered by signing hash of plain tx
+		return nil, errors.New("reserved fields not supported by plain tx")
+	}
+	data, ok := t.body.Reserved[slot].([]byte)
+	if !ok {
+		return nil, errors.New("malformed reserved field")
+	}
+	return data, nil
+}
+
+// setReserved sets content of the slot, and trims trailing absent slots.
+func setReserved(reserved []interface{}, slot int, data []byte) []interface{} {
+	reserved = append([]interface{}(nil), reserved...)
+	for len(reserved) <= slot {
+		reserved = append(reserved, []byte(nil))
+	}
+	reserved[slot] = data
+	for len(reserved) > 0 {
+		if last, ok := reserved[len(reserved)-1].([]byte); !ok || len(last) > 0 {
+			break
+		}
+		reserved = reserved[:len(reserved)-1]
+	}
+	return reserved
+}
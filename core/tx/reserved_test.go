@@ -0,0 +1,49 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package tx_test
+
+import (
+	"testing"
+
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/crypto"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelegation(t *testing.T) {
+	originKey, _ := crypto.GenerateKey()
+	delegatorKey, _ := crypto.GenerateKey()
+	origin := polo.Address(crypto.PubkeyToAddress(originKey.PublicKey))
+	delegator := polo.Address(crypto.PubkeyToAddress(delegatorKey.PublicKey))
+
+	var features tx.Features
+	features.SetDelegated(true)
+	trx := new(tx.Builder).ChainTag(1).Gas(21000).Features(features).Build()
+	assert.True(t, trx.Features().IsDelegated())
+	assert.False(t, trx.HasReservedFields(polo.ForkConfig{}, 0))
+	assert.True(t, trx.HasReservedFields(polo.ForkConfig{Delegation: 10}, 9), "not recognized before fork")
+
+	sig, _ := crypto.Sign(trx.SigningHash().Bytes(), originKey)
+	_, err := trx.WithSignature(sig).Signer()
+	assert.NotNil(t, err, "delegator signature missing")
+
+	dsig, _ := crypto.Sign(trx.DelegatorSigningHash(origin).Bytes(), delegatorKey)
+	trx = trx.WithSignature(append(sig, dsig...))
+
+	signer, err := trx.Signer()
+	assert.Nil(t, err)
+	assert.Equal(t, origin, signer)
+
+	payer, err := trx.Delegator()
+	assert.Nil(t, err)
+	assert.Equal(t, delegator, *payer)
+
+	unknown := new(tx.Builder).Features(tx.Features(2)).Build()
+	assert.True(t, unknown.HasReservedFields(polo.ForkConfig{}, 0))
+
+	plain := new(tx.Builder).Build()
+	payer, err = plain.Delegator()
+	assert.Nil(t, err)
+	assert.Nil(t, payer)
+}
@@ -0,0 +1,17 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+// +build !gm
+
+package tx
+
+import (
+	"crypto/ecdsa"
+
+	"github.com/HiNounou029/nounouchain/crypto"
+)
+
+// recoverPubkey returns the public key which produced the signature over hash.
+// A secp256k1 public key recovered from the signature implies its validity.
+func recoverPubkey(hash, sig []byte) (*ecdsa.PublicKey, error) {
+	return crypto.SigToPub(hash, sig)
+}
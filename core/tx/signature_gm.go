@@ -0,0 +1,25 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+// +build gm
+
+package tx
+
+import (
+	"crypto/ecdsa"
+	"errors"
+
+	"github.com/HiNounou029/nounouchain/crypto"
+)
+
+// recoverPubkey returns the public key which produced the signature over hash.
+// The sm2 public key is appended to the signature rather than recovered, so we have to verify the signature.
+func recoverPubkey(hash, sig []byte) (*ecdsa.PublicKey, error) {
+	pub, err := crypto.Ecrecover(hash, sig)
+	if err != nil {
+		return nil, err
+	}
+	if !crypto.VerifySignature(pub, hash, sig) {
+		return nil, errors.New("invalid signature")
+	}
+	return crypto.SigToPub(hash, sig)
+}
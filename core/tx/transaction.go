@@ -26,7 +26,8 @@ var (
 
 // Transaction is an immutable tx type.
 type Transaction struct {
-	body body
+	txType byte
+	body   body
 
 	cache struct {
 		signingHash  atomic.Value
@@ -52,6 +53,11 @@ type body struct {
 	Plain      bool
 }
 
+// Type returns type of tx.
+func (t *Transaction) Type() byte {
+	return t.txType
+}
+
 // ChainTag returns chain tag.
 func (t *Transaction) ChainTag() byte {
 	return t.body.ChainTag
@@ -111,6 +117,10 @@ func (t *Transaction) SigningHash() (hash polo.Bytes32) {
 	defer func() { t.cache.signingHash.Store(hash) }()
 
 	hw := polo.NewBlake2b()
+	if t.txType != TypeLegacy {
+		// separates signing hashes of different types
+		hw.Write([]byte{t.txType})
+	}
 	if t.body.Plain {
 		value := t.Clauses()[0].Value()
 		to := t.Clauses()[0].To()
@@ -179,9 +189,24 @@ func (t *Transaction) Signer() (signer polo.Address, err error) {
 		}
 	}()
 
+	sig, _, err := t.splitSignature()
+	if err != nil {
+		return polo.Address{}, err
+	}
+	ms, err := t.MultiSig()
+	if err != nil {
+		return polo.Address{}, err
+	}
+	if ms != nil {
+		if err := ms.Verify(t.SigningHash().Bytes(), sig); err != nil {
+			return polo.Address{}, err
+		}
+		return ms.Address(), nil
+	}
+
 	//	hash := t.SigningHash().Bytes()
 	//	fmt.Println("hash: ", hash)
-	pub, err := crypto.SigToPub(t.SigningHash().Bytes(), t.body.Signature)
+	pub, err := crypto.SigToPub(t.SigningHash().Bytes(), sig)
 	if err != nil {
 		return polo.Address{}, err
 	}
@@ -190,6 +215,64 @@ func (t *Transaction) Signer() (signer polo.Address, err error) {
 	return
 }
 
+// MultiSig returns the multisig descriptor if the tx is sent by a multisig account.
+func (t *Transaction) MultiSig() (*MultiSig, error) {
+	data, err := t.reservedSlot(reservedMultiSig)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	return decodeMultiSig(data)
+}
+
+// Features returns features of tx. Malformed features are reported by HasReservedFields.
+func (t *Transaction) Features() Features {
+	data, err := t.reservedSlot(reservedFeatures)
+	if err != nil {
+		return 0
+	}
+	features, _ := decodeFeatures(data)
+	return features
+}
+
+// DelegatorSigningHash returns hash for the delegator to sign.
+// It binds the origin, so the delegator pays only for the expected sender.
+func (t *Transaction) DelegatorSigningHash(origin polo.Address) polo.Bytes32 {
+	return polo.Blake2b(t.SigningHash().Bytes(), origin.Bytes())
+}
+
+// Delegator returns the gas payer of a delegated tx, or nil if not delegated.
+func (t *Transaction) Delegator() (*polo.Address, error) {
+	if !t.Features().IsDelegated() {
+		return nil, nil
+	}
+	origin, err := t.Signer()
+	if err != nil {
+		return nil, err
+	}
+	_, sig, err := t.splitSignature()
+	if err != nil {
+		return nil, err
+	}
+	pub, err := recoverPubkey(t.DelegatorSigningHash(origin).Bytes(), sig)
+	if err != nil {
+		return nil, err
+	}
+	delegator := polo.Address(crypto.PubkeyToAddress(*pub))
+	return &delegator, nil
+}
+
+// splitSignature splits signature into part of origin and part of delegator.
+func (t *Transaction) splitSignature() (origin []byte, delegator []byte, err error) {
+	if !t.Features().IsDelegated() {
+		return t.body.Signature, nil, nil
+	}
+	n := len(t.body.Signature)
+	if n <= SignatureLength {
+		return nil, nil, errors.New("delegator signature missing")
+	}
+	return t.body.Signature[:n-SignatureLength], t.body.Signature[n-SignatureLength:], nil
+}
+
 func (t *Transaction) Plain() bool {
 	return t.body.Plain
 }
@@ -197,35 +280,88 @@ func (t *Transaction) Plain() bool {
 // WithSignature create a new tx with signature set.
 func (t *Transaction) WithSignature(sig []byte) *Transaction {
 	newTx := Transaction{
-		body: t.body,
+		txType: t.txType,
+		body:   t.body,
 	}
 	// copy sig
 	newTx.body.Signature = append([]byte(nil), sig...)
 	return &newTx
 }
 
+// WithPlain create a new tx with the plain layout switched.
+// Typed tx is switched between TypeNative and TypePlain.
 func (t *Transaction) WithPlain(plain bool) *Transaction {
 	newTx := Transaction{
-		body: t.body,
+		txType: t.txType,
+		body:   t.body,
 	}
 	newTx.body.Plain = plain
+	if t.txType != TypeLegacy {
+		if plain {
+			newTx.txType = TypePlain
+		} else {
+			newTx.txType = TypeNative
+		}
+	}
 	return &newTx
 }
 
-// HasReservedFields returns if there're reserved fields.
-// Reserved fields are for backward compatibility purpose.
-func (t *Transaction) HasReservedFields() bool {
-	return len(t.body.Reserved) > 0
+// HasReservedFields returns if there're unrecognized reserved fields at the block number.
+// Reserved fields are for backward compatibility purpose, and recognized since forks.
+func (t *Transaction) HasReservedFields(forkConfig polo.ForkConfig, blockNum uint32) bool {
+	if blockNum < forkConfig.MultiSig {
+		return len(t.body.Reserved) > 0
+	}
+	slots := reservedSlots
+	if blockNum < forkConfig.Delegation {
+		slots = reservedFeatures
+	}
+	if len(t.body.Reserved) > slots {
+		return true
+	}
+	for i := range t.body.Reserved {
+		data, err := t.reservedSlot(i)
+		if err != nil {
+			return true
+		}
+		// absent trailing slot should be trimmed
+		if len(data) == 0 && i == len(t.body.Reserved)-1 {
+			return true
+		}
+	}
+	data, _ := t.reservedSlot(reservedFeatures)
+	features, err := decodeFeatures(data)
+	return err != nil || features&^DelegationFeature != 0
 }
 
 // EncodeRLP implements rlp.Encoder
 func (t *Transaction) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, &t.body)
+	if t.txType == TypeLegacy {
+		return rlp.Encode(w, &t.body)
+	}
+	return encodeTyped(w, t.txType, &t.body)
 }
 
 // DecodeRLP implements rlp.Decoder
 func (t *Transaction) DecodeRLP(s *rlp.Stream) error {
-	_, size, _ := s.Kind()
+	kind, size, err := s.Kind()
+	if err != nil {
+		return err
+	}
+	if kind != rlp.List {
+		data, err := s.Bytes()
+		if err != nil {
+			return err
+		}
+		txType, body, err := decodeTyped(data)
+		if err != nil {
+			return err
+		}
+		*t = Transaction{txType: txType, body: *body}
+		t.cache.size.Store(metric.StorageSize(rlp.ListSize(size)))
+		return nil
+	}
+
 	var body body
 	if err := s.Decode(&body); err != nil {
 		return err
@@ -257,6 +393,14 @@ func (t *Transaction) IntrinsicGas() (uint64, error) {
 	if err != nil {
 		return 0, err
 	}
+	// malformed descriptor is reported by Signer
+	if ms, _ := t.MultiSig(); ms != nil {
+		var overflow bool
+		gas, overflow = math.SafeAdd(gas, uint64(len(ms.PubKeys))*MultiSigVerifyGas)
+		if overflow {
+			return 0, errIntrinsicGasOverflow
+		}
+	}
 	t.cache.intrinsicGas.Store(gas)
 	return gas, nil
 }
@@ -283,6 +427,7 @@ func (t *Transaction) String() string {
 
 	return fmt.Sprintf(`
 	Tx(%v, %v)
+	Type:           %v
 	From:           %v
 	Clauses:        %v
 	Gas:            %v
@@ -292,7 +437,7 @@ func (t *Transaction) String() string {
 	DependsOn:      %v
 	Nonce:          %v
 	Signature:      0x%x
-`, t.ID(), t.Size(), from, t.body.Clauses, t.body.Gas,
+`, t.ID(), t.Size(), t.txType, from, t.body.Clauses, t.body.Gas,
 		t.body.ChainTag, br.Number(), br[4:], t.body.Expiration, dependsOn, t.body.Nonce, t.body.Signature)
 }
 
@@ -0,0 +1,160 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package txpool
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/polo"
+)
+
+// Policy is a business rule consulted before a tx is admitted into the pool.
+type Policy interface {
+	// Name returns the name used to identify the policy in rejection reasons.
+	Name() string
+	// Admit returns an error describing why the tx is refused, or nil to admit it.
+	Admit(tx *tx.Transaction, origin polo.Address) error
+}
+
+// PolicyFunc adapts a plain function to the Policy interface.
+type PolicyFunc struct {
+	PolicyName string
+	Func       func(tx *tx.Transaction, origin polo.Address) error
+}
+
+// Name implements Policy.
+func (f *PolicyFunc) Name() string {
+	return f.PolicyName
+}
+
+// Admit implements Policy.
+func (f *PolicyFunc) Admit(tx *tx.Transaction, origin polo.Address) error {
+	return f.Func(tx, origin)
+}
+
+// admitByPolicies runs policies in order and stops at the first rejection.
+func admitByPolicies(policies []Policy, tx *tx.Transaction, origin polo.Address) error {
+	for _, policy := range policies {
+		if err := policy.Admit(tx, origin); err != nil {
+			return txRejectedError{fmt.Sprintf("policy %v: %v", policy.Name(), err)}
+		}
+	}
+	return nil
+}
+
+type addressSet map[polo.Address]struct{}
+
+func newAddressSet(addrs []polo.Address) addressSet {
+	set := make(addressSet, len(addrs))
+	for _, addr := range addrs {
+		set[addr] = struct{}{}
+	}
+	return set
+}
+
+func (s addressSet) contains(addr polo.Address) bool {
+	_, found := s[addr]
+	return found
+}
+
+// originAllowPolicy admits only txs sent by listed origins.
+type originAllowPolicy struct {
+	allowed addressSet
+}
+
+// NewOriginAllowPolicy creates a policy that admits txs only from the given origins.
+func NewOriginAllowPolicy(origins []polo.Address) Policy {
+	return &originAllowPolicy{newAddressSet(origins)}
+}
+
+func (p *originAllowPolicy) Name() string { return "allow-origins" }
+
+func (p *originAllowPolicy) Admit(tx *tx.Transaction, origin polo.Address) error {
+	if !p.allowed.contains(origin) {
+		return fmt.Errorf("origin %v not allowed", origin)
+	}
+	return nil
+}
+
+// originDenyPolicy refuses txs sent by listed origins.
+type originDenyPolicy struct {
+	denied addressSet
+}
+
+// NewOriginDenyPolicy creates a policy that refuses txs from the given origins.
+func NewOriginDenyPolicy(origins []polo.Address) Policy {
+	return &originDenyPolicy{newAddressSet(origins)}
+}
+
+func (p *originDenyPolicy) Name() string { return "deny-origins" }
+
+func (p *originDenyPolicy) Admit(tx *tx.Transaction, origin polo.Address) error {
+	if p.denied.contains(origin) {
+		return fmt.Errorf("origin %v denied", origin)
+	}
+	return nil
+}
+
+// clauseValuePolicy caps the value carried by each clause.
+type clauseValuePolicy struct {
+	max *big.Int
+}
+
+// NewClauseValuePolicy creates a policy that refuses txs with any clause value above max.
+func NewClauseValuePolicy(max *big.Int) Policy {
+	return &clauseValuePolicy{new(big.Int).Set(max)}
+}
+
+func (p *clauseValuePolicy) Name() string { return "max-clause-value" }
+
+func (p *clauseValuePolicy) Admit(tx *tx.Transaction, origin polo.Address) error {
+	for i, clause := range tx.Clauses() {
+		if clause.Value().Cmp(p.max) > 0 {
+			return fmt.Errorf("clause #%v value exceeds %v", i, p.max)
+		}
+	}
+	return nil
+}
+
+// targetBanPolicy refuses txs with clauses targeting banned addresses.
+type targetBanPolicy struct {
+	banned addressSet
+}
+
+// NewTargetBanPolicy creates a policy that refuses txs calling any of the given addresses.
+func NewTargetBanPolicy(targets []polo.Address) Policy {
+	return &targetBanPolicy{newAddressSet(targets)}
+}
+
+func (p *targetBanPolicy) Name() string { return "banned-targets" }
+
+func (p *targetBanPolicy) Admit(tx *tx.Transaction, origin polo.Address) error {
+	for i, clause := range tx.Clauses() {
+		if to := clause.To(); to != nil && p.banned.contains(*to) {
+			return fmt.Errorf("clause #%v targets banned address %v", i, *to)
+		}
+	}
+	return nil
+}
+
+// certPolicy admits only origins associated with a valid certificate.
+type certPolicy struct {
+	certified addressSet
+}
+
+// NewCertPolicy creates a policy that admits txs only from origins holding a
+// certificate. The certificates should be verified by the caller.
+func NewCertPolicy(certified []polo.Address) Policy {
+	return &certPolicy{newAddressSet(certified)}
+}
+
+func (p *certPolicy) Name() string { return "require-cert" }
+
+func (p *certPolicy) Admit(tx *tx.Transaction, origin polo.Address) error {
+	if !p.certified.contains(origin) {
+		return fmt.Errorf("origin %v has no valid certificate", origin)
+	}
+	return nil
+}
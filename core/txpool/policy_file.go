@@ -0,0 +1,131 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package txpool
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/HiNounou029/nounouchain/caclient"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/pkg/errors"
+)
+
+// PolicyConfig is the JSON layout of the admission policy file.
+//
+//	{
+//	  "allowOrigins": ["0x..."],
+//	  "denyOrigins": ["0x..."],
+//	  "maxClauseValue": "1000000000000000000000",
+//	  "bannedTargets": ["0x..."],
+//	  "requireCert": {
+//	    "rootCA": "cacerts/rootca.pem",
+//	    "certs": {"0x...": "signcerts/cert.pem"}
+//	  }
+//	}
+//
+// Relative paths are resolved against the directory of the policy file.
+type PolicyConfig struct {
+	AllowOrigins   []polo.Address        `json:"allowOrigins"`
+	DenyOrigins    []polo.Address        `json:"denyOrigins"`
+	MaxClauseValue *math.HexOrDecimal256 `json:"maxClauseValue"`
+	BannedTargets  []polo.Address        `json:"bannedTargets"`
+	RequireCert    *CertPolicyConfig     `json:"requireCert"`
+}
+
+// CertPolicyConfig maps origins to certificates issued by the CA.
+type CertPolicyConfig struct {
+	RootCA string            `json:"rootCA"`
+	Certs  map[string]string `json:"certs"`
+}
+
+// LoadPolicyFile reads the policy file and builds built-in policies from it.
+func LoadPolicyFile(path string) ([]Policy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg PolicyConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, errors.WithMessage(err, "unmarshal")
+	}
+	return cfg.Build(filepath.Dir(path))
+}
+
+// Build creates policies from config. baseDir is used to resolve relative paths.
+func (cfg *PolicyConfig) Build(baseDir string) ([]Policy, error) {
+	var policies []Policy
+	if len(cfg.DenyOrigins) > 0 {
+		policies = append(policies, NewOriginDenyPolicy(cfg.DenyOrigins))
+	}
+	if len(cfg.AllowOrigins) > 0 {
+		policies = append(policies, NewOriginAllowPolicy(cfg.AllowOrigins))
+	}
+	if cfg.MaxClauseValue != nil {
+		policies = append(policies, NewClauseValuePolicy((*big.Int)(cfg.MaxClauseValue)))
+	}
+	if len(cfg.BannedTargets) > 0 {
+		policies = append(policies, NewTargetBanPolicy(cfg.BannedTargets))
+	}
+	if cfg.RequireCert != nil {
+		certified, err := cfg.RequireCert.verify(baseDir)
+		if err != nil {
+			return nil, errors.WithMessage(err, "requireCert")
+		}
+		policies = append(policies, NewCertPolicy(certified))
+	}
+	return policies, nil
+}
+
+// verify checks certificates against the root CA, and returns origins with valid ones.
+// Invalid or expired certificates are logged and skipped.
+func (cfg *CertPolicyConfig) verify(baseDir string) ([]polo.Address, error) {
+	if cfg.RootCA == "" {
+		return nil, errors.New("rootCA required")
+	}
+	rootCA := resolvePath(baseDir, cfg.RootCA)
+	certified := make([]polo.Address, 0, len(cfg.Certs))
+	for addr, certPath := range cfg.Certs {
+		origin, err := polo.ParseAddress(addr)
+		if err != nil {
+			return nil, errors.WithMessage(err, addr)
+		}
+		certBuf, err := ioutil.ReadFile(resolvePath(baseDir, certPath))
+		if err != nil {
+			return nil, err
+		}
+		if err := caclient.ValCert(rootCA, certBuf); err != nil {
+			log.Warn("skip invalid certificate", "origin", origin, "err", err)
+			continue
+		}
+		certified = append(certified, origin)
+	}
+	return certified, nil
+}
+
+func resolvePath(baseDir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// policyFile tracks modification of the policy file, for hot reloading.
+type policyFile struct {
+	path    string
+	modTime time.Time
+}
+
+// changed returns whether the file was modified since last loaded, and its modification time.
+func (f *policyFile) changed() (time.Time, bool, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return info.ModTime(), !info.ModTime().Equal(f.modTime), nil
+}
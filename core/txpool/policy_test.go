@@ -0,0 +1,122 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package txpool
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/nounou/genesis"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/HiNounou029/nounouchain/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltinPolicies(t *testing.T) {
+	acc0 := genesis.DevAccounts()[0].Address
+	acc1 := genesis.DevAccounts()[1].Address
+	target := polo.BytesToAddress([]byte("target"))
+
+	clause := tx.NewClause(&target).WithValue(big.NewInt(100))
+	trx := new(tx.Builder).Clause(clause).Build()
+
+	tests := []struct {
+		policy Policy
+		origin polo.Address
+		admit  bool
+	}{
+		{NewOriginAllowPolicy([]polo.Address{acc0}), acc0, true},
+		{NewOriginAllowPolicy([]polo.Address{acc0}), acc1, false},
+		{NewOriginDenyPolicy([]polo.Address{acc0}), acc0, false},
+		{NewOriginDenyPolicy([]polo.Address{acc0}), acc1, true},
+		{NewClauseValuePolicy(big.NewInt(100)), acc0, true},
+		{NewClauseValuePolicy(big.NewInt(99)), acc0, false},
+		{NewTargetBanPolicy([]polo.Address{target}), acc0, false},
+		{NewTargetBanPolicy([]polo.Address{acc1}), acc0, true},
+		{NewCertPolicy([]polo.Address{acc0}), acc0, true},
+		{NewCertPolicy([]polo.Address{acc0}), acc1, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.policy.Admit(trx, tt.origin)
+		assert.Equal(t, tt.admit, err == nil, tt.policy.Name())
+	}
+}
+
+func TestLoadPolicyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "txpool-policy")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "policy.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{
+		"denyOrigins": ["0x0000000000000000000000000000000000000001"],
+		"maxClauseValue": "0x64",
+		"bannedTargets": ["0x0000000000000000000000000000000000000002"]
+	}`), 0600))
+
+	policies, err := LoadPolicyFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(policies))
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"requireCert": {}}`), 0600))
+	_, err = LoadPolicyFile(path)
+	assert.NotNil(t, err)
+}
+
+func TestPolicyFileReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "txpool-policy")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	kv, _ := storage.NewMem()
+	chain := newChain(kv)
+	path := filepath.Join(dir, "policy.json")
+	options := Options{Limit: 10, LimitPerAccount: 2, MaxLifetime: time.Hour, PolicyFile: path}
+
+	_, err = New(chain, state.NewCreator(kv), options)
+	assert.NotNil(t, err, "missing policy file should fail")
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"requireCert": {}}`), 0600))
+	_, err = New(chain, state.NewCreator(kv), options)
+	assert.NotNil(t, err, "invalid policy file should fail")
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"denyOrigins": ["0x0000000000000000000000000000000000000001"]}`), 0600))
+	pool, err := New(chain, state.NewCreator(kv), options)
+	assert.Nil(t, err)
+	defer pool.Close()
+	assert.Equal(t, 1, len(pool.Policies()))
+
+	// broken edit keeps previous policies, and is retried until fixed
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{`), 0600))
+	assert.Nil(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+	assert.NotNil(t, pool.reloadPolicies())
+	assert.NotNil(t, pool.reloadPolicies())
+	assert.Equal(t, 1, len(pool.Policies()))
+}
+
+func TestAddWithPolicy(t *testing.T) {
+	pool := newPool()
+	defer pool.Close()
+
+	acc := genesis.DevAccounts()[0]
+	pool.AddPolicy(NewOriginDenyPolicy([]polo.Address{acc.Address}))
+	pool.AddPolicy(&PolicyFunc{"never", func(*tx.Transaction, polo.Address) error {
+		return errors.New("never admit")
+	}})
+
+	err := pool.Add(newTx(pool.chain.Tag(), nil, 21000, tx.BlockRef{}, 100, nil, acc))
+	assert.True(t, IsTxRejected(err))
+	assert.Contains(t, err.Error(), "policy deny-origins")
+
+	err = pool.Add(newTx(pool.chain.Tag(), nil, 21000, tx.BlockRef{}, 100, nil, genesis.DevAccounts()[1]))
+	assert.True(t, IsTxRejected(err))
+	assert.Equal(t, "tx rejected: policy never: never admit", err.Error())
+}
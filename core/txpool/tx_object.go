@@ -11,6 +11,7 @@ import (
 	"github.com/HiNounou029/nounouchain/core/block"
 	"github.com/HiNounou029/nounouchain/core/chain"
 	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/nounou/builtin"
 	"github.com/HiNounou029/nounouchain/state"
 	"github.com/HiNounou029/nounouchain/vm/runtime"
 	"github.com/pkg/errors"
@@ -80,9 +81,16 @@ func (o *txObject) Executable(chain *chain.Chain, state *state.State, headBlock
 	checkpoint := state.NewCheckpoint()
 	defer state.RevertTo(checkpoint)
 
-	if _, _, err := o.resolved.BuyGas(state); err != nil {
+	if _, _, _, err := o.resolved.BuyGas(state, polo.GetForkConfig(chain.GenesisBlock().Header().ID()), headBlock.Number()+1, headBlock.Timestamp()); err != nil {
 		return false, err
 	}
+
+	compliance := builtin.Compliance.Native(state)
+	for _, clause := range o.resolved.Clauses {
+		if to := clause.To(); to != nil && clause.Value().Sign() != 0 && compliance.IsFrozen(*to) {
+			return false, errors.Errorf("account frozen, recipient: %s", to.String())
+		}
+	}
 	return true, nil
 }
 
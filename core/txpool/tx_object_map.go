@@ -64,6 +64,28 @@ func (m *txObjectMap) Remove(txID polo.Bytes32) bool {
 	return false
 }
 
+// Get returns the tx object by ID, or nil if not found.
+func (m *txObjectMap) Get(txID polo.Bytes32) *txObject {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.txObjMap[txID]
+}
+
+// Clear removes all tx objects, and returns removed ones.
+func (m *txObjectMap) Clear() []*txObject {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	txObjs := make([]*txObject, 0, len(m.txObjMap))
+	for _, txObj := range m.txObjMap {
+		txObjs = append(txObjs, txObj)
+	}
+	m.txObjMap = make(map[polo.Bytes32]*txObject)
+	m.quota = make(map[polo.Address]int)
+	return txObjs
+}
+
 func (m *txObjectMap) ToTxObjects() []*txObject {
 	m.lock.RLock()
 	defer m.lock.RUnlock()
@@ -5,12 +5,14 @@ package txpool
 import (
 	"fmt"
 	"github.com/HiNounou029/nounouchain/common/metric"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/HiNounou029/nounouchain/polo"
 	"github.com/HiNounou029/nounouchain/nounou/builtin"
 	"github.com/HiNounou029/nounouchain/common/co"
+	"github.com/HiNounou029/nounouchain/common/ratelimit"
 	"github.com/HiNounou029/nounouchain/core/block"
 	"github.com/HiNounou029/nounouchain/core/chain"
 	"github.com/HiNounou029/nounouchain/core/tx"
@@ -29,6 +31,14 @@ import (
 */
 var (
 	log = log15.New("pkg", "txpool")
+
+	rejectedTxsCounter    = metric.NewCounter("txpool_rejected_txs", "txs rejected by tx pool")
+	rateLimitedTxsCounter = metric.NewCounter("txpool_rate_limited_txs", "txs rejected due to origin rate limit")
+	washesCounter         = metric.NewCounter("txpool_washes", "washes done by tx pool")
+	washedTxsCounter      = metric.NewCounter("txpool_washed_txs", "txs washed out of tx pool")
+	washDuration          = metric.NewHistogram("txpool_wash_duration_seconds", "duration of washing tx pool", nil)
+	txsGauge              = metric.NewGauge("txpool_txs", "txs in tx pool")
+	executableTxsGauge    = metric.NewGauge("txpool_executable_txs", "executable txs in tx pool")
 )
 
 // Options options for tx pool.
@@ -36,12 +46,21 @@ type Options struct {
 	Limit           int
 	LimitPerAccount int
 	MaxLifetime     time.Duration
+	// PolicyFile is the path of admission policy file, empty to disable.
+	// The file is reloaded when modified.
+	PolicyFile string
+	// OriginRateLimit is the max count of txs per second accepted from one origin, 0 to disable.
+	OriginRateLimit float64
+	// OriginRateBurst is the max count of txs accepted from one origin in a burst.
+	OriginRateBurst int
 }
 
-// TxEvent will be posted when tx is added or status changed.
+// TxEvent will be posted when tx is added, status changed or removed.
 type TxEvent struct {
 	Tx         *tx.Transaction
 	Executable *bool
+	// Removed is true if tx removed from pool, and Executable is nil then.
+	Removed bool
 }
 
 // TxPool maintains unprocessed transactions.
@@ -49,29 +68,92 @@ type TxPool struct {
 	options      Options
 	chain        *chain.Chain
 	stateCreator *state.Creator
+	forkConfig   polo.ForkConfig
 
 	executables    atomic.Value
 	all            *txObjectMap
 	addedAfterWash uint32
 
-	done   chan struct{}
-	txFeed event.Feed
-	scope  event.SubscriptionScope
-	goes   co.Goes
+	policyFile     *policyFile
+	filePolicies   atomic.Value
+	customPolicies atomic.Value
+	policyLock     sync.Mutex
+	originLimiter  *ratelimit.Limiter
+
+	done    chan struct{}
+	washCh  chan struct{}
+	flushCh chan chan int
+	txFeed  event.Feed
+	scope   event.SubscriptionScope
+	goes    co.Goes
+
+	// events queued to be sent in order
+	eventsLock sync.Mutex
+	events     []*TxEvent
+	eventsCh   chan struct{}
 }
 
 // New create a new TxPool instance.
+// An error is returned if the policy file can't be loaded, rather than running without policies.
 // Shutdown is required to be called at end.
-func New(chain *chain.Chain, stateCreator *state.Creator, options Options) *TxPool {
+func New(chain *chain.Chain, stateCreator *state.Creator, options Options) (*TxPool, error) {
 	pool := &TxPool{
-		options:      options,
-		chain:        chain,
-		stateCreator: stateCreator,
-		all:          newTxObjectMap(),
-		done:         make(chan struct{}),
+		options:       options,
+		chain:         chain,
+		stateCreator:  stateCreator,
+		forkConfig:    polo.GetForkConfig(chain.GenesisBlock().Header().ID()),
+		all:           newTxObjectMap(),
+		done:          make(chan struct{}),
+		washCh:        make(chan struct{}, 1),
+		flushCh:       make(chan chan int),
+		eventsCh:      make(chan struct{}, 1),
+		originLimiter: ratelimit.New(options.OriginRateLimit, options.OriginRateBurst),
+	}
+	if options.PolicyFile != "" {
+		pool.policyFile = &policyFile{path: options.PolicyFile}
+		if err := pool.reloadPolicies(); err != nil {
+			return nil, err
+		}
 	}
 	pool.goes.Go(pool.housekeeping)
-	return pool
+	pool.goes.Go(pool.sendEvents)
+	return pool, nil
+}
+
+// AddPolicy appends a custom admission policy, which is consulted after policies from file.
+func (p *TxPool) AddPolicy(policy Policy) {
+	p.policyLock.Lock()
+	defer p.policyLock.Unlock()
+
+	custom, _ := p.customPolicies.Load().([]Policy)
+	p.customPolicies.Store(append(append([]Policy(nil), custom...), policy))
+}
+
+// Policies returns admission policies in effect.
+func (p *TxPool) Policies() []Policy {
+	file, _ := p.filePolicies.Load().([]Policy)
+	custom, _ := p.customPolicies.Load().([]Policy)
+	return append(append([]Policy(nil), file...), custom...)
+}
+
+// reloadPolicies loads policies from file if modified.
+// On failure, policies previously loaded are kept, and the file is retried on next call.
+func (p *TxPool) reloadPolicies() error {
+	modTime, changed, err := p.policyFile.changed()
+	if err != nil {
+		return errors.WithMessage(err, "stat policy file")
+	}
+	if !changed {
+		return nil
+	}
+	policies, err := LoadPolicyFile(p.policyFile.path)
+	if err != nil {
+		return errors.WithMessage(err, "load policy file")
+	}
+	p.filePolicies.Store(policies)
+	p.policyFile.modTime = modTime
+	log.Info("admission policies loaded", "path", p.policyFile.path, "count", len(policies))
+	return nil
 }
 
 func (p *TxPool) housekeeping() {
@@ -88,6 +170,11 @@ func (p *TxPool) housekeeping() {
 		case <-p.done:
 			return
 		case <-ticker.C:
+			if p.policyFile != nil {
+				if err := p.reloadPolicies(); err != nil {
+					log.Warn("failed to reload policies", "path", p.policyFile.path, "err", err)
+				}
+			}
 			var headBlockChanged bool
 			if newHeadBlock := p.chain.BestBlock().Header(); newHeadBlock.ID() != headBlock.ID() {
 				headBlock = newHeadBlock
@@ -105,27 +192,51 @@ func (p *TxPool) housekeeping() {
 			if headBlockChanged ||
 				poolLen > p.options.Limit ||
 				(poolLen < 200 && atomic.LoadUint32(&p.addedAfterWash) > 0) {
+				p.doWash(headBlock)
+			}
+		case <-p.washCh:
+			headBlock = p.chain.BestBlock().Header()
+			p.doWash(headBlock)
+		case removed := <-p.flushCh:
+			removed <- p.doFlush()
+		}
+	}
+}
 
-				atomic.StoreUint32(&p.addedAfterWash, 0)
+// doWash washes the pool and updates executables.
+func (p *TxPool) doWash(headBlock *block.Header) {
+	atomic.StoreUint32(&p.addedAfterWash, 0)
 
-				startTime := mclock.Now()
-				executables, removed, err := p.wash(headBlock)
-				elapsed := mclock.Now() - startTime
+	poolLen := p.all.Len()
+	startTime := mclock.Now()
+	executables, removed, err := p.wash(headBlock)
+	elapsed := mclock.Now() - startTime
 
-				ctx := []interface{}{
-					"len", poolLen,
-					"removed", removed,
-					"elapsed", common.PrettyDuration(elapsed),
-				}
-				if err != nil {
-					ctx = append(ctx, "err", err)
-				} else {
-					p.executables.Store(executables)
-				}
+	ctx := []interface{}{
+		"len", poolLen,
+		"removed", removed,
+		"elapsed", common.PrettyDuration(elapsed),
+	}
+	if err != nil {
+		ctx = append(ctx, "err", err)
+	} else {
+		p.executables.Store(executables)
+		executableTxsGauge.Set(float64(len(executables)))
+	}
+	washesCounter.Inc()
+	washedTxsCounter.Add(uint64(removed))
+	washDuration.ObserveDuration(time.Duration(elapsed))
+	txsGauge.Set(float64(p.all.Len()))
 
-				log.Debug("wash done", ctx...)
-			}
-		}
+	log.Debug("wash done", ctx...)
+}
+
+// Wash triggers washing the pool immediately, even if the chain is not synced.
+func (p *TxPool) Wash() {
+	select {
+	case p.washCh <- struct{}{}:
+	default:
+		// already triggered
 	}
 }
 
@@ -142,19 +253,29 @@ func (p *TxPool) SubscribeTxEvent(ch chan *TxEvent) event.Subscription {
 	return p.scope.Track(p.txFeed.Subscribe(ch))
 }
 
-func (p *TxPool) add(newTx *tx.Transaction, rejectNonexecutable bool) error {
+func (p *TxPool) add(newTx *tx.Transaction, rejectNonexecutable bool) (err error) {
+	defer func() {
+		if err != nil {
+			rejectedTxsCounter.Inc()
+		}
+	}()
+
 	if p.all.Contains(newTx.ID()) {
 		// tx already in the pool
 		return nil
 	}
 
+	headBlock := p.chain.BestBlock().Header()
+
 	// validation
 	switch {
 	case newTx.ChainTag() != p.chain.Tag():
 		errMsg := fmt.Sprintf("chain tag mismatch, want %v, have %v", p.chain.Tag(), newTx.ChainTag())
 		return badTxError{ errMsg }
-	case newTx.HasReservedFields():
+	case newTx.HasReservedFields(p.forkConfig, headBlock.Number()+1):
 		return badTxError{"reserved fields not empty"}
+	case newTx.IsTyped() && headBlock.Number()+1 < p.forkConfig.TypedTx:
+		return badTxError{"tx type unsupported"}
 	case newTx.Size() > metric.StorageSize(polo.Conf.TxSizeLimit):
 		return txRejectedError{"size too large"}
 	}
@@ -164,7 +285,15 @@ func (p *TxPool) add(newTx *tx.Transaction, rejectNonexecutable bool) error {
 		return badTxError{err.Error()}
 	}
 
-	headBlock := p.chain.BestBlock().Header()
+	if err := admitByPolicies(p.Policies(), newTx, txObj.Origin()); err != nil {
+		return err
+	}
+
+	if !p.originLimiter.Allow(txObj.Origin()) {
+		rateLimitedTxsCounter.Inc()
+		return txRejectedError{"origin rate limit exceeded"}
+	}
+
 	if isChainSynced(uint64(time.Now().Unix()), headBlock.Timestamp()) {
 		state, err := p.stateCreator.NewState(headBlock.StateRoot())
 		if err != nil {
@@ -185,9 +314,7 @@ func (p *TxPool) add(newTx *tx.Transaction, rejectNonexecutable bool) error {
 		}
 
 		txObj.executable = executable
-		p.goes.Go(func() {
-			p.txFeed.Send(&TxEvent{newTx, &executable})
-		})
+		p.postEvents(&TxEvent{Tx: newTx, Executable: &executable})
 		//		log.Debug("tx added", "id", newTx.ID(), "executable", executable)
 	} else {
 		// we skip steps that rely on head block when chain is not synced,
@@ -200,7 +327,7 @@ func (p *TxPool) add(newTx *tx.Transaction, rejectNonexecutable bool) error {
 			return txRejectedError{err.Error()}
 		}
 		//		log.Debug("tx added", "id", newTx.ID())
-		p.txFeed.Send(&TxEvent{newTx, nil})
+		p.postEvents(&TxEvent{Tx: newTx})
 	}
 	atomic.AddUint32(&p.addedAfterWash, 1)
 	return nil
@@ -219,13 +346,77 @@ func (p *TxPool) StrictlyAdd(newTx *tx.Transaction) error {
 
 // Remove removes tx from pool by its ID.
 func (p *TxPool) Remove(txID polo.Bytes32) bool {
-	if p.all.Remove(txID) {
+	if txObj := p.all.Get(txID); txObj != nil && p.all.Remove(txID) {
 		//		log.Debug("tx removed", "id", txID)
+		p.notifyRemoved([]*txObject{txObj})
 		return true
 	}
 	return false
 }
 
+// Flush removes all txs from pool, and returns count of removed.
+// It's done by the housekeeping routine, so that a wash in progress won't restore executables.
+func (p *TxPool) Flush() int {
+	removed := make(chan int, 1)
+	select {
+	case p.flushCh <- removed:
+		return <-removed
+	case <-p.done:
+		return 0
+	}
+}
+
+func (p *TxPool) doFlush() int {
+	txObjs := p.all.Clear()
+	p.executables.Store(tx.Transactions(nil))
+	p.notifyRemoved(txObjs)
+	return len(txObjs)
+}
+
+// notifyRemoved posts events of removed txs.
+func (p *TxPool) notifyRemoved(txObjs []*txObject) {
+	events := make([]*TxEvent, 0, len(txObjs))
+	for _, txObj := range txObjs {
+		events = append(events, &TxEvent{Tx: txObj.Transaction, Removed: true})
+	}
+	p.postEvents(events...)
+}
+
+// postEvents queues events to be sent by the sendEvents routine, so that the caller is never
+// blocked by subscribers, and subscribers receive events in the order of pool changes.
+func (p *TxPool) postEvents(events ...*TxEvent) {
+	if len(events) == 0 {
+		return
+	}
+	p.eventsLock.Lock()
+	p.events = append(p.events, events...)
+	p.eventsLock.Unlock()
+
+	select {
+	case p.eventsCh <- struct{}{}:
+	default:
+		// already signaled
+	}
+}
+
+func (p *TxPool) sendEvents() {
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-p.eventsCh:
+			p.eventsLock.Lock()
+			events := p.events
+			p.events = nil
+			p.eventsLock.Unlock()
+
+			for _, ev := range events {
+				p.txFeed.Send(ev)
+			}
+		}
+	}
+}
+
 // Executables returns executable txs.
 func (p *TxPool) Executables() tx.Transactions {
 	if sorted := p.executables.Load(); sorted != nil {
@@ -237,10 +428,13 @@ func (p *TxPool) Executables() tx.Transactions {
 // Fill fills txs into pool.
 func (p *TxPool) Fill(txs tx.Transactions) {
 	txObjs := make([]*txObject, 0, len(txs))
+	policies := p.Policies()
 	for _, tx := range txs {
 		// here we ignore errors
 		if txObj, err := resolveTx(tx); err == nil {
-			txObjs = append(txObjs, txObj)
+			if admitByPolicies(policies, tx, txObj.Origin()) == nil {
+				txObjs = append(txObjs, txObj)
+			}
 		}
 	}
 	p.all.Fill(txObjs)
@@ -255,23 +449,26 @@ func (p *TxPool) Dump() tx.Transactions {
 // this method should only be called in housekeeping go routine
 func (p *TxPool) wash(headBlock *block.Header) (executables tx.Transactions, removed int, err error) {
 	all := p.all.ToTxObjects()
-	var toRemove []polo.Bytes32
+	var toRemove []*txObject
 	defer func() {
 		if err != nil {
 			// in case of error, simply cut pool size to limit
+			toRemove = nil
 			for i, txObj := range all {
 				if len(all)-i <= p.options.Limit {
 					break
 				}
 				removed++
 				p.all.Remove(txObj.ID())
+				toRemove = append(toRemove, txObj)
 			}
 		} else {
-			for _, id := range toRemove {
-				p.all.Remove(id)
+			for _, txObj := range toRemove {
+				p.all.Remove(txObj.ID())
 			}
 			removed = len(toRemove)
 		}
+		p.notifyRemoved(toRemove)
 	}()
 
 	state, err := p.stateCreator.NewState(headBlock.StateRoot())
@@ -284,6 +481,7 @@ func (p *TxPool) wash(headBlock *block.Header) (executables tx.Transactions, rem
 		executableObjs    = make([]*txObject, 0, len(all))
 		nonExecutableObjs = make([]*txObject, 0, len(all))
 		now               = time.Now().UnixNano()
+		policies          = p.Policies()
 	)
 	for _, txObj := range all {
 
@@ -295,14 +493,20 @@ func (p *TxPool) wash(headBlock *block.Header) (executables tx.Transactions, rem
 
 		// out of lifetime
 		if now > txObj.timeAdded+int64(p.options.MaxLifetime) {
-			toRemove = append(toRemove, txObj.ID())
+			toRemove = append(toRemove, txObj)
 			log.Debug("tx washed out", "id", txObj.ID(), "err", "out of lifetime")
 			continue
 		}
+		// no longer admitted since policies changed
+		if err := admitByPolicies(policies, txObj.Transaction, txObj.Origin()); err != nil {
+			toRemove = append(toRemove, txObj)
+			log.Debug("tx washed out", "id", txObj.ID(), "err", err)
+			continue
+		}
 		// settled, out of energy or dep broken
 		executable, err := txObj.Executable(p.chain, state, headBlock)
 		if err != nil {
-			toRemove = append(toRemove, txObj.ID())
+			toRemove = append(toRemove, txObj)
 			//			log.Debug("tx washed out", "id", txObj.ID(), "err", err)
 			continue
 		}
@@ -336,18 +540,18 @@ func (p *TxPool) wash(headBlock *block.Header) (executables tx.Transactions, rem
 	// remove over limit txs, from non-executables to low priced
 	if len(executableObjs) > limit {
 		for _, txObj := range nonExecutableObjs {
-			toRemove = append(toRemove, txObj.ID())
+			toRemove = append(toRemove, txObj)
 			log.Debug("non-executable tx washed out due to pool limit", "id", txObj.ID())
 		}
 		for _, txObj := range executableObjs[limit:] {
-			toRemove = append(toRemove, txObj.ID())
+			toRemove = append(toRemove, txObj)
 			log.Debug("executable tx washed out due to pool limit", "id", txObj.ID())
 		}
 		executableObjs = executableObjs[:limit]
 	} else if len(executableObjs)+len(nonExecutableObjs) > limit {
 		// executableObjs + nonExecutableObjs over pool limit
 		for _, txObj := range nonExecutableObjs[limit-len(executableObjs):] {
-			toRemove = append(toRemove, txObj.ID())
+			toRemove = append(toRemove, txObj)
 			log.Debug("non-executable tx washed out due to pool limit", "id", txObj.ID())
 		}
 	}
@@ -363,12 +567,12 @@ func (p *TxPool) wash(headBlock *block.Header) (executables tx.Transactions, rem
 		}
 	}
 
-	p.goes.Go(func() {
-		for _, tx := range toBroadcast {
-			executable := true
-			p.txFeed.Send(&TxEvent{tx, &executable})
-		}
-	})
+	events := make([]*TxEvent, 0, len(toBroadcast))
+	for _, tx := range toBroadcast {
+		executable := true
+		events = append(events, &TxEvent{Tx: tx, Executable: &executable})
+	}
+	p.postEvents(events...)
 	return executables, 0, nil
 }
 
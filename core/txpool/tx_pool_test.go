@@ -24,11 +24,15 @@ func init() {
 func newPool() *TxPool {
 	kv, _ := storage.NewMem()
 	chain := newChain(kv)
-	return New(chain, state.NewCreator(kv), Options{
+	pool, err := New(chain, state.NewCreator(kv), Options{
 		Limit:           10,
 		LimitPerAccount: 2,
 		MaxLifetime:     time.Hour,
 	})
+	if err != nil {
+		panic(err)
+	}
+	return pool
 }
 func TestNewClose(t *testing.T) {
 	pool := newPool()
@@ -56,7 +60,60 @@ func TestSubscribeNewTx(t *testing.T) {
 	assert.Nil(t, pool.Add(tx))
 
 	v := true
-	assert.Equal(t, &TxEvent{tx, &v}, <-txCh)
+	assert.Equal(t, &TxEvent{Tx: tx, Executable: &v}, <-txCh)
+}
+
+func TestRemovedEvent(t *testing.T) {
+	pool := newPool()
+	defer pool.Close()
+
+	txCh := make(chan *TxEvent, 10)
+	pool.SubscribeTxEvent(txCh)
+
+	tx1 := newTx(pool.chain.Tag(), nil, 21000, tx.BlockRef{}, 100, nil, genesis.DevAccounts()[0])
+	tx2 := newTx(pool.chain.Tag(), nil, 21000, tx.BlockRef{}, 100, nil, genesis.DevAccounts()[1])
+	assert.Nil(t, pool.Add(tx1))
+	assert.Nil(t, pool.Add(tx2))
+	<-txCh
+	<-txCh
+
+	assert.True(t, pool.Remove(tx1.ID()))
+	assert.Equal(t, &TxEvent{Tx: tx1, Removed: true}, <-txCh)
+	assert.False(t, pool.Remove(tx1.ID()))
+
+	assert.Equal(t, 1, pool.Flush())
+	assert.Equal(t, &TxEvent{Tx: tx2, Removed: true}, <-txCh)
+}
+
+func TestEventsInOrder(t *testing.T) {
+	pool := newPool()
+	defer pool.Close()
+
+	b1 := new(block.Builder).
+		ParentID(pool.chain.GenesisBlock().Header().ID()).
+		Timestamp(uint64(time.Now().Unix())).
+		TotalScore(100).
+		GasLimit(10000000).
+		StateRoot(pool.chain.GenesisBlock().Header().StateRoot()).
+		Build()
+	pool.chain.AddBlock(b1, nil)
+
+	txCh := make(chan *TxEvent)
+	pool.SubscribeTxEvent(txCh)
+
+	var txs tx.Transactions
+	for i := 0; i < 20; i++ {
+		trx := newTx(pool.chain.Tag(), nil, 21000, tx.BlockRef{}, 100, nil, genesis.DevAccounts()[0])
+		assert.Nil(t, pool.Add(trx))
+		assert.True(t, pool.Remove(trx.ID()))
+		txs = append(txs, trx)
+	}
+
+	executable := true
+	for _, trx := range txs {
+		assert.Equal(t, &TxEvent{Tx: trx, Executable: &executable}, <-txCh)
+		assert.Equal(t, &TxEvent{Tx: trx, Removed: true}, <-txCh)
+	}
 }
 
 func TestWashTxs(t *testing.T) {
@@ -102,12 +159,14 @@ func TestAdd(t *testing.T) {
 	acc := genesis.DevAccounts()[0]
 
 	dupTx := newTx(pool.chain.Tag(), nil, 21000, tx.BlockRef{}, 100, nil, acc)
+	typedTx := signTx(new(tx.Builder).Type(tx.TypeNative).ChainTag(pool.chain.Tag()).Gas(21000).Expiration(100).Build(), acc)
 
 	tests := []struct {
 		tx     *tx.Transaction
 		errStr string
 	}{
 		{newTx(pool.chain.Tag()+1, nil, 21000, tx.BlockRef{}, 100, nil, acc), "bad tx: chain tag mismatch"},
+		{typedTx, "bad tx: tx type unsupported"},
 		{dupTx, ""},
 		{dupTx, ""},
 	}
@@ -139,3 +198,23 @@ func TestAdd(t *testing.T) {
 		}
 	}
 }
+
+func TestWashAndFlush(t *testing.T) {
+	pool := newPool()
+	defer pool.Close()
+
+	tx := newTx(pool.chain.Tag(), nil, 21000, tx.BlockRef{}, 100, nil, genesis.DevAccounts()[0])
+	assert.Nil(t, pool.Add(tx))
+
+	// wash triggered even if chain not synced
+	pool.Wash()
+	for i := 0; i < 100 && len(pool.Executables()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, Tx.Transactions{tx}, pool.Executables())
+
+	assert.Equal(t, 1, pool.Flush())
+	assert.Zero(t, len(pool.Dump()))
+	assert.Zero(t, len(pool.Executables()))
+	assert.Zero(t, pool.Flush())
+}
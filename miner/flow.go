@@ -69,8 +69,10 @@ func (f *Flow) Adopt(tx *tx.Transaction) error {
 	switch {
 	case tx.ChainTag() != f.miner.chain.Tag():
 		return badTxError{"chain tag mismatch"}
-	case tx.HasReservedFields():
+	case tx.HasReservedFields(f.runtime.ForkConfig(), f.runtime.Context().Number):
 		return badTxError{"reserved fields not empty"}
+	case tx.IsTyped() && f.runtime.Context().Number < f.runtime.ForkConfig().TypedTx:
+		return badTxError{"tx type unsupported"}
 	case f.runtime.Context().Number < tx.BlockRef().Number():
 		return errTxNotAdoptableNow
 	case tx.IsExpired(f.runtime.Context().Number):
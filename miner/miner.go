@@ -22,6 +22,7 @@ type Miner struct {
 	nodeMaster     polo.Address
 	beneficiary    *polo.Address
 	targetGasLimit uint64
+	forkConfig     polo.ForkConfig
 }
 
 // New create a new Miner instance.
@@ -38,6 +39,7 @@ func New(
 		nodeMaster,
 		beneficiary,
 		0,
+		polo.GetForkConfig(chain.GenesisBlock().Header().ID()),
 	}
 }
 
@@ -83,6 +85,7 @@ func (p *Miner) Schedule(parent *block.Header, nowTimestamp uint64) (flow *Flow,
 	for _, u := range updates {
 		authority.Update(u.Address, u.Active)
 	}
+	builtin.ActivatePlugins(state, p.forkConfig, parent.Number()+1)
 
 	rt := runtime.New(
 		p.chain.NewSeeker(parent.ID()),
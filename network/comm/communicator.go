@@ -13,6 +13,8 @@ import (
 
 	"github.com/HiNounou029/nounouchain/polo"
 	"github.com/HiNounou029/nounouchain/common/co"
+	"github.com/HiNounou029/nounouchain/common/metric"
+	"github.com/HiNounou029/nounouchain/common/ratelimit"
 	"github.com/HiNounou029/nounouchain/core/block"
 	"github.com/HiNounou029/nounouchain/core/chain"
 	"github.com/HiNounou029/nounouchain/core/tx"
@@ -28,7 +30,12 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/discover"
 )
 
-var log = log15.New("pkg", "comm")
+var (
+	log = log15.New("pkg", "comm")
+
+	rateLimitedTxsCounter = metric.NewCounter("p2p_rate_limited_txs", "txs dropped due to peer rate limit")
+	receivedMsgsCounter   = metric.NewCounterVec("p2p_received_msgs", "p2p messages received by msg name", "msg")
+)
 
 // Communicator communicates with remote p2p peers to exchange blocks and txs, etc.
 type Communicator struct {
@@ -47,6 +54,11 @@ type Communicator struct {
 	isWithCert	   bool
 	certInfo	   []byte
 	nodeInfo *p2p.NodeInfo
+	txLimiter      *ratelimit.Limiter
+	banned         struct {
+		sync.Mutex
+		m map[discover.NodeID]time.Time // to expiry, zero for forever
+	}
 }
 
 // New create a new Communicator instance.
@@ -66,6 +78,13 @@ func New(chain *chain.Chain, txPool *txpool.TxPool, rootCaPath string, isStartWi
 	}
 }
 
+// SetTxRateLimit limits count of txs per second accepted from each peer.
+// Peers exceeding the limit are deprioritized, and disconnected if keep violating.
+// It should be called before Start.
+func (c *Communicator) SetTxRateLimit(rate float64, burst int) {
+	c.txLimiter = ratelimit.New(rate, burst)
+}
+
 // Synced returns a channel indicates if synchronization process passed.
 func (c *Communicator) Synced() <-chan struct{} {
 	return c.syncedCh
@@ -106,7 +125,7 @@ func (c *Communicator) Sync(handler HandleBlockStream) {
 
 				best := c.chain.BestBlock().Header()
 				// choose peer which has the head block with higher total score
-				peer := c.peerSet.Slice().Find(func(peer *Peer) bool {
+				peer := c.peerSet.Slice().Prioritized().Find(func(peer *Peer) bool {
 					_, totalScore := peer.Head()
 					return totalScore >= best.TotalScore()
 				})
@@ -195,6 +214,11 @@ func (c *Communicator) runPeer(peer *Peer) {
 	ctx, cancel := context.WithTimeout(c.ctx, time.Second*5)
 	defer cancel()
 
+	if c.isBanned(peer.ID()) {
+		peer.logger.Debug("rejected banned peer")
+		return
+	}
+
 	status, err := proto.GetStatus(ctx, peer)
 	if err != nil {
 		peer.logger.Debug("failed to get status", "err", err)
@@ -237,6 +261,7 @@ func (c *Communicator) runPeer(peer *Peer) {
 
 	defer func() {
 		c.peerSet.Remove(peer.ID())
+		c.txLimiter.Forget(peer.ID())
 		peer.logger.Debug(fmt.Sprintf("peer removed (%v)", c.peerSet.Len()))
 	}()
 
@@ -294,6 +319,70 @@ func (c *Communicator) PeerCount() int {
 	return c.peerSet.Len()
 }
 
+// DisconnectPeer disconnects the peer, returns false if not connected.
+func (c *Communicator) DisconnectPeer(id discover.NodeID) bool {
+	if peer := c.peerSet.Find(id); peer != nil {
+		peer.Disconnect(p2p.DiscRequested)
+		return true
+	}
+	return false
+}
+
+// BanPeer disconnects the peer, and rejects its connections for the duration, or forever if duration is 0.
+func (c *Communicator) BanPeer(id discover.NodeID, duration time.Duration) {
+	var expiry time.Time
+	if duration > 0 {
+		expiry = time.Now().Add(duration)
+	}
+	c.banned.Lock()
+	if c.banned.m == nil {
+		c.banned.m = make(map[discover.NodeID]time.Time)
+	}
+	c.banned.m[id] = expiry
+	c.banned.Unlock()
+
+	c.DisconnectPeer(id)
+}
+
+// UnbanPeer lifts the ban of the peer, returns false if not banned.
+func (c *Communicator) UnbanPeer(id discover.NodeID) bool {
+	c.banned.Lock()
+	defer c.banned.Unlock()
+
+	_, ok := c.banned.m[id]
+	delete(c.banned.m, id)
+	return ok
+}
+
+// BannedPeers returns banned peers, with the time bans expire, zero for forever.
+func (c *Communicator) BannedPeers() map[discover.NodeID]time.Time {
+	c.banned.Lock()
+	defer c.banned.Unlock()
+
+	now := time.Now()
+	banned := make(map[discover.NodeID]time.Time)
+	for id, expiry := range c.banned.m {
+		if !expiry.IsZero() && now.After(expiry) {
+			delete(c.banned.m, id)
+			continue
+		}
+		banned[id] = expiry
+	}
+	return banned
+}
+
+func (c *Communicator) isBanned(id discover.NodeID) bool {
+	c.banned.Lock()
+	defer c.banned.Unlock()
+
+	expiry, ok := c.banned.m[id]
+	if ok && !expiry.IsZero() && time.Now().After(expiry) {
+		delete(c.banned.m, id)
+		return false
+	}
+	return ok
+}
+
 // PeersStats returns all peers' stats
 func (c *Communicator) PeersStats() []*PeerStats {
 	var stats []*PeerStats
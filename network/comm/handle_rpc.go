@@ -17,11 +17,20 @@ import (
 	"github.com/pkg/errors"
 )
 
+// msgLabel returns metric label of the msg code, unknown codes are aggregated.
+func msgLabel(code uint64) string {
+	if code > proto.MsgGetTxs {
+		return "unknown"
+	}
+	return proto.MsgName(code)
+}
+
 // peer will be disconnected if error returned
 func (c *Communicator) handleRPC(peer *Peer, msg *p2p.Msg, write func(interface{}), txsToSync *txsToSync) (err error) {
 
 	log := peer.logger.New("msg", proto.MsgName(msg.Code))
 	log.Debug("received RPC call")
+	receivedMsgsCounter.With(msgLabel(msg.Code)).Inc()
 	defer func() {
 		if err != nil {
 			log.Debug("failed to handle RPC call", "err", err)
@@ -76,7 +85,14 @@ func (c *Communicator) handleRPC(peer *Peer, msg *p2p.Msg, write func(interface{
 			return errors.WithMessage(err, "decode msg")
 		}
 		peer.MarkTransaction(newTx.ID())
-		c.txPool.StrictlyAdd(newTx)
+		if c.txLimiter.Allow(peer.ID()) {
+			c.txPool.StrictlyAdd(newTx)
+		} else {
+			rateLimitedTxsCounter.Inc()
+			if peer.AddRateViolation() >= maxRateViolations {
+				return errors.New("tx rate limit exceeded")
+			}
+		}
 		write(&struct{}{})
 	case proto.MsgGetBlockByID:
 		var blockID polo.Bytes32
@@ -19,6 +19,9 @@ import (
 const (
 	maxKnownTxs    = 32768 // Maximum transactions IDs to keep in the known list (prevent DOS)
 	maxKnownBlocks = 1024  // Maximum block IDs to keep in the known list (prevent DOS)
+
+	rateViolationWindow = mclock.AbsTime(time.Minute) // Window in which rate limit violations are counted
+	maxRateViolations   = 100                         // Peer will be disconnected if violations in window reach it
 )
 
 func init() {
@@ -39,6 +42,11 @@ type Peer struct {
 		id         polo.Bytes32
 		totalScore uint64
 	}
+	violations struct {
+		sync.Mutex
+		count int
+		since mclock.AbsTime
+	}
 }
 
 func newPeer(peer *p2p.Peer, rw p2p.MsgReadWriter) *Peer {
@@ -98,6 +106,29 @@ func (p *Peer) IsBlockKnown(id polo.Bytes32) bool {
 	return p.knownBlocks.Contains(id)
 }
 
+// AddRateViolation records that the peer exceeded rate limit.
+// It returns count of violations in current window.
+func (p *Peer) AddRateViolation() int {
+	p.violations.Lock()
+	defer p.violations.Unlock()
+
+	now := mclock.Now()
+	if now-p.violations.since > rateViolationWindow {
+		p.violations.count = 0
+		p.violations.since = now
+	}
+	p.violations.count++
+	return p.violations.count
+}
+
+// IsDeprioritized returns if the peer violated rate limit in current window.
+func (p *Peer) IsDeprioritized() bool {
+	p.violations.Lock()
+	defer p.violations.Unlock()
+
+	return p.violations.count > 0 && mclock.Now()-p.violations.since <= rateViolationWindow
+}
+
 // Duration returns duration of connection.
 func (p *Peer) Duration() mclock.AbsTime {
 	return mclock.Now() - p.createdTime
@@ -117,6 +148,20 @@ func (ps Peers) Filter(cond func(*Peer) bool) Peers {
 	return ret
 }
 
+// Prioritized returns peers with deprioritized ones moved to the end.
+func (ps Peers) Prioritized() Peers {
+	ret := make(Peers, 0, len(ps))
+	var low Peers
+	for _, peer := range ps {
+		if peer.IsDeprioritized() {
+			low = append(low, peer)
+		} else {
+			ret = append(ret, peer)
+		}
+	}
+	return append(ret, low...)
+}
+
 // Find find one peer that satisfies the given condition.
 func (ps Peers) Find(cond func(*Peer) bool) *Peer {
 	for _, peer := range ps {
@@ -32,6 +32,8 @@ func MsgName(msgCode uint64) string {
 	switch msgCode {
 	case MsgGetStatus:
 		return "MsgGetStatus"
+	case MsgCertValRes:
+		return "MsgCertValRes"
 	case MsgNewBlockID:
 		return "MsgNewBlockID"
 	case MsgNewBlock:
@@ -20,8 +20,9 @@ func (c *Communicator) txsLoop() {
 		case txEv := <-txEvCh:
 			if txEv.Executable != nil && *txEv.Executable {
 				tx := txEv.Tx
+				// skip deprioritized peers, which are spamming us
 				peers := c.peerSet.Slice().Filter(func(p *Peer) bool {
-					return !p.IsTransactionKnown(tx.ID())
+					return !p.IsTransactionKnown(tx.ID()) && !p.IsDeprioritized()
 				})
 
 				for _, peer := range peers {
@@ -59,6 +59,8 @@ func (p *prototypeContract) Events() *abi.ABI {
 type nativeMethod struct {
 	abi *abi.Method
 	run func(env *xenv.Environment) []interface{}
+	// plugin declaring the method, nil for core builtins
+	plugin *Plugin
 }
 
 type methodKey struct {
@@ -68,15 +70,15 @@ type methodKey struct {
 
 var nativeMethods = make(map[methodKey]*nativeMethod)
 
-// FindNativeCall find native calls.
-func FindNativeCall(to polo.Address, input []byte) (*abi.Method, func(*xenv.Environment) []interface{}, bool) {
+// FindNativeCall find native calls available at the given block number under the fork config.
+func FindNativeCall(to polo.Address, input []byte, forkConfig polo.ForkConfig, blockNum uint32) (*abi.Method, func(*xenv.Environment) []interface{}, bool) {
 	methodID, err := abi.ExtractMethodID(input)
 	if err != nil {
 		return nil, nil, false
 	}
 
 	method := nativeMethods[methodKey{to, methodID}]
-	if method == nil {
+	if method == nil || (method.plugin != nil && blockNum < method.plugin.activation(forkConfig)) {
 		return nil, nil, false
 	}
 	return method.abi, method.run, true
@@ -0,0 +1,33 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package compliance
+
+import (
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+)
+
+// Compliance binder of `Compliance` contract.
+type Compliance struct {
+	addr  polo.Address
+	state *state.State
+}
+
+// New create a new instance.
+func New(addr polo.Address, state *state.State) *Compliance {
+	return &Compliance{addr, state}
+}
+
+// IsFrozen returns whether the account is frozen.
+func (c *Compliance) IsFrozen(account polo.Address) bool {
+	return !c.state.GetStorage(c.addr, polo.BytesToBytes32(account[:])).IsZero()
+}
+
+// SetFrozen freezes or unfreezes the account.
+func (c *Compliance) SetFrozen(account polo.Address, frozen bool) {
+	var value polo.Bytes32
+	if frozen {
+		value[len(value)-1] = 1
+	}
+	c.state.SetStorage(c.addr, polo.BytesToBytes32(account[:]), value)
+}
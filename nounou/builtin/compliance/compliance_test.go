@@ -0,0 +1,27 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package compliance
+
+import (
+	"testing"
+
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/HiNounou029/nounouchain/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompliance(t *testing.T) {
+	kv, _ := storage.NewMem()
+	st, _ := state.New(polo.Bytes32{}, kv)
+	c := New(polo.BytesToAddress([]byte("cpl")), st)
+	acc := polo.BytesToAddress([]byte("acc"))
+
+	assert.False(t, c.IsFrozen(acc))
+	c.SetFrozen(acc, true)
+	assert.True(t, c.IsFrozen(acc))
+	c.SetFrozen(acc, false)
+	assert.False(t, c.IsFrozen(acc))
+
+	assert.Nil(t, st.Err())
+}
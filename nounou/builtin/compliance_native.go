@@ -0,0 +1,72 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package builtin
+
+import (
+	"github.com/HiNounou029/nounouchain/common/xenv"
+	"github.com/HiNounou029/nounouchain/nounou/abi"
+	"github.com/HiNounou029/nounouchain/nounou/builtin/compliance"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const complianceABI = `[
+{"constant":false,"inputs":[{"name":"_account","type":"address"}],"name":"freeze","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},
+{"constant":false,"inputs":[{"name":"_account","type":"address"}],"name":"unfreeze","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},
+{"constant":true,"inputs":[{"name":"_account","type":"address"}],"name":"isFrozen","outputs":[{"name":"","type":"bool"}],"payable":false,"stateMutability":"view","type":"function"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"account","type":"address"}],"name":"Freeze","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"account","type":"address"}],"name":"Unfreeze","type":"event"}
+]`
+
+// Compliance is the plugin contract to freeze accounts, governed by executor.
+// Frozen accounts can neither pay for gas, nor call, create or send value, and can't
+// receive value. It's activated by the Compliance fork.
+var Compliance = &compliancePlugin{&Plugin{
+	Name: "Compliance",
+	ABI:  []byte(complianceABI),
+	Fork: func(fc polo.ForkConfig) uint32 { return fc.Compliance },
+}}
+
+type compliancePlugin struct{ *Plugin }
+
+func (c *compliancePlugin) Native(state *state.State) *compliance.Compliance {
+	return compliance.New(c.Address, state)
+}
+
+func init() {
+	mustEvent := func(name string) *abi.Event {
+		ev, found := Compliance.NativeABI().EventByName(name)
+		if !found {
+			panic("event not found: " + name)
+		}
+		return ev
+	}
+	setFrozen := func(frozen bool, event string) func(env *xenv.Environment) []interface{} {
+		return func(env *xenv.Environment) []interface{} {
+			var account common.Address
+			env.ParseArgs(&account)
+
+			env.UseGas(polo.SloadGas)
+			executor := polo.BytesToAddress(Params.Native(env.State()).Get(polo.KeyExecutorAddress).Bytes())
+			if env.OuterCaller() != executor {
+				env.Revert("builtin: executor required")
+			}
+
+			Compliance.Native(env.State()).SetFrozen(polo.Address(account), frozen)
+			env.Log(mustEvent(event), Compliance.Address, []polo.Bytes32{polo.BytesToBytes32(account[:])})
+			return nil
+		}
+	}
+
+	Compliance.Methods = map[string]*PluginMethod{
+		"freeze":   {Gas: polo.SstoreSetGas, Run: setFrozen(true, "Freeze")},
+		"unfreeze": {Gas: polo.SstoreResetGas, Run: setFrozen(false, "Unfreeze")},
+		"isFrozen": {Gas: polo.SloadGas, Run: func(env *xenv.Environment) []interface{} {
+			var account common.Address
+			env.ParseArgs(&account)
+			return []interface{}{Compliance.Native(env.State()).IsFrozen(polo.Address(account))}
+		}},
+	}
+	RegisterPlugin(Compliance.Plugin)
+}
@@ -0,0 +1,133 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package builtin_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/HiNounou029/nounouchain/common/xenv"
+	"github.com/HiNounou029/nounouchain/core/chain"
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/crypto"
+	"github.com/HiNounou029/nounouchain/nounou/builtin"
+	"github.com/HiNounou029/nounouchain/nounou/genesis"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/HiNounou029/nounouchain/storage"
+	"github.com/HiNounou029/nounouchain/vm"
+	"github.com/HiNounou029/nounouchain/vm/runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func complianceEvent(account polo.Address, name string) *tx.Event {
+	ev, _ := builtin.Compliance.NativeABI().EventByName(name)
+	data, _ := ev.Encode()
+	return &tx.Event{
+		Address: builtin.Compliance.Address,
+		Topics:  []polo.Bytes32{ev.ID(), polo.BytesToBytes32(account.Bytes())},
+		Data:    data,
+	}
+}
+
+func TestComplianceNative(t *testing.T) {
+	fork := polo.Conf.ComplianceFork
+	polo.Conf.ComplianceFork = 0
+	defer func() { polo.Conf.ComplianceFork = fork }()
+
+	var (
+		executor = polo.BytesToAddress([]byte("e"))
+		frozen   = genesis.DevAccounts()[0]
+		other    = polo.BytesToAddress([]byte("other"))
+	)
+	kv, _ := storage.NewMem()
+	b0 := buildGenesis(kv, func(state *state.State) error {
+		state.SetCode(builtin.Params.Address, builtin.Params.RuntimeBytecodes())
+		builtin.Params.Native(state).Set(polo.KeyExecutorAddress, new(big.Int).SetBytes(executor[:]))
+		builtin.ActivatePlugins(state, polo.ConfiguredForkConfig(), 0)
+		state.SetBalance(frozen.Address, big.NewInt(1e18))
+		state.SetBalance(other, big.NewInt(1e18))
+		return nil
+	})
+	c, _ := chain.New(kv, b0)
+	st, _ := state.New(b0.Header().StateRoot(), kv)
+	seeker := c.NewSeeker(b0.Header().ID())
+	defer func() {
+		assert.Nil(t, st.Err())
+		assert.Nil(t, seeker.Err())
+	}()
+
+	rt := runtime.New(seeker, st, &xenv.BlockContext{})
+	test := &ctest{
+		rt:  rt,
+		abi: builtin.Compliance.NativeABI(),
+		to:  builtin.Compliance.Address,
+	}
+
+	test.Case("freeze", frozen.Address).
+		Caller(other).
+		ShouldVMError(errReverted).
+		Assert(t)
+
+	// freeze via staticcall fails, rather than crashing the node
+	staticCaller := polo.BytesToAddress([]byte("static"))
+	st.SetCode(staticCaller, staticCallerBytecode(builtin.Compliance.Address))
+	freeze, _ := builtin.Compliance.NativeABI().MethodByName("freeze")
+	data, _ := freeze.EncodeInput(frozen.Address)
+	out := rt.ExecuteClause(tx.NewClause(&staticCaller).WithData(data), 0, 1000000,
+		&xenv.TransactionContext{Origin: executor, GasPrice: &big.Int{}})
+	assert.Nil(t, out.VMErr)
+	assert.Equal(t, polo.Bytes32{}, polo.BytesToBytes32(out.Data))
+	assert.False(t, builtin.Compliance.Native(st).IsFrozen(frozen.Address))
+
+	test.Case("freeze", frozen.Address).
+		Caller(executor).
+		ShouldLog(complianceEvent(frozen.Address, "Freeze")).
+		Assert(t)
+
+	test.Case("isFrozen", frozen.Address).
+		ShouldOutput(true).
+		Assert(t)
+
+	transfer := func(from, to polo.Address, value int64) error {
+		return rt.ExecuteClause(tx.NewClause(&to).WithValue(big.NewInt(value)), 0, math.MaxUint64,
+			&xenv.TransactionContext{Origin: from, GasPrice: &big.Int{}}).VMErr
+	}
+	assert.Equal(t, vm.ErrAccountFrozen, transfer(frozen.Address, other, 1))
+	assert.Equal(t, vm.ErrAccountFrozen, transfer(other, frozen.Address, 1))
+	assert.Nil(t, transfer(other, frozen.Address, 0))
+
+	// self-destruct moves no balance from or to frozen accounts
+	suicide := func(contract, receiver polo.Address) error {
+		// selfdestruct(receiver)
+		st.SetCode(contract, append(append([]byte{0x73}, receiver.Bytes()...), 0xff))
+		st.SetBalance(contract, big.NewInt(1))
+		err := rt.ExecuteClause(tx.NewClause(&contract).WithData(make([]byte, 4)), 0, 1000000,
+			&xenv.TransactionContext{Origin: other, GasPrice: &big.Int{}}).VMErr
+		assert.Equal(t, big.NewInt(1), st.GetBalance(contract))
+		return err
+	}
+	assert.Equal(t, vm.ErrAccountFrozen, suicide(polo.BytesToAddress([]byte("suicide")), frozen.Address))
+	assert.Equal(t, vm.ErrAccountFrozen, suicide(frozen.Address, other))
+
+	trx := new(tx.Builder).Gas(21000).Clause(tx.NewClause(&other)).Build()
+	sig, _ := crypto.Sign(trx.SigningHash().Bytes(), frozen.PrivateKey)
+	resolved, err := runtime.ResolveTransaction(trx.WithSignature(sig))
+	assert.Nil(t, err)
+	_, _, _, err = resolved.BuyGas(st, polo.ForkConfig{}, 0, 0)
+	assert.NotNil(t, err)
+
+	test.Case("unfreeze", frozen.Address).
+		Caller(executor).
+		ShouldLog(complianceEvent(frozen.Address, "Unfreeze")).
+		Assert(t)
+
+	test.Case("isFrozen", frozen.Address).
+		ShouldOutput(false).
+		Assert(t)
+
+	assert.Nil(t, transfer(frozen.Address, other, 1))
+	_, _, _, err = resolved.BuyGas(st, polo.ForkConfig{}, 0, 0)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,150 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package builtin
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/HiNounou029/nounouchain/common/xenv"
+	"github.com/HiNounou029/nounouchain/nounou/abi"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+)
+
+// forwarderRuntimeBytecode is deployed for plugins without byte codes.
+// It forwards calldata to itself, where the call is intercepted as native call,
+// and returns (or reverts with) the output. Self calls not intercepted are reverted.
+var forwarderRuntimeBytecode = []byte{
+	0x33, 0x30, 0x14, 0x60, 0x2a, 0x57, // if caller == address goto revert
+	0x36, 0x60, 0x00, 0x60, 0x00, 0x37, // calldatacopy(0, 0, calldatasize)
+	0x60, 0x00, 0x60, 0x00, 0x36, 0x60, 0x00, 0x60, 0x00, 0x30, 0x5a, 0xf1, // call(gas, address, 0, 0, calldatasize, 0, 0)
+	0x3d, 0x60, 0x00, 0x60, 0x00, 0x3e, // returndatacopy(0, 0, returndatasize)
+	0x60, 0x25, 0x57, // if success goto ok
+	0x3d, 0x60, 0x00, 0xfd, // revert(0, returndatasize)
+	0x5b, 0x3d, 0x60, 0x00, 0xf3, // ok: return(0, returndatasize)
+	0x5b, 0x60, 0x00, 0x80, 0xfd, // revert: revert(0, 0)
+}
+
+// PluginMethod is a native method of plugin contract.
+type PluginMethod struct {
+	// Gas is charged before Run, in addition to gas used by Run itself.
+	Gas uint64
+	Run func(env *xenv.Environment) []interface{}
+}
+
+// Plugin declares a builtin contract implemented out of the core.
+// Native methods are invoked as calls from the contract to itself, as core builtins do.
+// Without byte codes, a forwarder is deployed which exposes native methods directly,
+// where env.Caller() is the contract itself, and tx origin is the only caller info.
+type Plugin struct {
+	Name string
+	// Address defaults to address derived from name, like core builtins.
+	Address polo.Address
+	// ABI is the JSON ABI of native methods.
+	ABI []byte
+	// RuntimeBytecodes is deployed at activation, defaults to the forwarder.
+	RuntimeBytecodes []byte
+	// Methods maps method names in ABI to native handlers.
+	Methods map[string]*PluginMethod
+	// Activation is the block number from which the contract is available.
+	// Plugins activated at block 0 are deployed in genesis, and change the genesis ID,
+	// so a plugin added to a running chain must be activated at a future block instead.
+	Activation uint32
+	// Fork overrides Activation if set, for plugins scheduled as forks of the network.
+	Fork func(fc polo.ForkConfig) uint32
+
+	abi *abi.ABI
+}
+
+// activation returns the block number from which the contract is available under the fork config.
+func (p *Plugin) activation(forkConfig polo.ForkConfig) uint32 {
+	if p.Fork != nil {
+		return p.Fork(forkConfig)
+	}
+	return p.Activation
+}
+
+// NativeABI returns the parsed ABI of native methods.
+func (p *Plugin) NativeABI() *abi.ABI {
+	return p.abi
+}
+
+var plugins = make(map[polo.Address]*Plugin)
+
+// RegisterPlugin registers a plugin contract, to be found by FindNativeCall, and deployed
+// by ActivatePlugins. It's expected to be called in init function of the plugin package,
+// and panics if the plugin is malformed or conflicts with others.
+func RegisterPlugin(p *Plugin) {
+	if p.Name == "" {
+		panic("plugin name required")
+	}
+	if p.Address.IsZero() {
+		p.Address = polo.BytesToAddress([]byte(p.Name))
+	}
+	for _, c := range []*contract{
+		Params.contract, Authority.contract, Executor.contract, Prototype.contract,
+		Extension.contract, Token.contract, Measure,
+	} {
+		if c.Address == p.Address {
+			panic(fmt.Sprintf("plugin %v: address occupied by builtin %v", p.Name, c.name))
+		}
+	}
+	if other, ok := plugins[p.Address]; ok {
+		panic(fmt.Sprintf("plugin %v: address occupied by plugin %v", p.Name, other.Name))
+	}
+	if len(p.RuntimeBytecodes) == 0 {
+		p.RuntimeBytecodes = forwarderRuntimeBytecode
+	}
+
+	var err error
+	if p.abi, err = abi.New(p.ABI); err != nil {
+		panic(fmt.Sprintf("plugin %v: load ABI: %v", p.Name, err))
+	}
+	methods := make(map[methodKey]*nativeMethod, len(p.Methods))
+	for name, m := range p.Methods {
+		method, found := p.abi.MethodByName(name)
+		if !found {
+			panic(fmt.Sprintf("plugin %v: method not found: %v", p.Name, name))
+		}
+		m := m
+		methods[methodKey{p.Address, method.ID()}] = &nativeMethod{
+			abi: method,
+			run: func(env *xenv.Environment) []interface{} {
+				env.UseGas(m.Gas)
+				return m.Run(env)
+			},
+			plugin: p,
+		}
+	}
+	for key, method := range methods {
+		nativeMethods[key] = method
+	}
+	plugins[p.Address] = p
+}
+
+// Plugins returns registered plugins, sorted by activation and then name.
+func Plugins() []*Plugin {
+	list := make([]*Plugin, 0, len(plugins))
+	for _, p := range plugins {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Activation != list[j].Activation {
+			return list[i].Activation < list[j].Activation
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// ActivatePlugins deploys plugins activated at the given block number under the fork config.
+// It should be applied on state before executing txs of the block, and in genesis
+// for plugins activated at block 0.
+func ActivatePlugins(state *state.State, forkConfig polo.ForkConfig, blockNum uint32) {
+	for _, p := range Plugins() {
+		if p.activation(forkConfig) == blockNum {
+			state.SetCode(p.Address, p.RuntimeBytecodes)
+		}
+	}
+}
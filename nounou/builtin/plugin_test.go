@@ -0,0 +1,143 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package builtin_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/HiNounou029/nounouchain/common/xenv"
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/nounou/builtin"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/HiNounou029/nounouchain/storage"
+	"github.com/HiNounou029/nounouchain/vm/runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPluginABI = `[{"constant":true,"inputs":[{"name":"a","type":"uint256"},{"name":"b","type":"uint256"}],"name":"add","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"}]`
+
+const testWriterABI = `[{"constant":false,"inputs":[],"name":"write","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"}]`
+
+var (
+	adderPlugin = &builtin.Plugin{
+		Name: "TestAdder",
+		ABI:  []byte(testPluginABI),
+		Methods: map[string]*builtin.PluginMethod{
+			"add": {Gas: 100, Run: runAdd},
+		},
+	}
+	latePlugin = &builtin.Plugin{
+		Name: "TestLateAdder",
+		ABI:  []byte(testPluginABI),
+		Methods: map[string]*builtin.PluginMethod{
+			"add": {Run: runAdd},
+		},
+		Activation: 10,
+	}
+	writerPlugin = &builtin.Plugin{
+		Name: "TestWriter",
+		ABI:  []byte(testWriterABI),
+		Methods: map[string]*builtin.PluginMethod{
+			"write": {Run: func(env *xenv.Environment) []interface{} {
+				env.State().SetStorage(env.To(), polo.Bytes32{}, polo.BytesToBytes32([]byte{1}))
+				return nil
+			}},
+		},
+	}
+)
+
+func init() {
+	builtin.RegisterPlugin(adderPlugin)
+	builtin.RegisterPlugin(latePlugin)
+	builtin.RegisterPlugin(writerPlugin)
+}
+
+func runAdd(env *xenv.Environment) []interface{} {
+	var args struct {
+		A *big.Int
+		B *big.Int
+	}
+	env.ParseArgs(&args)
+	return []interface{}{new(big.Int).Add(args.A, args.B)}
+}
+
+func callAdd(t *testing.T, st *state.State, p *builtin.Plugin, blockNum uint32) *runtime.Output {
+	method, _ := p.NativeABI().MethodByName("add")
+	data, err := method.EncodeInput(big.NewInt(1), big.NewInt(2))
+	assert.Nil(t, err)
+
+	rt := runtime.New(nil, st, &xenv.BlockContext{Number: blockNum})
+	return rt.ExecuteClause(tx.NewClause(&p.Address).WithData(data), 0, math.MaxUint64, &xenv.TransactionContext{})
+}
+
+func TestPlugin(t *testing.T) {
+	kv, _ := storage.NewMem()
+	st, _ := state.New(polo.Bytes32{}, kv)
+
+	assert.Equal(t, polo.BytesToAddress([]byte("TestAdder")), adderPlugin.Address)
+	assert.Panics(t, func() { builtin.RegisterPlugin(&builtin.Plugin{Name: "Params", ABI: []byte(testPluginABI)}) })
+	assert.Panics(t, func() { builtin.RegisterPlugin(&builtin.Plugin{Name: "TestAdder", ABI: []byte(testPluginABI)}) })
+	assert.Panics(t, func() {
+		builtin.RegisterPlugin(&builtin.Plugin{
+			Name:    "TestUnknown",
+			ABI:     []byte(testPluginABI),
+			Methods: map[string]*builtin.PluginMethod{"sub": {Run: runAdd}},
+		})
+	})
+
+	builtin.ActivatePlugins(st, polo.NoFork, 0)
+	assert.NotEmpty(t, st.GetCode(adderPlugin.Address))
+	assert.Empty(t, st.GetCode(latePlugin.Address))
+
+	out := callAdd(t, st, adderPlugin, 1)
+	assert.Nil(t, out.VMErr)
+	var sum *big.Int
+	method, _ := adderPlugin.NativeABI().MethodByName("add")
+	assert.Nil(t, method.DecodeOutput(out.Data, &sum))
+	assert.Equal(t, big.NewInt(3), sum)
+
+	// native methods are unavailable before activation, even if the code exists
+	st.SetCode(latePlugin.Address, st.GetCode(adderPlugin.Address))
+	out = callAdd(t, st, latePlugin, 9)
+	assert.NotNil(t, out.VMErr)
+
+	builtin.ActivatePlugins(st, polo.NoFork, 10)
+	out = callAdd(t, st, latePlugin, 10)
+	assert.Nil(t, out.VMErr)
+	assert.Nil(t, method.DecodeOutput(out.Data, &sum))
+	assert.Equal(t, big.NewInt(3), sum)
+}
+
+func TestPluginStaticCall(t *testing.T) {
+	kv, _ := storage.NewMem()
+	st, _ := state.New(polo.Bytes32{}, kv)
+	builtin.ActivatePlugins(st, polo.NoFork, 0)
+
+	caller := polo.BytesToAddress([]byte("caller"))
+	st.SetCode(caller, staticCallerBytecode(writerPlugin.Address))
+
+	method, _ := writerPlugin.NativeABI().MethodByName("write")
+	data, err := method.EncodeInput()
+	assert.Nil(t, err)
+
+	rt := runtime.New(nil, st, &xenv.BlockContext{Number: 1})
+	out := rt.ExecuteClause(tx.NewClause(&caller).WithData(data), 0, 1000000, &xenv.TransactionContext{})
+	assert.Nil(t, out.VMErr)
+	assert.Equal(t, polo.Bytes32{}, polo.BytesToBytes32(out.Data), "non-const method should fail under staticcall")
+	assert.Equal(t, polo.Bytes32{}, st.GetStorage(writerPlugin.Address, polo.Bytes32{}))
+
+	out = rt.ExecuteClause(tx.NewClause(&writerPlugin.Address).WithData(data), 0, 1000000, &xenv.TransactionContext{})
+	assert.Nil(t, out.VMErr)
+	assert.Equal(t, polo.BytesToBytes32([]byte{1}), st.GetStorage(writerPlugin.Address, polo.Bytes32{}))
+}
+
+// staticCallerBytecode returns code which does staticcall(gas, to, 0, calldatasize, 0, 0)
+// with the calldata, and returns the success flag.
+func staticCallerBytecode(to polo.Address) []byte {
+	code := []byte{0x36, 0x60, 0x00, 0x60, 0x00, 0x37, 0x60, 0x00, 0x60, 0x00, 0x36, 0x60, 0x00, 0x73}
+	code = append(code, to.Bytes()...)
+	return append(code, 0x5a, 0xfa, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3)
+}
@@ -10,15 +10,37 @@ import (
 // ForkConfig config for a fork.
 type ForkConfig struct {
 	FixTransferLog uint32
+	// revert data stored in receipts, which changes receipts root
+	RevertReason uint32
+	// constantinople opcode set of the vm
+	Constantinople uint32
+	// multisig descriptor in tx reserved fields
+	MultiSig uint32
+	// gas paid by delegator, prototype sponsor or the common 'To'
+	Delegation uint32
+	// typed tx envelopes, which old nodes can't decode
+	TypedTx uint32
+	// SM3/SM2 precompiled contracts of the vm
+	SMPrecompile uint32
+	// compliance plugin, which freezes accounts
+	Compliance uint32
 }
 
 func (fc ForkConfig) String() string {
-	return fmt.Sprintf("FTRL: #%v", fc.FixTransferLog)
+	return fmt.Sprintf("FTRL: #%v, RVRS: #%v, CNST: #%v, MSIG: #%v, DLGT: #%v, TYTX: #%v, SMPC: #%v, CMPL: #%v",
+		fc.FixTransferLog, fc.RevertReason, fc.Constantinople, fc.MultiSig, fc.Delegation, fc.TypedTx, fc.SMPrecompile, fc.Compliance)
 }
 
 // NoFork a special config without any forks.
 var NoFork = ForkConfig{
 	FixTransferLog: math.MaxUint32,
+	RevertReason:   math.MaxUint32,
+	Constantinople: math.MaxUint32,
+	MultiSig:       math.MaxUint32,
+	Delegation:     math.MaxUint32,
+	TypedTx:        math.MaxUint32,
+	SMPrecompile:   math.MaxUint32,
+	Compliance:     math.MaxUint32,
 }
 
 // for well-known networks
@@ -26,14 +48,48 @@ var forkConfigs = map[Bytes32]ForkConfig{
 	// mainnet
 	MustParseBytes32("0x00000000851caf3cfdb6e899cf5958bfb1ac3413d346d43539627e6be7ec1b4a"): {
 		FixTransferLog: 1072000,
+		RevertReason:   math.MaxUint32,
+		Constantinople: math.MaxUint32,
+		MultiSig:       math.MaxUint32,
+		Delegation:     math.MaxUint32,
+		TypedTx:        math.MaxUint32,
+		SMPrecompile:   math.MaxUint32,
+		Compliance:     math.MaxUint32,
 	},
 	// testnet
 	MustParseBytes32("0x000000000b2bce3c70bc649a02749e8687721b09ed2e15997f466536b20bb127"): {
 		FixTransferLog: 1080000,
+		RevertReason:   math.MaxUint32,
+		Constantinople: math.MaxUint32,
+		MultiSig:       math.MaxUint32,
+		Delegation:     math.MaxUint32,
+		TypedTx:        math.MaxUint32,
+		SMPrecompile:   math.MaxUint32,
+		Compliance:     math.MaxUint32,
 	},
 }
 
 // GetForkConfig get fork config for given genesis ID.
+// For other networks, see ConfiguredForkConfig.
 func GetForkConfig(genesisID Bytes32) ForkConfig {
-	return forkConfigs[genesisID]
+	if fc, ok := forkConfigs[genesisID]; ok {
+		return fc
+	}
+	return ConfiguredForkConfig()
+}
+
+// ConfiguredForkConfig get fork config for networks not well-known, which also applies
+// when building genesis, before the genesis ID is known.
+// Forks are active since genesis, except RevertReason and later ones,
+// which are scheduled by configuration to keep existing chains valid.
+func ConfiguredForkConfig() ForkConfig {
+	return ForkConfig{
+		RevertReason:   Conf.RevertReasonFork,
+		Constantinople: Conf.ConstantinopleFork,
+		MultiSig:       Conf.MultiSigFork,
+		Delegation:     Conf.DelegationFork,
+		TypedTx:        Conf.TypedTxFork,
+		SMPrecompile:   Conf.SMPrecompileFork,
+		Compliance:     Conf.ComplianceFork,
+	}
 }
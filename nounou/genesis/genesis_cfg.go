@@ -16,6 +16,15 @@ type Account struct {
 type Config struct {
 	Authorities []*Account //打包block
 	Approvers   []*Account //预分配tokens, approve authority
+	// contract deployment is permissioned if present
+	DeployPermission *DeployPermission `json:",omitempty"`
+}
+
+// DeployPermission initial settings of contract deployment permission.
+// They are stored in Params, and managed by executor proposals afterwards.
+type DeployPermission struct {
+	Deployers  []string // addresses approved to deploy contracts
+	Exemptions []string // txs originated from these addresses are not checked
 }
 
 // default configuration, should read from config file, e.g., /data/genesis_cfg.json
@@ -3,11 +3,23 @@
 package genesis_test
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/HiNounou029/nounouchain/common/xenv"
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/nounou/builtin"
 	"github.com/HiNounou029/nounouchain/nounou/genesis"
+	"github.com/HiNounou029/nounouchain/polo"
 	"github.com/HiNounou029/nounouchain/state"
 	"github.com/HiNounou029/nounouchain/storage"
+	"github.com/HiNounou029/nounouchain/vm"
+	"github.com/HiNounou029/nounouchain/vm/runtime"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,6 +34,43 @@ func TestTestnetGenesis(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestDeployPermission(t *testing.T) {
+	deployer := polo.BytesToAddress([]byte("deployer"))
+	exempted := polo.BytesToAddress([]byte("exempted"))
+	stranger := polo.BytesToAddress([]byte("stranger"))
+
+	cfg := genesis.MustReadConfig("")
+	cfg.DeployPermission = &genesis.DeployPermission{
+		Deployers:  []string{deployer.String()},
+		Exemptions: []string{exempted.String()},
+	}
+	data, _ := json.Marshal(cfg)
+	dir, _ := ioutil.TempDir("", "genesis")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "genesis_cfg.json")
+	assert.Nil(t, ioutil.WriteFile(path, data, 0600))
+
+	kv, _ := storage.NewMem()
+	b0, _, err := genesis.NewProdnet(path).Build(state.NewCreator(kv))
+	assert.Nil(t, err)
+	st, _ := state.New(b0.Header().StateRoot(), kv)
+
+	params := builtin.Params.Native(st)
+	assert.Equal(t, big.NewInt(1), params.Get(polo.KeyDeployPermission))
+	assert.Equal(t, big.NewInt(1), params.Get(polo.DeployerKey(deployer)))
+	assert.Equal(t, big.NewInt(1), params.Get(polo.DeployExemptionKey(exempted)))
+
+	rt := runtime.New(nil, st, &xenv.BlockContext{Number: 1})
+	for origin, expected := range map[polo.Address]error{
+		deployer: nil,
+		exempted: nil,
+		stranger: vm.ErrDeployNotPermitted,
+	} {
+		out := rt.ExecuteClause(tx.NewClause(nil), 0, math.MaxUint64, &xenv.TransactionContext{Origin: origin})
+		assert.Equal(t, expected, out.VMErr, origin.String())
+	}
+}
+
 func TestTime( t *testing.T) {
 	//gt := time.Date(2018, 11, 22, 0, 0, 0, 0, time.Local)
 	//fmt.Printf("%s\n", gt.String())
@@ -70,6 +70,9 @@ func NewDevnet() *Genesis {
 				state.SetCode(polo.Address(addr), emptyRuntimeBytecode)
 			}
 
+			// alloc plugin contracts activated at genesis
+			builtin.ActivatePlugins(state, polo.ConfiguredForkConfig(), 0)
+
 			// setup builtin contracts
 			state.SetCode(builtin.Authority.Address, builtin.Authority.RuntimeBytecodes())
 			state.SetCode(builtin.Params.Address, builtin.Params.RuntimeBytecodes())
@@ -30,6 +30,9 @@ func NewProdnet(cfgFilePath string) *Genesis {
 				state.SetCode(polo.Address(addr), emptyRuntimeBytecode)
 			}
 
+			// alloc plugin contracts activated at genesis
+			builtin.ActivatePlugins(state, polo.ConfiguredForkConfig(), 0)
+
 			// alloc builtin contracts
 			state.SetCode(builtin.Authority.Address, builtin.Authority.RuntimeBytecodes())
 			state.SetCode(builtin.Executor.Address, builtin.Executor.RuntimeBytecodes())
@@ -63,6 +66,20 @@ func NewProdnet(cfgFilePath string) *Genesis {
 	data = mustEncodeInput(builtin.Params.ABI, "set", polo.KeyProposerEndorsement, polo.InitialProposerEndorsement)
 	builder.Call(tx.NewClause(&builtin.Params.Address).WithData(data), builtin.Executor.Address)
 
+	// enable deployment permission
+	if dp := genesisCfg.DeployPermission; dp != nil {
+		data = mustEncodeInput(builtin.Params.ABI, "set", polo.KeyDeployPermission, big.NewInt(1))
+		builder.Call(tx.NewClause(&builtin.Params.Address).WithData(data), builtin.Executor.Address)
+		for _, addr := range dp.Deployers {
+			data = mustEncodeInput(builtin.Params.ABI, "set", polo.DeployerKey(polo.MustParseAddress(addr)), big.NewInt(1))
+			builder.Call(tx.NewClause(&builtin.Params.Address).WithData(data), builtin.Executor.Address)
+		}
+		for _, addr := range dp.Exemptions {
+			data = mustEncodeInput(builtin.Params.ABI, "set", polo.DeployExemptionKey(polo.MustParseAddress(addr)), big.NewInt(1))
+			builder.Call(tx.NewClause(&builtin.Params.Address).WithData(data), builtin.Executor.Address)
+		}
+	}
+
 	// add initial authority nodes
 	for _, anode := range initialAuthorityNodes {
 		data := mustEncodeInput(builtin.Authority.ABI, "add", anode.masterAddress, anode.endorsorAddress, anode.identity)
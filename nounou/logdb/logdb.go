@@ -8,13 +8,20 @@ import (
 	"fmt"
 	"math/big"
 	"sync"
+	"time"
 
+	"github.com/HiNounou029/nounouchain/common/metric"
 	"github.com/HiNounou029/nounouchain/polo"
 	"github.com/HiNounou029/nounouchain/core/block"
 	"github.com/HiNounou029/nounouchain/core/tx"
 	sqlite3 "github.com/mattn/go-sqlite3"
 )
 
+var (
+	commitDuration = metric.NewHistogram("logdb_commit_duration_seconds", "duration of committing logs of blocks", nil)
+	queryDuration  = metric.NewHistogramVec("logdb_query_duration_seconds", "duration of filtering logs by kind (event, transfer, tx)", nil, "kind")
+)
+
 type LogDB struct {
 	path          string
 	db            *sql.DB
@@ -32,7 +39,7 @@ func New(path string) (logDB *LogDB, err error) {
 			db.Close()
 		}
 	}()
-	if _, err := db.Exec(eventTableSchema + transferTableSchema); err != nil {
+	if _, err := db.Exec(eventTableSchema + transferTableSchema + accountTxTableSchema + metaTableSchema); err != nil {
 		return nil, err
 	}
 
@@ -83,9 +90,11 @@ func (db *LogDB) FilterEvents(ctx context.Context, filter *EventFilter) ([]*Even
 			stmt += " AND " + condition + " <= ? "
 		}
 	}
+	// criteria are alternatives, while range applies to all
+	length := len(filter.CriteriaSet)
 	for i, criteria := range filter.CriteriaSet {
 		if i == 0 {
-			stmt += " AND ( 1"
+			stmt += " AND (( 1"
 		} else {
 			stmt += " OR ( 1"
 		}
@@ -99,7 +108,16 @@ func (db *LogDB) FilterEvents(ctx context.Context, filter *EventFilter) ([]*Even
 				stmt += fmt.Sprintf(" AND topic%v = ?", j)
 			}
 		}
-		stmt += ")"
+		if i == length-1 {
+			stmt += " )) "
+		} else {
+			stmt += " ) "
+		}
+	}
+
+	if filter.Cursor != nil {
+		stmt += cursorCondition(filter.Order, "eventIndex")
+		args = append(args, filter.Cursor.BlockNumber, filter.Cursor.BlockNumber, filter.Cursor.Index)
 	}
 
 	if filter.Order == DESC {
@@ -164,6 +182,10 @@ func (db *LogDB) FilterTransfers(ctx context.Context, filter *TransferFilter) ([
 			}
 		}
 	}
+	if filter.Cursor != nil {
+		stmt += cursorCondition(filter.Order, "transferIndex")
+		args = append(args, filter.Cursor.BlockNumber, filter.Cursor.BlockNumber, filter.Cursor.Index)
+	}
 	if filter.Order == DESC {
 		stmt += " ORDER BY blockNumber DESC,transferIndex DESC "
 	} else {
@@ -176,7 +198,66 @@ func (db *LogDB) FilterTransfers(ctx context.Context, filter *TransferFilter) ([
 	return db.queryTransfers(ctx, stmt, args...)
 }
 
+// FilterAccountTxs returns txs related to the account, ordered by block number and tx index.
+func (db *LogDB) FilterAccountTxs(ctx context.Context, filter *AccountTxFilter) ([]*AccountTx, error) {
+	args := []interface{}{filter.Address.Bytes()}
+	stmt := "SELECT blockID, blockNumber, blockTime, txIndex, txID, txOrigin, address, reverted FROM accountTx WHERE address = ?"
+	condition := "blockNumber"
+	if filter.Range != nil {
+		if filter.Range.Unit == Time {
+			condition = "blockTime"
+		}
+		args = append(args, filter.Range.From)
+		stmt += " AND " + condition + " >= ? "
+		if filter.Range.To >= filter.Range.From {
+			args = append(args, filter.Range.To)
+			stmt += " AND " + condition + " <= ? "
+		}
+	}
+	if filter.Order == DESC {
+		stmt += " ORDER BY blockNumber DESC,txIndex DESC "
+	} else {
+		stmt += " ORDER BY blockNumber ASC,txIndex ASC "
+	}
+	if filter.Options != nil {
+		stmt += " limit ?, ? "
+		args = append(args, filter.Options.Offset, filter.Options.Limit)
+	}
+	return db.queryAccountTxs(ctx, stmt, args...)
+}
+
+// AccountTxsIndexedFrom returns the block number since which trunk txs are indexed by account,
+// and false if not recorded yet. Txs of blocks committed before the accountTx index are
+// indexed by backfill, from the recorded block number down to the genesis.
+func (db *LogDB) AccountTxsIndexedFrom() (uint32, bool, error) {
+	var num uint32
+	if err := db.db.QueryRow("SELECT value FROM meta WHERE name = 'accountTxsIndexedFrom'").Scan(&num); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return num, true, nil
+}
+
+// SetAccountTxsIndexedFrom records the block number since which trunk txs are indexed by account.
+func (db *LogDB) SetAccountTxsIndexedFrom(num uint32) error {
+	_, err := db.db.Exec("INSERT OR REPLACE INTO meta(name, value) VALUES ('accountTxsIndexedFrom', ?);", num)
+	return err
+}
+
+// cursorCondition returns the condition of rows after the cursor in order,
+// with args block number, block number and index.
+func cursorCondition(order Order, indexColumn string) string {
+	op := ">"
+	if order == DESC {
+		op = "<"
+	}
+	return " AND (blockNumber " + op + " ? OR (blockNumber = ? AND " + indexColumn + " " + op + " ?)) "
+}
+
 func (db *LogDB) queryEvents(ctx context.Context, stmt string, args ...interface{}) ([]*Event, error) {
+	defer queryDuration.With("event").ObserveSince(time.Now())
 	rows, err := db.db.QueryContext(ctx, stmt, args...)
 	if err != nil {
 		return nil, err
@@ -243,6 +324,7 @@ func (db *LogDB) queryEvents(ctx context.Context, stmt string, args ...interface
 }
 
 func (db *LogDB) queryTransfers(ctx context.Context, stmt string, args ...interface{}) ([]*Transfer, error) {
+	defer queryDuration.With("transfer").ObserveSince(time.Now())
 	rows, err := db.db.QueryContext(ctx, stmt, args...)
 	if err != nil {
 		return nil, err
@@ -307,6 +389,59 @@ func (db *LogDB) queryTransfers(ctx context.Context, stmt string, args ...interf
 	return transfers, nil
 }
 
+func (db *LogDB) queryAccountTxs(ctx context.Context, stmt string, args ...interface{}) ([]*AccountTx, error) {
+	defer queryDuration.With("tx").ObserveSince(time.Now())
+	rows, err := db.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var txs []*AccountTx
+	for rows.Next() {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		var (
+			blockID     []byte
+			blockNumber uint32
+			blockTime   uint64
+			txIndex     uint32
+			txID        []byte
+			txOrigin    []byte
+			address     []byte
+			reverted    uint32
+		)
+		if err := rows.Scan(
+			&blockID,
+			&blockNumber,
+			&blockTime,
+			&txIndex,
+			&txID,
+			&txOrigin,
+			&address,
+			&reverted,
+		); err != nil {
+			return nil, err
+		}
+		txs = append(txs, &AccountTx{
+			BlockID:     polo.BytesToBytes32(blockID),
+			BlockNumber: blockNumber,
+			BlockTime:   blockTime,
+			TxIndex:     txIndex,
+			TxID:        polo.BytesToBytes32(txID),
+			TxOrigin:    polo.BytesToAddress(txOrigin),
+			Address:     polo.BytesToAddress(address),
+			Reverted:    reverted != 0,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
 func topicValue(topic *polo.Bytes32) []byte {
 	if topic == nil {
 		return nil
@@ -319,6 +454,7 @@ type BlockBatch struct {
 	header    *block.Header
 	events    []*Event
 	transfers []*Transfer
+	txs       []*AccountTx
 	dbLock  sync.Mutex
 }
 
@@ -338,6 +474,7 @@ func (bb *BlockBatch) execInTx(proc func(*sql.Tx) error) (err error) {
 }
 
 func (bb *BlockBatch) Commit(abandonedBlocks ...polo.Bytes32) error {
+	defer commitDuration.ObserveSince(time.Now())
 	return bb.execInTx(func(tx *sql.Tx) error {
 		for _, event := range bb.events {
 			if _, err := tx.Exec("INSERT OR REPLACE INTO event(blockID ,eventIndex, blockNumber ,blockTime ,txID ,txOrigin ,address ,topic0 ,topic1 ,topic2 ,topic3 ,topic4, data) VALUES ( ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);",
@@ -382,6 +519,24 @@ func (bb *BlockBatch) Commit(abandonedBlocks ...polo.Bytes32) error {
 				return err
 			}
 		}
+		for _, atx := range bb.txs {
+			reverted := 0
+			if atx.Reverted {
+				reverted = 1
+			}
+			if _, err := tx.Exec("INSERT OR REPLACE INTO accountTx(blockID, txIndex, address, blockNumber, blockTime, txID, txOrigin, reverted) VALUES (?, ?, ?, ?, ?, ?, ?, ?);",
+				atx.BlockID.Bytes(),
+				atx.TxIndex,
+				atx.Address.Bytes(),
+				atx.BlockNumber,
+				atx.BlockTime,
+				atx.TxID.Bytes(),
+				atx.TxOrigin.Bytes(),
+				reverted,
+			); err != nil {
+				return err
+			}
+		}
 		for _, id := range abandonedBlocks {
 			if _, err := tx.Exec("DELETE FROM event WHERE blockID = ?;", id.Bytes()); err != nil {
 				return err
@@ -389,6 +544,9 @@ func (bb *BlockBatch) Commit(abandonedBlocks ...polo.Bytes32) error {
 			if _, err := tx.Exec("DELETE FROM transfer WHERE blockID = ?;", id.Bytes()); err != nil {
 				return err
 			}
+			if _, err := tx.Exec("DELETE FROM accountTx WHERE blockID = ?;", id.Bytes()); err != nil {
+				return err
+			}
 		}
 		return nil
 	})
@@ -411,3 +569,9 @@ func (bb *BlockBatch) ForTransaction(txID polo.Bytes32, txOrigin polo.Address) s
 		},
 	}
 }
+
+// InsertTx indexes the tx at given index of the block, by its origin, clause recipients and contracts created.
+func (bb *BlockBatch) InsertTx(index uint32, trx *tx.Transaction, txOrigin polo.Address, reverted bool) *BlockBatch {
+	bb.txs = append(bb.txs, newAccountTxs(bb.header, index, trx, txOrigin, reverted)...)
+	return bb
+}
@@ -33,7 +33,7 @@ func TestEvents(t *testing.T) {
 
 	for i := 0; i < 100; i++ {
 		if err := db.Prepare(header).ForTransaction(polo.BytesToBytes32([]byte("txID")), polo.BytesToAddress([]byte("txOrigin"))).
-			Insert(tx.Events{txEvent}, nil).Commit(); err != nil {
+			Insert(tx.Events{txEvent}, nil, false).Commit(); err != nil {
 			t.Fatal(err)
 		}
 
@@ -78,6 +78,27 @@ func TestEvents(t *testing.T) {
 		t.Fatal(err)
 	}
 	assert.Equal(t, len(es), limit, "limit should be equal")
+
+	// the range applies to all alternative criteria
+	other := polo.BytesToAddress([]byte("other"))
+	es, err = db.FilterEvents(context.Background(), &logdb.EventFilter{
+		Range: &logdb.Range{
+			Unit: logdb.Block,
+			From: 0,
+			To:   10,
+		},
+		CriteriaSet: []*logdb.EventCriteria{
+			&logdb.EventCriteria{Address: &other},
+			&logdb.EventCriteria{Address: &addr},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 10, len(es), "events out of range should be excluded")
+	for _, e := range es {
+		assert.True(t, e.BlockNumber <= 10)
+	}
 }
 
 func TestTransfers(t *testing.T) {
@@ -99,7 +120,7 @@ func TestTransfers(t *testing.T) {
 			Amount:    value,
 		}
 		header = new(block.Builder).ParentID(header.ID()).Build().Header()
-		if err := db.Prepare(header).ForTransaction(polo.Bytes32{}, from).Insert(nil, tx.Transfers{transLog}).
+		if err := db.Prepare(header).ForTransaction(polo.Bytes32{}, from).Insert(nil, tx.Transfers{transLog}, false).
 			Commit(); err != nil {
 			t.Fatal(err)
 		}
@@ -131,6 +152,16 @@ func TestTransfers(t *testing.T) {
 	assert.Equal(t, len(ts), count, "transfers searched")
 }
 
+func TestCursor(t *testing.T) {
+	c := logdb.Cursor{BlockNumber: 100, Index: 3}
+	text, err := c.MarshalText()
+	assert.Nil(t, err)
+	var c2 logdb.Cursor
+	assert.Nil(t, c2.UnmarshalText(text))
+	assert.Equal(t, c, c2)
+	assert.NotNil(t, c2.UnmarshalText([]byte("bad")))
+}
+
 func home() (string, error) {
 	// try to get HOME env
 	if home := os.Getenv("HOME"); home != "" {
@@ -171,7 +202,7 @@ func BenchmarkLog(b *testing.B) {
 		batch := db.Prepare(header)
 		txBatch := batch.ForTransaction(polo.BytesToBytes32([]byte("txID")), polo.BytesToAddress([]byte("txOrigin")))
 		for j := 0; j < 100; j++ {
-			txBatch.Insert(tx.Events{l}, nil)
+			txBatch.Insert(tx.Events{l}, nil, false)
 			header = new(block.Builder).ParentID(header.ID()).Build().Header()
 		}
 
@@ -180,3 +211,23 @@ func BenchmarkLog(b *testing.B) {
 		}
 	}
 }
+
+func TestAccountTxsIndexedFrom(t *testing.T) {
+	db, err := logdb.NewMem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	_, ok, err := db.AccountTxsIndexedFrom()
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	for _, num := range []uint32{100, 99} {
+		assert.Nil(t, db.SetAccountTxsIndexedFrom(num))
+		from, ok, err := db.AccountTxsIndexedFrom()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, num, from)
+	}
+}
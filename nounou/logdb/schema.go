@@ -51,4 +51,28 @@ CREATE INDEX IF NOT EXISTS blockNumberIndex ON transfer(blockNumber);
 CREATE INDEX IF NOT EXISTS blockTimeIndex ON transfer(blockTime);
 CREATE INDEX IF NOT EXISTS senderIndex ON transfer(sender);
 CREATE INDEX IF NOT EXISTS recipientIndex ON transfer(recipient);`
+
+	// create a table for txs related to accounts, one row for each account of a tx,
+	// which is the tx origin, a clause recipient or a contract created
+	accountTxTableSchema = `CREATE TABLE IF NOT EXISTS accountTx (
+	blockID BLOB(32),
+	txIndex INTEGER,
+	address BLOB(20),
+	blockNumber INTEGER,
+	blockTime INTEGER,
+	txID BLOB(32),
+	txOrigin BLOB(20),
+	reverted INTEGER
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS accountTxPrim ON accountTx(blockID, txIndex, address);
+
+CREATE INDEX IF NOT EXISTS accountTxAddressIndex ON accountTx(address, blockNumber, txIndex);
+CREATE INDEX IF NOT EXISTS accountTxBlockTimeIndex ON accountTx(address, blockTime);`
+
+	// create a table for named numbers about the db, e.g. progress of index backfill
+	metaTableSchema = `CREATE TABLE IF NOT EXISTS meta (
+	name TEXT PRIMARY KEY,
+	value INTEGER
+);`
 )
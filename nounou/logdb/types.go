@@ -3,6 +3,9 @@
 package logdb
 
 import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
 	"math/big"
 
 	"github.com/HiNounou029/nounouchain/polo"
@@ -71,6 +74,52 @@ func newTransfer(header *block.Header, index uint32, txID polo.Bytes32, txOrigin
 	}
 }
 
+//AccountTx represents a tx related to an account, which is sent by the account,
+//or has a clause to the account or creating the account.
+type AccountTx struct {
+	BlockID     polo.Bytes32
+	BlockNumber uint32
+	BlockTime   uint64
+	TxIndex     uint32
+	TxID        polo.Bytes32
+	TxOrigin    polo.Address
+	Address     polo.Address
+	Reverted    bool
+}
+
+//newAccountTxs converts a tx to AccountTx for each of its related accounts.
+func newAccountTxs(header *block.Header, index uint32, trx *tx.Transaction, txOrigin polo.Address, reverted bool) []*AccountTx {
+	addresses := []polo.Address{txOrigin}
+	for i, clause := range trx.Clauses() {
+		if to := clause.To(); to != nil {
+			addresses = append(addresses, *to)
+		} else if !reverted {
+			addresses = append(addresses, polo.CreateContractAddress(trx.ID(), uint32(i), 0))
+		}
+	}
+	var (
+		txs  []*AccountTx
+		seen = make(map[polo.Address]bool)
+	)
+	for _, addr := range addresses {
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		txs = append(txs, &AccountTx{
+			BlockID:     header.ID(),
+			BlockNumber: header.Number(),
+			BlockTime:   header.Timestamp(),
+			TxIndex:     index,
+			TxID:        trx.ID(),
+			TxOrigin:    txOrigin,
+			Address:     addr,
+			Reverted:    reverted,
+		})
+	}
+	return txs
+}
+
 type RangeType string
 
 const (
@@ -96,6 +145,32 @@ type Options struct {
 	Limit  uint64
 }
 
+// Cursor position of a log in order of (block number, index), to continue filtering after it.
+// It's encoded in text as an opaque string.
+type Cursor struct {
+	BlockNumber uint32
+	Index       uint32
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c Cursor) MarshalText() ([]byte, error) {
+	var b [8]byte
+	binary.BigEndian.PutUint32(b[:], c.BlockNumber)
+	binary.BigEndian.PutUint32(b[4:], c.Index)
+	return []byte(base64.RawURLEncoding.EncodeToString(b[:])), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *Cursor) UnmarshalText(text []byte) error {
+	b, err := base64.RawURLEncoding.DecodeString(string(text))
+	if err != nil || len(b) != 8 {
+		return errors.New("invalid cursor")
+	}
+	c.BlockNumber = binary.BigEndian.Uint32(b)
+	c.Index = binary.BigEndian.Uint32(b[4:])
+	return nil
+}
+
 type EventCriteria struct {
 	Address *polo.Address // always a contract address
 	Topics  [5]*polo.Bytes32
@@ -105,6 +180,7 @@ type EventCriteria struct {
 type EventFilter struct {
 	CriteriaSet []*EventCriteria
 	Range       *Range
+	Cursor      *Cursor // filter events after the cursor in order
 	Options     *Options
 	Order       Order //default asc
 }
@@ -119,6 +195,15 @@ type TransferFilter struct {
 	TxID        *polo.Bytes32
 	CriteriaSet []*TransferCriteria
 	Range       *Range
+	Cursor      *Cursor // filter transfers after the cursor in order
 	Options     *Options
 	Order       Order //default asc
 }
+
+//AccountTxFilter filter of txs related to an account
+type AccountTxFilter struct {
+	Address polo.Address
+	Range   *Range
+	Options *Options
+	Order   Order //default asc
+}
@@ -3,6 +3,7 @@
 package polo
 
 import (
+	"math"
 	"math/big"
 	"time"
 
@@ -40,6 +41,8 @@ var (
 	KeyExecutorAddress     = BytesToBytes32([]byte("executor"))
 	KeyBaseGasPrice        = BytesToBytes32([]byte("base-gas-price"))
 	KeyProposerEndorsement = BytesToBytes32([]byte("proposer-endorsement"))
+	// contract deployment is permissioned if it's non-zero
+	KeyDeployPermission = BytesToBytes32([]byte("deploy-permission"))
 
 	InitialBaseGasPrice        = big.NewInt(0)  // gas price设置为最小值
 
@@ -54,7 +57,31 @@ type configuration struct {
 	TxPerSecondLimit uint64
 	TxSizeLimit uint64
 	MaxBlockProposers uint64
+	// block number since which revert data is stored in receipts
+	RevertReasonFork uint32
+	// block number since which constantinople opcodes are enabled
+	ConstantinopleFork uint32
+	// block number since which the compliance contract is activated
+	ComplianceFork uint32
+	// block number since which txs sent by multisig accounts are accepted
+	MultiSigFork uint32
+	// block number since which gas can be paid by delegator or prototype sponsor
+	DelegationFork uint32
+	// block number since which typed tx envelopes are accepted
+	TypedTxFork uint32
+	// block number since which SM3/SM2 precompiled contracts are enabled
+	SMPrecompileFork uint32
 }
 
-var Conf = configuration{5,2000, 65536, 7}
+var Conf = configuration{5,2000, 65536, 7, math.MaxUint32, math.MaxUint32, math.MaxUint32, math.MaxUint32, math.MaxUint32, math.MaxUint32, math.MaxUint32}
 
+// DeployerKey returns key of the governance param, which approves addr to deploy contracts if non-zero.
+func DeployerKey(addr Address) Bytes32 {
+	return Blake2b([]byte("deployer"), addr[:])
+}
+
+// DeployExemptionKey returns key of the governance param, which exempts txs originated from addr
+// from deployment permission checks if non-zero.
+func DeployExemptionKey(addr Address) Bytes32 {
+	return Blake2b([]byte("deploy-exemption"), addr[:])
+}
@@ -206,6 +206,9 @@ func (bc *PoloClient) SendTransaction(plain JsonTx) ([]byte, error) {
 	if bc.Key == nil {
 		return nil, errors.New("key is not initialized")
 	}
+	if err := bc.estimatePlainGas(tx, &addr, plain.Value, nil); err != nil {
+		return nil, err
+	}
 	sig, err := crypto.Sign(tx.SigningHash().Bytes(), bc.Key)
 	if err != nil {
 		return nil, fmt.Errorf("sign tx error %v", err)
@@ -224,10 +227,13 @@ func (bc *PoloClient) SendTransaction(plain JsonTx) ([]byte, error) {
 
 func (bc *PoloClient) DeployContract(data string) ([]byte, error) {
 	tx := NewPlainTransaction(bc, nil, 0, Hex2Bytes(data))
-	hash := tx.SigningHash().Bytes()
 	if bc.Key == nil {
 		return nil, errors.New("key is not initialized")
 	}
+	if err := bc.estimatePlainGas(tx, nil, 0, Hex2Bytes(data)); err != nil {
+		return nil, err
+	}
+	hash := tx.SigningHash().Bytes()
 	sig, err := crypto.Sign(hash, bc.Key)
 	if err != nil {
 		return nil, err
@@ -305,6 +311,9 @@ func (bc *PoloClient) InvokeContract(ethABI *ethabi.ABI,
 	if bc.Key == nil {
 		return nil, errors.New("key is not initialized")
 	}
+	if err := bc.estimatePlainGas(tx, &contractAddr, value.Uint64(), txData); err != nil {
+		return nil, err
+	}
 	sig, err := crypto.Sign(tx.SigningHash().Bytes(), bc.Key)
 	if err != nil {
 		return nil, fmt.Errorf("sign tx error %v", err)
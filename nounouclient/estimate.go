@@ -0,0 +1,78 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package poloclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+type estimateClause struct {
+	To    *polo.Address         `json:"to"`
+	Value *math.HexOrDecimal256 `json:"value"`
+	Data  string                `json:"data"`
+}
+
+type estimateData struct {
+	Clauses []estimateClause `json:"clauses"`
+	Caller  string           `json:"caller,omitempty"`
+}
+
+type estimateResult struct {
+	Gas          uint64 `json:"gas"`
+	IntrinsicGas uint64 `json:"intrinsicGas"`
+	Reverted     bool   `json:"reverted"`
+	VMError      string `json:"vmError"`
+	RevertReason string `json:"revertReason"`
+}
+
+// EstimateGas estimates gas of a tx with such clauses sent by the caller, at best block.
+// Error returned if the tx will be reverted.
+func (bc *PoloClient) EstimateGas(caller string, clauses ...*tx.Clause) (uint64, error) {
+	edata := &estimateData{
+		Clauses: make([]estimateClause, len(clauses)),
+		Caller:  caller,
+	}
+	for i, c := range clauses {
+		edata.Clauses[i] = estimateClause{
+			To:    c.To(),
+			Value: (*math.HexOrDecimal256)(c.Value()),
+			Data:  hexutil.Encode(c.Data()),
+		}
+	}
+	output, err := httpPost(bc.Endpoint+"/accounts/*/estimate", edata)
+	if err != nil {
+		return 0, fmt.Errorf("estimate gas: %v", err)
+	}
+	var result estimateResult
+	if err := json.Unmarshal(output, &result); err != nil {
+		return 0, fmt.Errorf("invalid result %s: %v", output, err)
+	}
+	if result.Reverted {
+		if result.RevertReason != "" {
+			return 0, fmt.Errorf("estimate gas: reverted: %s", result.RevertReason)
+		}
+		return 0, fmt.Errorf("estimate gas: %s", result.VMError)
+	}
+	return result.Gas, nil
+}
+
+// estimatePlainGas sets estimated gas of plain tx sent by the client.
+// It should be called before signing.
+func (bc *PoloClient) estimatePlainGas(t *plainTransaction, addr *Address, value uint64, data []byte) error {
+	clause := tx.NewClause((*polo.Address)(addr)).
+		WithValue(new(big.Int).SetUint64(value)).
+		WithData(data)
+	gas, err := bc.EstimateGas(bc.caller(), clause)
+	if err != nil {
+		return err
+	}
+	t.Gas = gas
+	return nil
+}
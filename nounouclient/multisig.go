@@ -0,0 +1,151 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package poloclient
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"sort"
+
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/crypto"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/pkg/errors"
+)
+
+// MultiSigTx collects partial signatures of a tx sent by a multisig account.
+// The unsigned tx can be passed around members by EncodeUnsigned/DecodeMultiSigTx.
+type MultiSigTx struct {
+	tx   *tx.Transaction
+	ms   *tx.MultiSig
+	sigs map[int][]byte // member index => signature
+}
+
+// NewMultiSigTx builds an unsigned tx sent by the multisig account.
+func (bc *PoloClient) NewMultiSigTx(ms *tx.MultiSig, clauses ...*tx.Clause) (*MultiSigTx, error) {
+	if err := ms.Validate(); err != nil {
+		return nil, err
+	}
+	gas, err := bc.EstimateGas(ms.Address().String(), clauses...)
+	if err != nil {
+		return nil, err
+	}
+	// the estimation knows nothing about the multisig, whose verification is charged as intrinsic gas
+	gas += uint64(len(ms.PubKeys)) * tx.MultiSigVerifyGas
+	builder := new(tx.Builder).
+		ChainTag(bc.ChainStatus.Tag).
+		BlockRef(tx.NewBlockRefFromID(bc.ChainStatus.BestBlockId)).
+		Expiration(720).
+		Gas(gas).
+		Nonce(uint64(mclock.Now())).
+		MultiSig(ms)
+	for _, clause := range clauses {
+		builder.Clause(clause)
+	}
+	return &MultiSigTx{builder.Build(), ms, make(map[int][]byte)}, nil
+}
+
+// DecodeMultiSigTx decodes a tx encoded by EncodeUnsigned.
+func DecodeMultiSigTx(raw string) (*MultiSigTx, error) {
+	data, err := hexutil.Decode(raw)
+	if err != nil {
+		return nil, err
+	}
+	var trx *tx.Transaction
+	if err := rlp.DecodeBytes(data, &trx); err != nil {
+		return nil, err
+	}
+	ms, err := trx.MultiSig()
+	if err != nil {
+		return nil, err
+	}
+	if ms == nil {
+		return nil, errors.New("not a multisig tx")
+	}
+	return &MultiSigTx{trx.WithSignature(nil), ms, make(map[int][]byte)}, nil
+}
+
+// EncodeUnsigned encodes the unsigned tx into hex string, to be shared with other members.
+func (m *MultiSigTx) EncodeUnsigned() (string, error) {
+	data, err := rlp.EncodeToBytes(m.tx)
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(data), nil
+}
+
+// Sign signs the tx with a member key, and collects the signature.
+func (m *MultiSigTx) Sign(key *ecdsa.PrivateKey) ([]byte, error) {
+	sig, err := crypto.Sign(m.tx.SigningHash().Bytes(), key)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.AddSignature(sig); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// AddSignature collects a signature produced by other member.
+// Signature from the same member is replaced.
+func (m *MultiSigTx) AddSignature(sig []byte) error {
+	if len(sig) != tx.SignatureLength {
+		return fmt.Errorf("invalid signature length %v", len(sig))
+	}
+	index, err := m.ms.Member(m.tx.SigningHash().Bytes(), sig)
+	if err != nil {
+		return err
+	}
+	if index < 0 {
+		return errors.New("signature not from member")
+	}
+	m.sigs[index] = append([]byte(nil), sig...)
+	return nil
+}
+
+// Signed returns count of collected signatures.
+func (m *MultiSigTx) Signed() int {
+	return len(m.sigs)
+}
+
+// Complete returns whether enough signatures collected.
+func (m *MultiSigTx) Complete() bool {
+	return len(m.sigs) >= int(m.ms.Threshold)
+}
+
+// Transaction returns the tx with collected signatures.
+func (m *MultiSigTx) Transaction() (*tx.Transaction, error) {
+	if !m.Complete() {
+		return nil, fmt.Errorf("insufficient signatures want %v, have %v", m.ms.Threshold, len(m.sigs))
+	}
+	indices := make([]int, 0, len(m.sigs))
+	for i := range m.sigs {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+
+	var sigs []byte
+	for _, i := range indices[:m.ms.Threshold] {
+		sigs = append(sigs, m.sigs[i]...)
+	}
+	return m.tx.WithSignature(sigs), nil
+}
+
+// SendMultiSigTx sends the multisig tx once signatures are complete.
+func (bc *PoloClient) SendMultiSigTx(m *MultiSigTx) ([]byte, error) {
+	trx, err := m.Transaction()
+	if err != nil {
+		return nil, err
+	}
+	rlpTx, err := rlp.EncodeToBytes(trx)
+	if err != nil {
+		return nil, fmt.Errorf("encode tx %v: %v", trx, err)
+	}
+	res, err := httpPost(bc.Endpoint+"/transactions", RawTx{Raw: hexutil.Encode(rlpTx)})
+	if err != nil {
+		return nil, fmt.Errorf("send tx %v: %v", trx, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,64 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package poloclient_test
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HiNounou029/nounouchain/api/status"
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/crypto"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/poloclient"
+	"github.com/HiNounou029/nounouchain/vm/runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMultiSigTx(t *testing.T) {
+	to := polo.BytesToAddress([]byte("to"))
+	clause := tx.NewClause(&to).WithValue(big.NewInt(1))
+
+	// estimates as the node does for a plain transfer, which is all intrinsic gas
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gas, _ := tx.IntrinsicGas(clause)
+		json.NewEncoder(w).Encode(map[string]interface{}{"gas": gas})
+	}))
+	defer ts.Close()
+
+	var (
+		keys []*ecdsa.PrivateKey
+		pubs []*ecdsa.PublicKey
+	)
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+		pubs = append(pubs, &key.PublicKey)
+	}
+	ms, err := tx.NewMultiSig(2, pubs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bc := &poloclient.PoloClient{Endpoint: ts.URL, ChainStatus: &status.ChainStatus{}}
+	mtx, err := bc.NewMultiSigTx(ms, clause)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys[:2] {
+		if _, err := mtx.Sign(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	trx, err := mtx.Transaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// intrinsic gas is checked when resolved by the pool
+	_, err = runtime.ResolveTransaction(trx)
+	assert.Nil(t, err)
+}
@@ -323,6 +323,19 @@ func (s *State) Delete(addr polo.Address) {
 	s.updateAccount(addr, emptyAccount())
 }
 
+// ClearStorage drops all storage of the given address, while storage set before is kept.
+// The cached account is replaced, which can't be reverted by checkpoints,
+// so it's only for throwaway states, e.g. simulating calls.
+func (s *State) ClearStorage(addr polo.Address) {
+	data := s.getCachedObject(addr).data
+	data.StorageRoot = nil
+	s.cache[addr] = newCachedObject(s.kv, &data)
+
+	cpy := s.getAccountCopy(addr)
+	cpy.StorageRoot = nil
+	s.updateAccount(addr, &cpy)
+}
+
 // NewCheckpoint makes a checkpoint of current state.
 // It returns revision of the checkpoint.
 func (s *State) NewCheckpoint() int {
@@ -374,6 +387,19 @@ func (s *State) Stage() *Stage {
 	return newStage(s.root, s.kv, changes)
 }
 
+// Touched returns addresses of changed accounts, with keys of their changed storage slots.
+func (s *State) Touched() map[polo.Address][]polo.Bytes32 {
+	touched := make(map[polo.Address][]polo.Bytes32)
+	for addr, obj := range s.changes() {
+		keys := make([]polo.Bytes32, 0, len(obj.storage))
+		for key := range obj.storage {
+			keys = append(keys, key)
+		}
+		touched[addr] = keys
+	}
+	return touched
+}
+
 type (
 	storageKey struct {
 		addr polo.Address
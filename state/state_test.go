@@ -126,3 +126,55 @@ func TestStorage(t *testing.T) {
 
 	assert.Equal(t, polo.Blake2b(data), st.GetStorage(addr, key))
 }
+
+func TestClearStorage(t *testing.T) {
+	kv, _ := storage.NewMem()
+	state, _ := New(polo.Bytes32{}, kv)
+
+	addr := polo.BytesToAddress([]byte("account1"))
+	key1 := polo.BytesToBytes32([]byte("key1"))
+	key2 := polo.BytesToBytes32([]byte("key2"))
+
+	state.SetBalance(addr, big.NewInt(1))
+	state.SetStorage(addr, key1, polo.BytesToBytes32([]byte("value1")))
+	root, err := state.Stage().Commit()
+	assert.Nil(t, err)
+
+	state, _ = New(root, kv)
+	assert.Equal(t, polo.BytesToBytes32([]byte("value1")), state.GetStorage(addr, key1))
+
+	state.ClearStorage(addr)
+	assert.Equal(t, polo.Bytes32{}, state.GetStorage(addr, key1))
+	assert.Equal(t, big.NewInt(1), state.GetBalance(addr))
+
+	state.SetStorage(addr, key2, polo.BytesToBytes32([]byte("value2")))
+	assert.Equal(t, polo.BytesToBytes32([]byte("value2")), state.GetStorage(addr, key2))
+
+	root, err = state.Stage().Commit()
+	assert.Nil(t, err)
+	state, _ = New(root, kv)
+	assert.Equal(t, polo.Bytes32{}, state.GetStorage(addr, key1))
+	assert.Equal(t, polo.BytesToBytes32([]byte("value2")), state.GetStorage(addr, key2))
+}
+
+func TestTouched(t *testing.T) {
+	kv, _ := storage.NewMem()
+	state, _ := New(polo.Bytes32{}, kv)
+
+	addr1 := polo.BytesToAddress([]byte("account1"))
+	addr2 := polo.BytesToAddress([]byte("account2"))
+	key := polo.BytesToBytes32([]byte("key"))
+
+	state.SetBalance(addr1, big.NewInt(1))
+	checkpoint := state.NewCheckpoint()
+	state.SetStorage(addr2, key, polo.BytesToBytes32([]byte("value")))
+	assert.Equal(t, map[polo.Address][]polo.Bytes32{
+		addr1: {},
+		addr2: {key},
+	}, state.Touched())
+
+	state.RevertTo(checkpoint)
+	assert.Equal(t, map[polo.Address][]polo.Bytes32{
+		addr1: {},
+	}, state.Touched())
+}
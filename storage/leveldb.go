@@ -98,6 +98,15 @@ func (ldb *LevelDB) Close() error {
 	return ldb.db.Close()
 }
 
+// Stats returns statistics of the level db.
+func (ldb *LevelDB) Stats() (*leveldb.DBStats, error) {
+	var stats leveldb.DBStats
+	if err := ldb.db.Stats(&stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
 // NewBatch create a batch for writing ops.
 func (ldb *LevelDB) NewBatch() kv.Batch {
 	return &levelDBBatch{
@@ -0,0 +1,114 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package vm_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/HiNounou029/nounouchain/crypto"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/HiNounou029/nounouchain/storage"
+	"github.com/HiNounou029/nounouchain/vm"
+	"github.com/HiNounou029/nounouchain/vm/runtime/statedb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	caller   = common.BytesToAddress([]byte("caller"))
+	contract = common.BytesToAddress([]byte("contract"))
+	other    = common.BytesToAddress([]byte("other"))
+	funded   = common.BytesToAddress([]byte("funded"))
+	missing  = common.BytesToAddress([]byte("missing"))
+)
+
+// pushAddr returns code of PUSH20 addr
+func pushAddr(addr common.Address) []byte {
+	return append([]byte{byte(vm.PUSH20)}, addr.Bytes()...)
+}
+
+// returnTop stores top of stack at mem[0:32] and returns it.
+var returnTop = []byte{
+	byte(vm.PUSH1), 0, byte(vm.MSTORE),
+	byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+}
+
+func newEVM(t *testing.T, constantinople int64) (*vm.EVM, *state.State) {
+	kv, _ := storage.NewMem()
+	st, err := state.New(polo.Bytes32{}, kv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.SetCode(polo.Address(other), []byte{byte(vm.STOP)})
+	st.SetBalance(polo.Address(funded), big.NewInt(1))
+
+	config := *params.TestChainConfig
+	config.ConstantinopleBlock = big.NewInt(constantinople)
+	evm := vm.NewEVM(vm.Context{
+		CanTransfer: func(db vm.StateDB, addr common.Address, amount *big.Int) bool {
+			return db.GetBalance(addr).Cmp(amount) >= 0
+		},
+		Transfer: func(vm.StateDB, common.Address, common.Address, *big.Int) {},
+		GetHash:  func(uint64) common.Hash { return common.Hash{} },
+		NewContractAddress: func(_ *vm.EVM, counter uint32) common.Address {
+			return common.BytesToAddress([]byte{byte(counter)})
+		},
+		BlockNumber: big.NewInt(1),
+		Time:        big.NewInt(0),
+		Difficulty:  big.NewInt(0),
+		GasPrice:    big.NewInt(0),
+	}, statedb.New(st), &config, vm.Config{})
+	return evm, st
+}
+
+func call(evm *vm.EVM, st *state.State, code []byte) ([]byte, error) {
+	st.SetCode(polo.Address(contract), code)
+	ret, _, err := evm.Call(vm.AccountRef(caller), contract, nil, 1000000, big.NewInt(0))
+	return ret, err
+}
+
+func TestExtCodeHash(t *testing.T) {
+	tests := []struct {
+		addr     common.Address
+		expected common.Hash
+	}{
+		{other, crypto.Keccak256Hash([]byte{byte(vm.STOP)})},
+		{funded, crypto.Keccak256Hash(nil)},
+		{missing, common.Hash{}},
+	}
+	for _, tt := range tests {
+		evm, st := newEVM(t, 0)
+		code := append(pushAddr(tt.addr), byte(vm.EXTCODEHASH))
+		ret, err := call(evm, st, append(code, returnTop...))
+		assert.Nil(t, err)
+		assert.Equal(t, tt.expected, common.BytesToHash(ret))
+	}
+}
+
+func TestCreate2(t *testing.T) {
+	evm, st := newEVM(t, 0)
+	initCode := []byte{byte(vm.STOP)}
+	code := []byte{
+		// mem[0] = init code
+		byte(vm.PUSH1), initCode[0], byte(vm.PUSH1), 0, byte(vm.MSTORE8),
+		// salt, size, offset, value
+		byte(vm.PUSH1), 0x2a, byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0,
+		byte(vm.CREATE2),
+	}
+	ret, err := call(evm, st, append(code, returnTop...))
+	assert.Nil(t, err)
+	expected := crypto.CreateAddress2(contract, common.BigToHash(big.NewInt(0x2a)), crypto.Keccak256(initCode))
+	assert.Equal(t, expected, common.BytesToAddress(ret))
+}
+
+func TestConstantinopleNotActivated(t *testing.T) {
+	ops := []vm.OpCode{vm.SHL, vm.SHR, vm.SAR, vm.EXTCODEHASH, vm.CREATE2}
+	for _, op := range ops {
+		evm, st := newEVM(t, 2)
+		_, err := call(evm, st, []byte{byte(op)})
+		assert.NotNil(t, err, op.String())
+	}
+}
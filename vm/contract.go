@@ -119,6 +119,13 @@ func (c *Contract) Caller() common.Address {
 	return c.CallerAddress
 }
 
+// Parent returns the contract which called this contract, or nil if it's
+// called by an account directly.
+func (c *Contract) Parent() *Contract {
+	parent, _ := c.caller.(*Contract)
+	return parent
+}
+
 // UseGas attempts the use gas and subtracts it and returns true on success
 func (c *Contract) UseGas(gas uint64) (ok bool) {
 	if c.Gas < gas {
@@ -0,0 +1,103 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package vm
+
+import (
+	"crypto/elliptic"
+	"math/big"
+
+	"github.com/HiNounou029/nounouchain/crypto"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+// Gas costs of SM2/SM3 precompiled contracts.
+const (
+	Sm3BaseGas    uint64 = 60   // Base price for a SM3 operation
+	Sm3PerWordGas uint64 = 12   // Per-word price for a SM3 operation
+	Sm2VerifyGas  uint64 = 3000 // Price for a SM2 signature verification, or public key recovery
+)
+
+// PrecompiledContractsSM contains pre-compiled contracts of SM2/SM3 algorithms.
+// They are available in both builds, at addresses apart from the range used by Ethereum.
+// They are kept out of the Ethereum sets, which are allocated in genesis.
+var PrecompiledContractsSM = map[common.Address]PrecompiledContract{
+	common.BytesToAddress([]byte{1, 0}): &sm3hash{},
+	common.BytesToAddress([]byte{1, 1}): &sm2verify{},
+	common.BytesToAddress([]byte{1, 2}): &sm2recover{},
+}
+
+// SM3 implemented as a native contract.
+type sm3hash struct{}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+//
+// This method does not require any overflow checking as the input size gas costs
+// required for anything significant is so high it's impossible to pay for.
+func (c *sm3hash) RequiredGas(input []byte) uint64 {
+	return uint64(len(input)+31)/32*Sm3PerWordGas + Sm3BaseGas
+}
+func (c *sm3hash) Run(input []byte) ([]byte, error) {
+	return sm3.Sm3Sum(input), nil
+}
+
+// SM2 signature verification implemented as a native contract.
+// The input is (hash, r, s, x, y), each 32 bytes, where (x, y) is the public key.
+// It returns 1 as a 32 bytes word if the signature is valid, otherwise 0.
+type sm2verify struct{}
+
+func (c *sm2verify) RequiredGas(input []byte) uint64 {
+	return Sm2VerifyGas
+}
+
+func (c *sm2verify) Run(input []byte) ([]byte, error) {
+	const sm2VerifyInputLength = 160
+
+	input = common.RightPadBytes(input, sm2VerifyInputLength)
+	x := new(big.Int).SetBytes(input[96:128])
+	y := new(big.Int).SetBytes(input[128:160])
+	if sm2VerifyHash(input[:32], input[32:64], input[64:96], x, y) {
+		return common.LeftPadBytes(big1.Bytes(), 32), nil
+	}
+	return make([]byte, 32), nil
+}
+
+// SM2 public key recovery implemented as a native contract.
+// SM2 signatures are not recoverable by themselves, so signatures produced by the
+// gm build carry the public key, in the [R || S || V || PUB] format.
+// The input is (hash, sig), and it returns the address of the public key if the
+// signature is valid, like ecrecover.
+type sm2recover struct{}
+
+func (c *sm2recover) RequiredGas(input []byte) uint64 {
+	return Sm2VerifyGas
+}
+
+func (c *sm2recover) Run(input []byte) ([]byte, error) {
+	const sm2RecoverInputLength = 32 + 130
+
+	input = common.RightPadBytes(input, sm2RecoverInputLength)
+	// V is always 1, and the public key is uncompressed
+	if input[96] != 1 || input[97] != 4 {
+		return nil, nil
+	}
+	x := new(big.Int).SetBytes(input[98:130])
+	y := new(big.Int).SetBytes(input[130:162])
+	if !sm2VerifyHash(input[:32], input[32:64], input[64:96], x, y) {
+		return nil, nil
+	}
+	pub := elliptic.Marshal(sm2.P256Sm2(), x, y)
+	// same as the address derivation of ecrecover
+	return common.LeftPadBytes(crypto.Keccak256(pub[1:])[12:], 32), nil
+}
+
+// sm2VerifyHash verifies the SM2 signature (r, s) of hash, with the default user id.
+func sm2VerifyHash(hash, r, s []byte, x, y *big.Int) bool {
+	curve := sm2.P256Sm2()
+	if !curve.IsOnCurve(x, y) {
+		return false
+	}
+	pub := &sm2.PublicKey{Curve: curve, X: x, Y: y}
+	return sm2.Sm2Verify(pub, hash, nil, new(big.Int).SetBytes(r), new(big.Int).SetBytes(s))
+}
@@ -0,0 +1,122 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package vm
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+
+	"github.com/HiNounou029/nounouchain/crypto"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+func runSMPrecompiled(t *testing.T, addr []byte, input []byte) []byte {
+	p := PrecompiledContractsSM[common.BytesToAddress(addr)]
+	if p == nil {
+		t.Fatalf("no precompiled contract at %x", addr)
+	}
+	contract := NewContract(AccountRef(common.HexToAddress("1337")),
+		nil, new(big.Int), p.RequiredGas(input))
+	res, err := RunPrecompiledContract(p, input, contract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// sm2TestSig signs hash, and returns the signature in the [R || S || V || PUB] format.
+func sm2TestSig(t *testing.T, hash []byte) (*sm2.PrivateKey, []byte) {
+	priv, err := sm2.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, s, err := sm2.Sm2Sign(priv, hash, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := append(common.LeftPadBytes(r.Bytes(), 32), common.LeftPadBytes(s.Bytes(), 32)...)
+	sig = append(sig, 1)
+	sig = append(sig, elliptic.Marshal(priv.Curve, priv.X, priv.Y)...)
+	return priv, sig
+}
+
+func TestPrecompiledSm3(t *testing.T) {
+	// sample from GB/T 32905-2016
+	res := runSMPrecompiled(t, []byte{1, 0}, []byte("abc"))
+	if expected := "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"; common.Bytes2Hex(res) != expected {
+		t.Errorf("Expected %v, got %v", expected, common.Bytes2Hex(res))
+	}
+	if gas := (&sm3hash{}).RequiredGas(make([]byte, 33)); gas != Sm3BaseGas+2*Sm3PerWordGas {
+		t.Errorf("Expected gas %v, got %v", Sm3BaseGas+2*Sm3PerWordGas, gas)
+	}
+}
+
+func TestPrecompiledSm2Verify(t *testing.T) {
+	hash := crypto.Keccak256([]byte("hello"))
+	priv, sig := sm2TestSig(t, hash)
+
+	input := append(append([]byte(nil), hash...), sig[:64]...)
+	input = append(input, common.LeftPadBytes(priv.X.Bytes(), 32)...)
+	input = append(input, common.LeftPadBytes(priv.Y.Bytes(), 32)...)
+
+	if res := runSMPrecompiled(t, []byte{1, 1}, input); new(big.Int).SetBytes(res).Cmp(big1) != 0 || len(res) != 32 {
+		t.Errorf("Expected valid signature, got %x", res)
+	}
+
+	input[0] ^= 0xff
+	if res := runSMPrecompiled(t, []byte{1, 1}, input); new(big.Int).SetBytes(res).Sign() != 0 || len(res) != 32 {
+		t.Errorf("Expected invalid signature, got %x", res)
+	}
+
+	// public key not on curve
+	if res := runSMPrecompiled(t, []byte{1, 1}, input[:128]); new(big.Int).SetBytes(res).Sign() != 0 {
+		t.Errorf("Expected invalid signature, got %x", res)
+	}
+}
+
+func TestPrecompiledSm2Recover(t *testing.T) {
+	hash := crypto.Keccak256([]byte("hello"))
+	_, sig := sm2TestSig(t, hash)
+	input := append(append([]byte(nil), hash...), sig...)
+
+	expected := common.LeftPadBytes(crypto.Keccak256(sig[66:])[12:], 32)
+	if res := runSMPrecompiled(t, []byte{1, 2}, input); common.Bytes2Hex(res) != common.Bytes2Hex(expected) {
+		t.Errorf("Expected %x, got %x", expected, res)
+	}
+
+	input[40] ^= 0xff
+	if res := runSMPrecompiled(t, []byte{1, 2}, input); len(res) != 0 {
+		t.Errorf("Expected empty output, got %x", res)
+	}
+
+	if res := runSMPrecompiled(t, []byte{1, 2}, input[:32]); len(res) != 0 {
+		t.Errorf("Expected empty output, got %x", res)
+	}
+}
+
+func TestSMPrecompileFork(t *testing.T) {
+	addr := common.BytesToAddress([]byte{1, 0})
+	tests := []struct {
+		fork    *big.Int
+		number  int64
+		enabled bool
+	}{
+		{nil, 10, false},
+		{big.NewInt(10), 9, false},
+		{big.NewInt(10), 10, true},
+		{big.NewInt(10), 11, true},
+	}
+	for _, tt := range tests {
+		evm := NewEVM(Context{BlockNumber: big.NewInt(tt.number), SMPrecompileBlock: tt.fork}, nil, params.TestChainConfig, Config{})
+		if enabled := evm.precompile(addr) != nil; enabled != tt.enabled {
+			t.Errorf("fork %v, block %v: expected enabled %v, got %v", tt.fork, tt.number, tt.enabled, enabled)
+		}
+	}
+	evm := NewEVM(Context{BlockNumber: big.NewInt(0)}, nil, params.TestChainConfig, Config{})
+	if evm.precompile(common.BytesToAddress([]byte{1})) == nil {
+		t.Error("Expected ecrecover regardless of the fork")
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package vm_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/HiNounou029/nounouchain/vm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanCreate(t *testing.T) {
+	// CREATE2 with empty init code, which results in non-zero address
+	code := []byte{byte(vm.PUSH1), 0x2a, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.CREATE2)}
+	code = append(code, returnTop...)
+
+	for _, permitted := range []bool{true, false} {
+		evm, st := newEVM(t, 0)
+		var creators []common.Address
+		evm.CanCreate = func(_ *vm.EVM, creator common.Address) bool {
+			creators = append(creators, creator)
+			return permitted
+		}
+		ret, err := call(evm, st, code)
+		assert.Nil(t, err)
+		assert.Equal(t, []common.Address{contract}, creators)
+		assert.Equal(t, permitted, common.BytesToAddress(ret) != common.Address{})
+	}
+
+	// creation at depth 0 is left to the caller of Create
+	evm, _ := newEVM(t, 0)
+	evm.CanCreate = func(*vm.EVM, common.Address) bool { return false }
+	_, _, _, err := evm.Create(vm.AccountRef(caller), nil, 100000, big.NewInt(0))
+	assert.Nil(t, err)
+}
@@ -25,4 +25,8 @@ var (
 	ErrTraceLimitReached        = errors.New("the number of logs reached the specified limit")
 	ErrInsufficientBalance      = errors.New("insufficient balance for transfer")
 	ErrContractAddressCollision = errors.New("contract address collision")
+	ErrExecutionReverted        = errors.New("evm: execution reverted")
+	ErrDeployNotPermitted       = errors.New("evm: contract deployment not permitted")
+	ErrAccountFrozen            = errors.New("evm: account frozen")
+	ErrWriteProtection          = errors.New("evm: write protection")
 )
@@ -47,24 +47,41 @@ type (
 	// OnCreateContractFunc callback when creating contract.
 	OnCreateContractFunc func(evm *EVM, contractAddr common.Address, caller common.Address)
 
-	// OnSuicideContractFunc callback when suicide contract.
-	OnSuicideContractFunc func(evm *EVM, contractAddr common.Address, tokenReceiver common.Address)
+	// OnSuicideContractFunc callback when suicide contract, which fails the execution if an error returned.
+	OnSuicideContractFunc func(evm *EVM, contractAddr common.Address, tokenReceiver common.Address) error
+
+	// CanCreateFunc returns whether the contract is permitted to create contracts.
+	CanCreateFunc func(evm *EVM, creator common.Address) bool
+
+	// CheckTransferFunc returns an error if the call or creation from one account to another is refused.
+	CheckTransferFunc func(evm *EVM, from common.Address, to common.Address, value *big.Int) error
 )
 
 // run runs the given contract and takes care of running precompiles with a fallback to the byte code interpreter.
 func run(evm *EVM, contract *Contract, input []byte) ([]byte, error) {
 	if contract.CodeAddr != nil {
-		precompiles := PrecompiledContractsHomestead
-		if evm.ChainConfig().IsByzantium(evm.BlockNumber) {
-			precompiles = PrecompiledContractsByzantium
-		}
-		if p := precompiles[*contract.CodeAddr]; p != nil {
+		if p := evm.precompile(*contract.CodeAddr); p != nil {
 			return RunPrecompiledContract(p, input, contract)
 		}
 	}
 	return evm.interpreter.Run(contract, input)
 }
 
+// precompile returns the precompiled contract at addr, or nil if there is none.
+func (evm *EVM) precompile(addr common.Address) PrecompiledContract {
+	precompiles := PrecompiledContractsHomestead
+	if evm.ChainConfig().IsByzantium(evm.BlockNumber) {
+		precompiles = PrecompiledContractsByzantium
+	}
+	if p := precompiles[addr]; p != nil {
+		return p
+	}
+	if evm.SMPrecompileBlock != nil && evm.BlockNumber.Cmp(evm.SMPrecompileBlock) >= 0 {
+		return PrecompiledContractsSM[addr]
+	}
+	return nil
+}
+
 // Context provides the EVM with auxiliary information. Once provided
 // it shouldn't be modified.
 type Context struct {
@@ -80,6 +97,11 @@ type Context struct {
 	InterceptContractCall InterceptContractCallFunc
 	OnCreateContract      OnCreateContractFunc
 	OnSuicideContract     OnSuicideContractFunc
+	// CanCreate is checked for CREATE family opcodes, while creation by clause
+	// is checked by the caller of Create.
+	CanCreate CanCreateFunc
+	// CheckTransfer is checked for calls and creations, with or without value.
+	CheckTransfer CheckTransferFunc
 
 	// Message information
 	Origin   common.Address // Provides information for ORIGIN
@@ -91,6 +113,9 @@ type Context struct {
 	BlockNumber *big.Int       // Provides information for NUMBER
 	Time        *big.Int       // Provides information for TIME
 	Difficulty  *big.Int       // Provides information for DIFFICULTY
+
+	// SMPrecompileBlock is the block number since which SM precompiled contracts are enabled (nil = disabled)
+	SMPrecompileBlock *big.Int
 }
 
 // EVM is the Ethereum Virtual Machine base object and provides
@@ -176,17 +201,18 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	if !evm.Context.CanTransfer(evm.StateDB, caller.Address(), value) {
 		return nil, gas, ErrInsufficientBalance
 	}
+	if evm.CheckTransfer != nil {
+		if err := evm.CheckTransfer(evm, caller.Address(), addr, value); err != nil {
+			return nil, gas, err
+		}
+	}
 
 	var (
 		to       = AccountRef(addr)
 		snapshot = evm.StateDB.Snapshot()
 	)
 	if !evm.StateDB.Exist(addr) {
-		precompiles := PrecompiledContractsHomestead
-		if evm.ChainConfig().IsByzantium(evm.BlockNumber) {
-			precompiles = PrecompiledContractsByzantium
-		}
-		if precompiles[addr] == nil && evm.ChainConfig().IsEIP158(evm.BlockNumber) && value.Sign() == 0 {
+		if evm.precompile(addr) == nil && evm.ChainConfig().IsEIP158(evm.BlockNumber) && value.Sign() == 0 {
 			// Calling a non existing account, don't do antything, but ping the tracer
 			if evm.vmConfig.Debug && evm.depth == 0 {
 				evm.vmConfig.Tracer.CaptureStart(caller.Address(), addr, false, input, gas, value)
@@ -236,7 +262,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	// when we're in homestead this also counts for code storage gas errors.
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if err != ErrExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -263,6 +289,11 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	if !evm.CanTransfer(evm.StateDB, caller.Address(), value) {
 		return nil, gas, ErrInsufficientBalance
 	}
+	if evm.CheckTransfer != nil {
+		if err := evm.CheckTransfer(evm, caller.Address(), caller.Address(), value); err != nil {
+			return nil, gas, err
+		}
+	}
 
 	var (
 		snapshot = evm.StateDB.Snapshot()
@@ -277,7 +308,7 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	ret, err = run(evm, contract, input)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if err != ErrExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -310,7 +341,7 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 	ret, err = run(evm, contract, input)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if err != ErrExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -353,7 +384,7 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	ret, err = run(evm, contract, input)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if err != ErrExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -362,12 +393,35 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 
 // Create creates a new contract using code as deployment code.
 func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
+	return evm.create(caller, code, gas, value, func() common.Address {
+		//contractAddr = crypto.CreateAddress(caller.Address(), nonce)
+
+		// differ with ethereum here!!!
+		// let runtime make new contract address
+		addr := evm.NewContractAddress(evm, evm.contractCreationCount)
+		evm.contractCreationCount++
+		return addr
+	})
+}
+
+// Create2 creates a new contract using code as deployment code.
+// The contract address is derived from caller, salt and hash of code, as EIP-1014.
+func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, endowment *big.Int, salt *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
+	codeHash := crypto.Keccak256Hash(code)
+	return evm.create(caller, code, gas, endowment, func() common.Address {
+		return crypto.CreateAddress2(caller.Address(), common.BigToHash(salt), codeHash[:])
+	})
+}
 
+func (evm *EVM) create(caller ContractRef, code []byte, gas uint64, value *big.Int, newAddress func() common.Address) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
 	// Depth check execution. Fail if we're trying to execute above the
 	// limit.
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, common.Address{}, gas, ErrDepth
 	}
+	if evm.depth > 0 && evm.CanCreate != nil && !evm.CanCreate(evm, caller.Address()) {
+		return nil, common.Address{}, gas, ErrDeployNotPermitted
+	}
 	if !evm.CanTransfer(evm.StateDB, caller.Address(), value) {
 		return nil, common.Address{}, gas, ErrInsufficientBalance
 	}
@@ -375,12 +429,12 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 	nonce := evm.StateDB.GetNonce(caller.Address())
 	evm.StateDB.SetNonce(caller.Address(), nonce+1)
 
-	//contractAddr = crypto.CreateAddress(caller.Address(), nonce)
-
-	// differ with ethereum here!!!
-	// let runtime make new contract address
-	contractAddr = evm.NewContractAddress(evm, evm.contractCreationCount)
-	evm.contractCreationCount++
+	contractAddr = newAddress()
+	if evm.CheckTransfer != nil {
+		if err := evm.CheckTransfer(evm, caller.Address(), contractAddr, value); err != nil {
+			return nil, common.Address{}, gas, err
+		}
+	}
 
 	//
 	contractHash := evm.StateDB.GetCodeHash(contractAddr)
@@ -439,7 +493,7 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 	// when we're in homestead this also counts for code storage gas errors.
 	if maxCodeSizeExceeded || (err != nil && (evm.ChainConfig().IsHomestead(evm.BlockNumber) || err != ErrCodeStoreOutOfGas)) {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if err != ErrExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
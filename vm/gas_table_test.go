@@ -16,7 +16,12 @@
 
 package vm
 
-import "testing"
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+)
 
 func TestMemoryGasCost(t *testing.T) {
 	//size := uint64(math.MaxUint64 - 64)
@@ -34,3 +39,30 @@ func TestMemoryGasCost(t *testing.T) {
 		t.Error("expected error")
 	}
 }
+
+// gas values from EIP-1014 examples, assuming no memory expansion
+func TestGasCreate2(t *testing.T) {
+	tests := []struct {
+		size uint64
+		gas  uint64
+	}{
+		{0, 32000},
+		{1, 32006},
+		{4, 32006},
+		{44, 32012},
+	}
+	for _, tt := range tests {
+		stack := newstack()
+		stack.push(new(big.Int))                    // salt
+		stack.push(new(big.Int).SetUint64(tt.size)) // size
+		stack.push(new(big.Int))                    // offset
+		stack.push(new(big.Int))                    // value
+		gas, err := gasCreate2(params.GasTable{}, nil, nil, stack, NewMemory(), 0)
+		if err != nil {
+			t.Error("didn't expect error:", err)
+		}
+		if gas != tt.gas {
+			t.Errorf("size %d: expected %d, got %d", tt.size, tt.gas, gas)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// selector of `Error(string)`, which solidity uses to encode revert reasons.
+var revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
+// DecodeRevertReason decodes the reason string from revert data, if it's
+// abi encoded as `Error(string)`.
+func DecodeRevertReason(data []byte) (string, bool) {
+	if len(data) < 4+64 || !bytes.Equal(data[:4], revertSelector) {
+		return "", false
+	}
+	data = data[4:]
+
+	offset := new(big.Int).SetBytes(data[:32])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(data)-32) {
+		return "", false
+	}
+	start := offset.Uint64() + 32
+	size := new(big.Int).SetBytes(data[start-32 : start])
+	if !size.IsUint64() || size.Uint64() > uint64(len(data))-start {
+		return "", false
+	}
+	return string(data[start : start+size.Uint64()]), true
+}
+
+// EncodeRevertReason encodes the reason string as `Error(string)`, in the way solidity does.
+func EncodeRevertReason(reason string) []byte {
+	size := (len(reason) + 31) / 32 * 32
+	data := make([]byte, 0, 4+64+size)
+	data = append(data, revertSelector...)
+	data = append(data, common.LeftPadBytes(big.NewInt(32).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(int64(len(reason))).Bytes(), 32)...)
+	return append(data, common.RightPadBytes([]byte(reason), size)...)
+}
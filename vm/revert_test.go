@@ -0,0 +1,43 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package vm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestDecodeRevertReason(t *testing.T) {
+	// revert("not enough balance")
+	data := hexutil.MustDecode("0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000012" +
+		"6e6f7420656e6f7567682062616c616e63650000000000000000000000000000")
+
+	reason, ok := DecodeRevertReason(data)
+	if !ok || reason != "not enough balance" {
+		t.Errorf("expected decoded reason, got %q %v", reason, ok)
+	}
+	if enc := EncodeRevertReason(reason); !bytes.Equal(enc, data) {
+		t.Errorf("expected encoded %x, got %x", data, enc)
+	}
+
+	tests := [][]byte{
+		nil,
+		data[:4],
+		// unknown selector
+		append([]byte{0, 0, 0, 0}, data[4:]...),
+		// truncated string
+		data[:len(data)-32],
+		// offset out of range
+		append(append([]byte(nil), data[:4]...), common.RightPadBytes(common.LeftPadBytes([]byte{0xff}, 32), 64)...),
+	}
+	for _, d := range tests {
+		if _, ok := DecodeRevertReason(d); ok {
+			t.Errorf("expected failure for %x", d)
+		}
+	}
+}
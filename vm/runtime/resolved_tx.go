@@ -19,16 +19,24 @@ import (
 type ResolvedTransaction struct {
 	tx           *tx.Transaction
 	Origin       polo.Address
+	Delegator    *polo.Address
 	IntrinsicGas uint64
 	Clauses      []*tx.Clause
 }
 
 // ResolveTransaction resolves the transaction and performs basic validation.
 func ResolveTransaction(tx *tx.Transaction) (*ResolvedTransaction, error) {
+	if _, err := tx.MultiSig(); err != nil {
+		return nil, err
+	}
 	origin, err := tx.Signer()
 	if err != nil {
 		return nil, err
 	}
+	delegator, err := tx.Delegator()
+	if err != nil {
+		return nil, errors.WithMessage(err, "delegator")
+	}
 	intrinsicGas, err := tx.IntrinsicGas()
 	if err != nil {
 		return nil, err
@@ -54,6 +62,7 @@ func ResolveTransaction(tx *tx.Transaction) (*ResolvedTransaction, error) {
 	return &ResolvedTransaction{
 		tx,
 		origin,
+		delegator,
 		intrinsicGas,
 		clauses,
 	}, nil
@@ -84,20 +93,67 @@ func (r *ResolvedTransaction) CommonTo() *polo.Address {
 }
 
 // BuyGas consumes balance to buy gas, to prepare for execution.
-func (r *ResolvedTransaction) BuyGas(state *state.State) (
+// Since the delegation fork, the gas is paid by, in order of precedence:
+// the delegator if the tx is delegated;
+// the current sponsor of the common 'To' within the origin's credit;
+// the common 'To' itself within the origin's credit;
+// otherwise the origin. Before the fork, it's always paid by the origin.
+// Frozen accounts are never charged, and a frozen origin or delegator fails the tx.
+func (r *ResolvedTransaction) BuyGas(state *state.State, forkConfig polo.ForkConfig, blockNum uint32, blockTime uint64) (
 	gasPrice *big.Int,
+	payer polo.Address,
 	returnGas func(uint64), err error) {
+	compliance := builtin.Compliance.Native(state)
+	if compliance.IsFrozen(r.Origin) {
+		return nil, polo.Address{}, nil, fmt.Errorf("account frozen, origin: %s", r.Origin.String())
+	}
+
 	gasPrice = builtin.Params.Native(state).Get(polo.KeyBaseGasPrice)
-	doReturnGas := func(rgas uint64) *big.Int {
+	prepaid := new(big.Int).Mul(new(big.Int).SetUint64(r.tx.Gas()), gasPrice)
+
+	doReturnGas := func(payer polo.Address, rgas uint64) *big.Int {
 		returnedGas := new(big.Int).Mul(new(big.Int).SetUint64(rgas), gasPrice)
-		state.AddBalance(r.Origin, returnedGas)
+		state.AddBalance(payer, returnedGas)
 		return returnedGas
 	}
-	prepaid := new(big.Int).Mul(new(big.Int).SetUint64(r.tx.Gas()), gasPrice)
+
+	if blockNum >= forkConfig.Delegation {
+		if r.Delegator != nil {
+			if compliance.IsFrozen(*r.Delegator) {
+				return nil, polo.Address{}, nil, fmt.Errorf("account frozen, delegator: %s", r.Delegator.String())
+			}
+			if state.SubBalance(*r.Delegator, prepaid) {
+				return gasPrice, *r.Delegator, func(rgas uint64) { doReturnGas(*r.Delegator, rgas) }, nil
+			}
+			return nil, polo.Address{}, nil, fmt.Errorf("insufficient gas, require at least %d, delegator: %s", prepaid, r.Delegator.String())
+		}
+
+		if commonTo := r.CommonTo(); commonTo != nil {
+			binding := builtin.Prototype.Native(state).Bind(*commonTo)
+			credit := binding.UserCredit(r.Origin, blockTime)
+			if binding.IsUser(r.Origin) && credit.Cmp(prepaid) >= 0 {
+				returnGasAndSetCredit := func(payer polo.Address) func(uint64) {
+					return func(rgas uint64) {
+						used := new(big.Int).Sub(prepaid, doReturnGas(payer, rgas))
+						binding.SetUserCredit(r.Origin, new(big.Int).Sub(credit, used), blockTime)
+					}
+				}
+				if sponsor := binding.CurrentSponsor(); binding.IsSponsor(sponsor) && !compliance.IsFrozen(sponsor) {
+					if state.SubBalance(sponsor, prepaid) {
+						return gasPrice, sponsor, returnGasAndSetCredit(sponsor), nil
+					}
+				}
+				if !compliance.IsFrozen(*commonTo) && state.SubBalance(*commonTo, prepaid) {
+					return gasPrice, *commonTo, returnGasAndSetCredit(*commonTo), nil
+				}
+			}
+		}
+	}
+
 	if state.SubBalance(r.Origin, prepaid) {
-		return gasPrice, func(rgas uint64) { doReturnGas(rgas) }, nil
+		return gasPrice, r.Origin, func(rgas uint64) { doReturnGas(r.Origin, rgas) }, nil
 	}
-	return nil, nil, fmt.Errorf("insufficient gas, require at least %d, addr: %s", prepaid, r.Origin.String())
+	return nil, polo.Address{}, nil, fmt.Errorf("insufficient gas, require at least %d, addr: %s", prepaid, r.Origin.String())
 }
 
 // ToContext create a tx context object.
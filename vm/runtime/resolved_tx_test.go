@@ -17,7 +17,6 @@ import (
 	"github.com/HiNounou029/nounouchain/vm/runtime"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/HiNounou029/nounouchain/crypto"
-	"github.com/HiNounou029/polo-sdk-go/crypto/secp256k1"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -80,8 +79,9 @@ func (tr *testResolvedTransaction) TestResolveTransaction() {
 		return txBuilder(tr.chain.Tag())
 	}
 
+	// unsigned
 	_, err := runtime.ResolveTransaction(txBuild().Build())
-	tr.assert.Equal(secp256k1.ErrInvalidSignatureLen, err)
+	tr.assert.NotNil(err)
 
 	_, err = runtime.ResolveTransaction(txSign(txBuild().Gas(21000 - 1)))
 	tr.assert.NotNil(err)
@@ -140,15 +140,23 @@ func (tr *testResolvedTransaction) TestBuyGas() {
 		return txBuilder(tr.chain.Tag())
 	}
 
-	buyGas := func(tx *tx.Transaction) polo.Address {
+	buyGasAt := func(tx *tx.Transaction, forkConfig polo.ForkConfig) polo.Address {
 		resolve, err := runtime.ResolveTransaction(tx)
 		if err != nil {
 			tr.t.Fatal(err)
 		}
-		_, returnGas, err := resolve.BuyGas(state)
+		_, payer, returnGas, err := resolve.BuyGas(state, forkConfig, 1, 0)
 		tr.assert.Nil(err)
 		returnGas(100)
-		return resolve.Origin
+		return payer
+	}
+	buyGas := func(tx *tx.Transaction) polo.Address {
+		return buyGasAt(tx, polo.ForkConfig{})
+	}
+
+	delegate := func(tx *tx.Transaction) *tx.Transaction {
+		sig, _ := crypto.Sign(tx.DelegatorSigningHash(genesis.DevAccounts()[0].Address).Bytes(), genesis.DevAccounts()[1].PrivateKey)
+		return tx.WithSignature(append(tx.Signature(), sig...))
 	}
 
 	tr.assert.Equal(
@@ -165,6 +173,10 @@ func (tr *testResolvedTransaction) TestBuyGas() {
 		genesis.DevAccounts()[0].Address,
 		buyGas(txSign(txBuild().Clause(clause().WithValue(big.NewInt(100))))),
 	)
+
+	delegated := delegate(txSign(txBuild().Features(tx.DelegationFeature).Clause(clause().WithValue(big.NewInt(100)))))
+	tr.assert.Equal(genesis.DevAccounts()[1].Address, buyGas(delegated))
+	tr.assert.Equal(genesis.DevAccounts()[0].Address, buyGasAt(delegated, polo.ForkConfig{Delegation: 2}), "paid by origin before fork")
 }
 
 func clause() *tx.Clause {
@@ -67,6 +67,14 @@ type Output struct {
 	ContractAddress *polo.Address // if create a new contract, or is nil.
 }
 
+// RevertData returns data returned by REVERT, or nil if the clause not reverted by REVERT.
+func (o *Output) RevertData() []byte {
+	if o.VMErr == vm.ErrExecutionReverted {
+		return o.Data
+	}
+	return nil
+}
+
 type TransactionExecutor struct {
 	HasNextClause func() bool
 	NextClause    func() (gasUsed uint64, output *Output, err error)
@@ -75,11 +83,12 @@ type TransactionExecutor struct {
 
 // Runtime bases on EVM and the builtin contract.
 type Runtime struct {
-	vmConfig   vm.Config
-	seeker     *chain.Seeker
-	state      *state.State
-	ctx        *xenv.BlockContext
-	forkConfig polo.ForkConfig
+	vmConfig    vm.Config
+	seeker      *chain.Seeker
+	state       *state.State
+	ctx         *xenv.BlockContext
+	forkConfig  polo.ForkConfig
+	chainConfig params.ChainConfig
 }
 
 // New create a Runtime object.
@@ -99,12 +108,15 @@ func New(
 		// for genesis building stage
 		rt.forkConfig = polo.NoFork
 	}
+	rt.chainConfig = chainConfig
+	rt.chainConfig.ConstantinopleBlock = new(big.Int).SetUint64(uint64(rt.forkConfig.Constantinople))
 	return &rt
 }
 
 func (rt *Runtime) Seeker() *chain.Seeker       { return rt.seeker }
 func (rt *Runtime) State() *state.State         { return rt.state }
 func (rt *Runtime) Context() *xenv.BlockContext { return rt.ctx }
+func (rt *Runtime) ForkConfig() polo.ForkConfig { return rt.forkConfig }
 
 // SetVMConfig config VM.
 // Returns this runtime.
@@ -158,14 +170,15 @@ func (rt *Runtime) newEVM(stateDB *statedb.StateDB, clauseIndex uint32, txCtx *x
 				return nil, nil, false
 			}
 
-			abi, run, found := builtin.FindNativeCall(polo.Address(contract.Address()), contract.Input)
+			abi, run, found := builtin.FindNativeCall(polo.Address(contract.Address()), contract.Input, rt.forkConfig, rt.ctx.Number)
 			if !found {
 				lastNonNativeCallGas = contract.Gas
 				return nil, nil, false
 			}
 
 			if readonly && !abi.Const() {
-				panic("invoke non-const method in readonly env")
+				// reachable by STATICCALL to a plugin forwarder
+				return nil, vm.ErrWriteProtection, true
 			}
 
 			if contract.Value().Sign() != 0 {
@@ -183,6 +196,19 @@ func (rt *Runtime) newEVM(stateDB *statedb.StateDB, clauseIndex uint32, txCtx *x
 			ret, err := xenv.New(abi, rt.seeker, rt.state, rt.ctx, txCtx, evm, contract).Call(run)
 			return ret, err, true
 		},
+		CanCreate: func(_ *vm.EVM, creator common.Address) bool {
+			return rt.deployPermitted(txCtx.Origin, polo.Address(creator))
+		},
+		CheckTransfer: func(_ *vm.EVM, from, to common.Address, value *big.Int) error {
+			compliance := builtin.Compliance.Native(rt.state)
+			if compliance.IsFrozen(polo.Address(from)) {
+				return vm.ErrAccountFrozen
+			}
+			if value.Sign() != 0 && compliance.IsFrozen(polo.Address(to)) {
+				return vm.ErrAccountFrozen
+			}
+			return nil
+		},
 		OnCreateContract: func(_ *vm.EVM, contractAddr, caller common.Address) {
 			// set master for created contract
 			rt.state.SetMaster(polo.Address(contractAddr), polo.Address(caller))
@@ -198,7 +224,11 @@ func (rt *Runtime) newEVM(stateDB *statedb.StateDB, clauseIndex uint32, txCtx *x
 				Data:    data,
 			})
 		},
-		OnSuicideContract: func(_ *vm.EVM, contractAddr, tokenReceiver common.Address) {
+		OnSuicideContract: func(_ *vm.EVM, contractAddr, tokenReceiver common.Address) error {
+			compliance := builtin.Compliance.Native(rt.state)
+			if compliance.IsFrozen(polo.Address(contractAddr)) || compliance.IsFrozen(polo.Address(tokenReceiver)) {
+				return vm.ErrAccountFrozen
+			}
 			if amount := stateDB.GetBalance(contractAddr); amount.Sign() != 0 {
 				stateDB.AddBalance(tokenReceiver, amount)
 				stateDB.SubBalance(contractAddr, amount)
@@ -209,6 +239,7 @@ func (rt *Runtime) newEVM(stateDB *statedb.StateDB, clauseIndex uint32, txCtx *x
 					Amount:    amount,
 				})
 			}
+			return nil
 		},
 		Origin:      common.Address(txCtx.Origin),
 		GasPrice:    txCtx.GasPrice,
@@ -217,7 +248,9 @@ func (rt *Runtime) newEVM(stateDB *statedb.StateDB, clauseIndex uint32, txCtx *x
 		BlockNumber: new(big.Int).SetUint64(uint64(rt.ctx.Number)),
 		Time:        new(big.Int).SetUint64(rt.ctx.Time),
 		Difficulty:  &big.Int{},
-	}, stateDB, &chainConfig, rt.vmConfig)
+
+		SMPrecompileBlock: new(big.Int).SetUint64(uint64(rt.forkConfig.SMPrecompile)),
+	}, stateDB, &rt.chainConfig, rt.vmConfig)
 }
 
 // ExecuteClause executes single clause.
@@ -232,6 +265,17 @@ func (rt *Runtime) ExecuteClause(
 	return output
 }
 
+// deployPermitted returns whether the creator is permitted to deploy contracts,
+// in tx originated from origin.
+func (rt *Runtime) deployPermitted(origin, creator polo.Address) bool {
+	params := builtin.Params.Native(rt.state)
+	if params.Get(polo.KeyDeployPermission).Sign() == 0 {
+		return true
+	}
+	return params.Get(polo.DeployExemptionKey(origin)).Sign() != 0 ||
+		params.Get(polo.DeployerKey(creator)).Sign() != 0
+}
+
 // PrepareClause prepare to execute clause.
 // It allows to interrupt execution.
 func (rt *Runtime) PrepareClause(
@@ -252,9 +296,13 @@ func (rt *Runtime) PrepareClause(
 
 	exec = func() (*Output, bool) {
 		if clause.To() == nil {
-			var caddr common.Address
-			data, caddr, leftOverGas, vmErr = evm.Create(vm.AccountRef(txCtx.Origin), clause.Data(), gas, clause.Value())
-			contractAddr = (*polo.Address)(&caddr)
+			if rt.deployPermitted(txCtx.Origin, txCtx.Origin) {
+				var caddr common.Address
+				data, caddr, leftOverGas, vmErr = evm.Create(vm.AccountRef(txCtx.Origin), clause.Data(), gas, clause.Value())
+				contractAddr = (*polo.Address)(&caddr)
+			} else {
+				leftOverGas, vmErr = gas, vm.ErrDeployNotPermitted
+			}
 		} else {
 			data, leftOverGas, vmErr = evm.Call(vm.AccountRef(txCtx.Origin), common.Address(*clause.To()), clause.Data(), gas, clause.Value())
 		}
@@ -300,7 +348,7 @@ func (rt *Runtime) PrepareTransaction(tx *tx.Transaction) (*TransactionExecutor,
 		return nil, err
 	}
 
-	gasPrice, returnGas, err := resolvedTx.BuyGas(rt.state)
+	gasPrice, payer, returnGas, err := resolvedTx.BuyGas(rt.state, rt.forkConfig, rt.ctx.Number, rt.ctx.Time)
 	if err != nil {
 		return nil, err
 	}
@@ -315,6 +363,7 @@ func (rt *Runtime) PrepareTransaction(tx *tx.Transaction) (*TransactionExecutor,
 	txOutputs := make([]*Tx.Output, 0, len(resolvedTx.Clauses))
 	reverted := false
 	finalized := false
+	var revertData []byte
 
 	hasNext := func() bool {
 		return !reverted && len(txOutputs) < len(resolvedTx.Clauses)
@@ -349,6 +398,7 @@ func (rt *Runtime) PrepareTransaction(tx *tx.Transaction) (*TransactionExecutor,
 				rt.state.RevertTo(checkpoint)
 				reverted = true
 				txOutputs = nil
+				revertData = output.RevertData()
 				log.Error("VMErr", "err", output.VMErr, "txId", tx.ID())
 				return
 			}
@@ -368,7 +418,10 @@ func (rt *Runtime) PrepareTransaction(tx *tx.Transaction) (*TransactionExecutor,
 				Reverted: reverted,
 				Outputs:  txOutputs,
 				GasUsed:  tx.Gas() - leftOverGas,
-				GasPayer: resolvedTx.Origin,
+				GasPayer: payer,
+			}
+			if len(revertData) > 0 && rt.ctx.Number >= rt.forkConfig.RevertReason {
+				receipt.RevertData = [][]byte{revertData}
 			}
 
 			receipt.Paid = new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), gasPrice)
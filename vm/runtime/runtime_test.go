@@ -15,6 +15,7 @@ import (
 	"github.com/HiNounou029/nounouchain/common/xenv"
 	"github.com/HiNounou029/nounouchain/core/chain"
 	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/crypto"
 	"github.com/HiNounou029/nounouchain/state"
 	"github.com/HiNounou029/nounouchain/storage"
 	"github.com/HiNounou029/nounouchain/vm/runtime"
@@ -135,3 +136,38 @@ func TestCall(t *testing.T) {
 	assert.NotNil(t, out)
 	assert.True(t, interrupted)
 }
+
+func TestRevertReasonFork(t *testing.T) {
+	kv, _ := storage.NewMem()
+
+	g := genesis.NewDevnet()
+	b0, _, err := g.Build(state.NewCreator(kv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, _ := chain.New(kv, b0)
+
+	fork := polo.Conf.RevertReasonFork
+	polo.Conf.RevertReasonFork = 10
+	defer func() { polo.Conf.RevertReasonFork = fork }()
+
+	// init code: mstore(0, 0x2a) revert(0, 32)
+	data, _ := hex.DecodeString("602a60005260206000fd")
+	trx := new(tx.Builder).ChainTag(ch.Tag()).Gas(100000).Clause(tx.NewClause(nil).WithData(data)).Build()
+	sig, _ := crypto.Sign(trx.SigningHash().Bytes(), genesis.DevAccounts()[0].PrivateKey)
+	trx = trx.WithSignature(sig)
+
+	execute := func(number uint32) *tx.Receipt {
+		state, _ := state.New(b0.Header().StateRoot(), kv)
+		receipt, err := runtime.New(ch.NewSeeker(b0.Header().ID()), state, &xenv.BlockContext{Number: number}).
+			ExecuteTransaction(trx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, receipt.Reverted)
+		return receipt
+	}
+
+	assert.Nil(t, execute(9).RevertData, "not stored before fork")
+	assert.Equal(t, [][]byte{common.LeftPadBytes([]byte{0x2a}, 32)}, execute(10).RevertData)
+}
@@ -0,0 +1,216 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package tracers
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/vm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+var (
+	errInternalFailure   = errors.New("internal failure")
+	errExecutionReverted = errors.New("execution reverted")
+)
+
+// CallFrame is a node of the call tree.
+type CallFrame struct {
+	Type    string                `json:"type"`
+	From    polo.Address          `json:"from"`
+	To      *polo.Address         `json:"to,omitempty"`
+	Value   *math.HexOrDecimal256 `json:"value,omitempty"`
+	Gas     uint64                `json:"gas"`
+	GasUsed uint64                `json:"gasUsed"`
+	Input   hexutil.Bytes         `json:"input"`
+	Output  hexutil.Bytes         `json:"output,omitempty"`
+	Error   string                `json:"error,omitempty"`
+	Calls   []*CallFrame          `json:"calls,omitempty"`
+
+	// states while the frame is running
+	gasIn   uint64
+	gasCost uint64
+	outOff  int64
+	outLen  int64
+}
+
+// CallTracer builds call trees, by watching CALL/CREATE family opcodes and depth changes.
+// Each clause produces a root frame.
+type CallTracer struct {
+	roots     []*CallFrame
+	callstack []*CallFrame
+	descended bool
+}
+
+var _ vm.Tracer = (*CallTracer)(nil)
+
+// NewCallTracer creates a call tracer.
+func NewCallTracer() *CallTracer {
+	return &CallTracer{}
+}
+
+// Result returns root frames, one for each executed clause.
+func (t *CallTracer) Result() []*CallFrame {
+	return t.roots
+}
+
+func (t *CallTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	root := &CallFrame{
+		Type:  "CALL",
+		From:  polo.Address(from),
+		Value: (*math.HexOrDecimal256)(new(big.Int).Set(value)),
+		Gas:   gas,
+		Input: append([]byte(nil), input...),
+	}
+	addr := polo.Address(to)
+	root.To = &addr
+	if create {
+		root.Type = "CREATE"
+	}
+	t.roots = append(t.roots, root)
+	t.callstack = []*CallFrame{root}
+	t.descended = false
+	return nil
+}
+
+func (t *CallTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if err != nil {
+		return t.CaptureFault(env, pc, op, gas, cost, memory, stack, contract, depth, err)
+	}
+	if len(t.callstack) == 0 {
+		return nil
+	}
+	// depth of the tracer is 0-based, while the vm is 1-based
+	depth--
+
+	if t.descended {
+		if depth >= len(t.callstack)-1 {
+			// entered the callee, record gas it received
+			t.callstack[len(t.callstack)-1].Gas = gas
+		}
+		t.descended = false
+	}
+
+	if depth == len(t.callstack)-2 {
+		// returned from the callee, the result is on top of stack
+		t.pop(gas, stack.Back(0), memory, env)
+	}
+
+	switch op {
+	case vm.REVERT:
+		t.callstack[len(t.callstack)-1].Error = errExecutionReverted.Error()
+	case vm.CREATE, vm.CREATE2:
+		offset, size := stack.Back(1).Int64(), stack.Back(2).Int64()
+		t.push(&CallFrame{
+			Type:    op.String(),
+			From:    polo.Address(contract.Address()),
+			Value:   (*math.HexOrDecimal256)(new(big.Int).Set(stack.Back(0))),
+			Input:   memory.Get(offset, size),
+			gasIn:   gas,
+			gasCost: cost,
+		})
+	case vm.SELFDESTRUCT:
+		to := polo.BytesToAddress(stack.Back(0).Bytes())
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, &CallFrame{
+			Type:  op.String(),
+			From:  polo.Address(contract.Address()),
+			To:    &to,
+			Value: (*math.HexOrDecimal256)(new(big.Int).Set(env.StateDB.GetBalance(contract.Address()))),
+		})
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		off := 1
+		if op == vm.DELEGATECALL || op == vm.STATICCALL {
+			off = 0
+		}
+		to := polo.BytesToAddress(stack.Back(1).Bytes())
+		frame := &CallFrame{
+			Type:    op.String(),
+			From:    polo.Address(contract.Address()),
+			To:      &to,
+			Input:   memory.Get(stack.Back(2+off).Int64(), stack.Back(3+off).Int64()),
+			gasIn:   gas,
+			gasCost: cost,
+			outOff:  stack.Back(4 + off).Int64(),
+			outLen:  stack.Back(5 + off).Int64(),
+		}
+		if off == 1 {
+			frame.Value = (*math.HexOrDecimal256)(new(big.Int).Set(stack.Back(2)))
+		}
+		t.push(frame)
+	}
+
+	return nil
+}
+
+func (t *CallTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if len(t.callstack) == 0 {
+		return nil
+	}
+	frame := t.callstack[len(t.callstack)-1]
+	if frame.Error != "" {
+		return nil
+	}
+	frame.Error = err.Error()
+	if len(t.callstack) > 1 {
+		t.callstack = t.callstack[:len(t.callstack)-1]
+		if frame.Gas > 0 {
+			frame.GasUsed = frame.Gas
+		}
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, frame)
+	}
+	return nil
+}
+
+func (t *CallTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) error {
+	if len(t.callstack) == 0 {
+		return nil
+	}
+	root := t.callstack[0]
+	root.GasUsed = gasUsed
+	root.Output = append([]byte(nil), output...)
+	if err != nil && root.Error == "" {
+		root.Error = err.Error()
+	}
+	t.callstack = nil
+	return nil
+}
+
+func (t *CallTracer) push(frame *CallFrame) {
+	t.callstack = append(t.callstack, frame)
+	t.descended = true
+}
+
+// pop finishes the running frame, and attaches it to the parent.
+func (t *CallTracer) pop(gasLeft uint64, ret *big.Int, memory *vm.Memory, env *vm.EVM) {
+	frame := t.callstack[len(t.callstack)-1]
+	t.callstack = t.callstack[:len(t.callstack)-1]
+
+	if frame.Type == vm.CREATE.String() || frame.Type == vm.CREATE2.String() {
+		frame.GasUsed = frame.gasIn - frame.gasCost - gasLeft
+		if ret.Sign() != 0 {
+			addr := polo.BytesToAddress(ret.Bytes())
+			frame.To = &addr
+			frame.Output = env.StateDB.GetCode(common.Address(addr))
+		} else if frame.Error == "" {
+			frame.Error = errInternalFailure.Error()
+		}
+	} else {
+		if frame.Gas > 0 {
+			frame.GasUsed = frame.gasIn - frame.gasCost + frame.Gas - gasLeft
+		}
+		if ret.Sign() != 0 {
+			frame.Output = memory.Get(frame.outOff, frame.outLen)
+		} else if frame.Error == "" {
+			frame.Error = errInternalFailure.Error()
+		}
+	}
+	parent := t.callstack[len(t.callstack)-1]
+	parent.Calls = append(parent.Calls, frame)
+}
@@ -0,0 +1,99 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package tracers
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/HiNounou029/nounouchain/vm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// PrestateAccount is the state of an account before execution.
+type PrestateAccount struct {
+	Balance *math.HexOrDecimal256 `json:"balance"`
+	Code    hexutil.Bytes         `json:"code,omitempty"`
+	Storage map[string]string     `json:"storage,omitempty"`
+}
+
+// PrestateTracer collects accounts and storage slots touched during execution.
+// Values are read afterwards from the state reverted to the point before
+// execution, since the state is already altered while tracing.
+type PrestateTracer struct {
+	touched map[polo.Address]map[polo.Bytes32]struct{}
+}
+
+var _ vm.Tracer = (*PrestateTracer)(nil)
+
+// NewPrestateTracer creates a prestate tracer.
+func NewPrestateTracer() *PrestateTracer {
+	return &PrestateTracer{make(map[polo.Address]map[polo.Bytes32]struct{})}
+}
+
+// Touch marks the account as touched. It's for accounts affected out of the vm, e.g. gas payer.
+func (t *PrestateTracer) Touch(addr polo.Address) {
+	if _, ok := t.touched[addr]; !ok {
+		t.touched[addr] = make(map[polo.Bytes32]struct{})
+	}
+}
+
+func (t *PrestateTracer) touchSlot(addr polo.Address, key polo.Bytes32) {
+	t.Touch(addr)
+	t.touched[addr][key] = struct{}{}
+}
+
+// Result reads prestate of touched accounts from the given state, keyed by hex address.
+func (t *PrestateTracer) Result(state *state.State) (map[string]*PrestateAccount, error) {
+	result := make(map[string]*PrestateAccount, len(t.touched))
+	for addr, slots := range t.touched {
+		acc := &PrestateAccount{
+			Balance: (*math.HexOrDecimal256)(state.GetBalance(addr)),
+			Code:    state.GetCode(addr),
+		}
+		if len(slots) > 0 {
+			acc.Storage = make(map[string]string, len(slots))
+			for key := range slots {
+				acc.Storage[key.String()] = state.GetStorage(addr, key).String()
+			}
+		}
+		result[addr.String()] = acc
+	}
+	if err := state.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (t *PrestateTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.Touch(polo.Address(from))
+	t.Touch(polo.Address(to))
+	return nil
+}
+
+func (t *PrestateTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if err != nil {
+		return nil
+	}
+	switch op {
+	case vm.SLOAD, vm.SSTORE:
+		t.touchSlot(polo.Address(contract.Address()), polo.BytesToBytes32(stack.Back(0).Bytes()))
+	case vm.EXTCODECOPY, vm.EXTCODESIZE, vm.EXTCODEHASH, vm.BALANCE, vm.SELFDESTRUCT:
+		t.Touch(polo.BytesToAddress(stack.Back(0).Bytes()))
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		t.Touch(polo.BytesToAddress(stack.Back(1).Bytes()))
+	}
+	return nil
+}
+
+func (t *PrestateTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *PrestateTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) error {
+	return nil
+}
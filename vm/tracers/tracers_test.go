@@ -0,0 +1,87 @@
+// Copyright © 2018-2019 Apollo Technologies Pte. Ltd. All Rights Reserved.
+
+package tracers_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/HiNounou029/nounouchain/common/xenv"
+	"github.com/HiNounou029/nounouchain/core/tx"
+	"github.com/HiNounou029/nounouchain/polo"
+	"github.com/HiNounou029/nounouchain/state"
+	"github.com/HiNounou029/nounouchain/storage"
+	"github.com/HiNounou029/nounouchain/vm"
+	"github.com/HiNounou029/nounouchain/vm/runtime"
+	"github.com/HiNounou029/nounouchain/vm/tracers"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	outer = polo.BytesToAddress([]byte("outer"))
+	inner = polo.BytesToAddress([]byte("inner"))
+)
+
+// outer: SLOAD(1); CALL(inner) with 32 bytes output; RETURN mem[0:32]
+// inner: MSTORE(0, 0x2a); RETURN mem[0:32]
+func newTestState(t *testing.T) *state.State {
+	kv, _ := storage.NewMem()
+	st, err := state.New(polo.Bytes32{}, kv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := []byte{0x60, 0x01, 0x54, 0x50, 0x60, 0x20, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x73}
+	code = append(code, inner.Bytes()...)
+	code = append(code, 0x61, 0xff, 0xff, 0xf1, 0x50, 0x60, 0x20, 0x60, 0x00, 0xf3)
+	st.SetCode(outer, code)
+	st.SetCode(inner, []byte{0x60, 0x2a, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3})
+	st.SetStorage(outer, polo.BytesToBytes32([]byte{1}), polo.BytesToBytes32([]byte{7}))
+	st.SetBalance(outer, big.NewInt(100))
+	return st
+}
+
+func execute(st *state.State, tracer vm.Tracer) *runtime.Output {
+	return runtime.New(nil, st, &xenv.BlockContext{}).
+		SetVMConfig(vm.Config{Debug: true, Tracer: tracer}).
+		ExecuteClause(tx.NewClause(&outer), 0, 1000000, &xenv.TransactionContext{})
+}
+
+func TestCallTracer(t *testing.T) {
+	tracer := tracers.NewCallTracer()
+	out := execute(newTestState(t), tracer)
+	assert.Nil(t, out.VMErr)
+
+	roots := tracer.Result()
+	assert.Equal(t, 1, len(roots))
+	root := roots[0]
+	assert.Equal(t, "CALL", root.Type)
+	assert.Equal(t, outer, *root.To)
+	assert.Equal(t, 1000000-out.LeftOverGas, root.GasUsed)
+	assert.Equal(t, 1, len(root.Calls))
+
+	call := root.Calls[0]
+	assert.Equal(t, "CALL", call.Type)
+	assert.Equal(t, outer, call.From)
+	assert.Equal(t, inner, *call.To)
+	assert.Equal(t, uint64(0xffff), call.Gas)
+	assert.True(t, call.GasUsed > 0)
+	assert.Equal(t, big.NewInt(0x2a).Bytes(), new(big.Int).SetBytes(call.Output).Bytes())
+	assert.Empty(t, call.Error)
+}
+
+func TestPrestateTracer(t *testing.T) {
+	st := newTestState(t)
+	tracer := tracers.NewPrestateTracer()
+	checkpoint := st.NewCheckpoint()
+	execute(st, tracer)
+	st.RevertTo(checkpoint)
+
+	result, err := tracer.Result(st)
+	assert.Nil(t, err)
+	assert.Contains(t, result, outer.String())
+	assert.Contains(t, result, inner.String())
+
+	acc := result[outer.String()]
+	assert.Equal(t, big.NewInt(100), (*big.Int)(acc.Balance))
+	assert.Equal(t, polo.BytesToBytes32([]byte{7}).String(), acc.Storage[polo.BytesToBytes32([]byte{1}).String()])
+}